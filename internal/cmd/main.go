@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -14,7 +16,9 @@ import (
 	"github.com/labstack/gommon/log"
 
 	"github.com/garaevmir/avitocoinstore/internal/handler"
+	"github.com/garaevmir/avitocoinstore/internal/keystore"
 	"github.com/garaevmir/avitocoinstore/internal/middleware"
+	"github.com/garaevmir/avitocoinstore/internal/model"
 	"github.com/garaevmir/avitocoinstore/internal/repository"
 	"github.com/garaevmir/avitocoinstore/internal/service"
 )
@@ -32,23 +36,169 @@ func main() {
 	userRepo := repository.NewUserRepository(pool)
 	transactionRepo := repository.NewTransactionRepository(pool)
 	inventoryRepo := repository.NewInventoryRepository(pool)
-	shopService := service.NewShopService(userRepo, transactionRepo, inventoryRepo)
+	catalogRepo := repository.NewCatalogRepository(pool)
+	ledgerRepo := repository.NewLedgerRepository(pool)
+	orderRepo := repository.NewOrderRepository(pool)
+	cartRepo := repository.NewCartRepository(pool)
+	idempotencyRepo := repository.NewIdempotencyRepository(pool)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(pool)
+	revocationRepo := repository.NewRevocationRepository(pool)
+	inviteRepo := repository.NewInviteRepository(pool)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(pool)
+	passwordResetRepo := repository.NewPasswordResetRepository(pool)
+	shopService := service.NewShopService(userRepo, transactionRepo, inventoryRepo, catalogRepo, ledgerRepo, orderRepo)
+	cartService := service.NewCartService(cartRepo, catalogRepo, shopService)
+	transferService := service.NewTransferService(userRepo, transactionRepo, transferPolicies(transactionRepo)...)
+	keys := keystore.New(os.Getenv("JWT_KEYS_DIR"))
+	if err := keys.Load(); err != nil {
+		e.Logger.Fatal("Failed to load JWT keys:", err)
+	}
+
+	accessTTL := durationFromEnv("ACCESS_TOKEN_TTL", 15*time.Minute)
+	tokenService := service.NewTokenService(
+		userRepo,
+		refreshTokenRepo,
+		keys,
+		accessTTL,
+		durationFromEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+	)
+	revocationService := service.NewRevocationService(revocationRepo, refreshTokenRepo, accessTTL)
+	passwordPolicy := service.PasswordPolicy{MinLength: intFromEnv("PASSWORD_MIN_LENGTH", 8)}
+	passwordService := service.NewPasswordService(
+		userRepo,
+		passwordResetRepo,
+		revocationService,
+		tokenService,
+		passwordPolicy,
+		durationFromEnv("PASSWORD_RESET_TTL", 24*time.Hour),
+	)
+	registrationService := service.NewRegistrationService(
+		userRepo,
+		inviteRepo,
+		passwordPolicy,
+		listFromEnv("REGISTRATION_ALLOWLIST"),
+		durationFromEnv("INVITE_TTL", 7*24*time.Hour),
+	)
+
+	// User named by ADMIN_USERNAME is created or promoted to admin on start, so admin API is reachable on fresh database
+	if username := os.Getenv("ADMIN_USERNAME"); username != "" {
+		err := registrationService.BootstrapAdmin(context.Background(), username, os.Getenv("ADMIN_PASSWORD"))
+		if err != nil {
+			e.Logger.Fatal("Failed to bootstrap admin:", err)
+		}
+	}
+
+	loginGuard := service.NewLoginGuard(loginAttemptRepo, service.LockoutPolicy{
+		UserAttempts: intFromEnv("LOGIN_MAX_ATTEMPTS", 5),
+		IPAttempts:   intFromEnv("LOGIN_MAX_ATTEMPTS_PER_IP", 50),
+		BaseDelay:    durationFromEnv("LOGIN_LOCKOUT_BASE", 30*time.Second),
+		MaxDelay:     durationFromEnv("LOGIN_LOCKOUT_MAX", 15*time.Minute),
+		Window:       durationFromEnv("LOGIN_ATTEMPTS_WINDOW", time.Hour),
+	})
+
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	go keys.Run(bgCtx, durationFromEnv("JWT_KEYS_RELOAD_INTERVAL", time.Minute))
+
+	if err := revocationService.Load(bgCtx); err != nil {
+		e.Logger.Fatal("Failed to load token revocations:", err)
+	}
+	go revocationService.Run(bgCtx, durationFromEnv("REVOCATION_REFRESH_INTERVAL", 30*time.Second))
+
+	// Client address is used to count failed logins, so it is taken from forwarding headers only behind trusted proxy
+	if os.Getenv("TRUST_PROXY_HEADERS") == "true" {
+		e.IPExtractor = echo.ExtractIPFromXFFHeader()
+	} else {
+		e.IPExtractor = echo.ExtractIPDirect()
+	}
 
 	e.Use(echoMiddleware.Logger())
 	e.Use(echoMiddleware.Recover())
 
-	authHandler := handler.NewAuthHandler(userRepo, os.Getenv("JWT_SECRET"))
-	coinHandler := handler.NewCoinHandler(transactionRepo, userRepo)
-	infoHandler := handler.NewInfoHandler(userRepo, inventoryRepo, transactionRepo)
+	authHandler := handler.NewAuthHandler(
+		userRepo,
+		tokenService,
+		registrationService,
+		loginGuard,
+		os.Getenv("AUTH_AUTO_REGISTER") == "true",
+	)
+	coinHandler := handler.NewCoinHandler(transferService)
+	infoHandler := handler.NewInfoHandler(userRepo, inventoryRepo, transactionRepo, orderRepo,
+		intFromEnv("INFO_HISTORY_LIMIT", 20), intFromEnv("INFO_ORDERS_LIMIT", 20))
+	historyHandler := handler.NewHistoryHandler(transactionRepo)
+	activityHandler := handler.NewActivityHandler(ledgerRepo)
+	statementHandler := handler.NewStatementHandler(ledgerRepo, userRepo)
+	orderHandler := handler.NewOrderHandler(orderRepo)
+	cartHandler := handler.NewCartHandler(cartService)
 	shopHandler := handler.NewShopHandler(shopService)
+	catalogHandler := handler.NewCatalogHandler(catalogRepo, userRepo)
+	catalogAdminHandler := handler.NewCatalogAdminHandler(catalogRepo)
+	tokenAdminHandler := handler.NewTokenAdminHandler(revocationService, userRepo)
+	jwksHandler := handler.NewJWKSHandler(keys)
+	inviteAdminHandler := handler.NewInviteAdminHandler(registrationService)
+	userAdminHandler := handler.NewUserAdminHandler(loginGuard, passwordService, revocationService, userRepo)
+	passwordHandler := handler.NewPasswordHandler(passwordService)
+	ledgerAdminHandler := handler.NewLedgerAdminHandler(ledgerRepo, userRepo, service.NewReconcileService(ledgerRepo))
+
+	auth := middleware.JWTAuth(keys, keystore.Algorithms, revocationService)
+	// Responses carrying tokens or one-time codes are not stored, so those endpoints are left out
+	idempotent := middleware.Idempotency(idempotencyRepo, durationFromEnv("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+		durationFromEnv("IDEMPOTENCY_PENDING_TTL", time.Minute))
 
 	e.POST("/api/auth", authHandler.Login)
+	e.POST("/api/register", authHandler.Register)
+	e.POST("/api/password/reset", passwordHandler.ResetPassword)
+	e.POST("/api/auth/refresh", authHandler.Refresh)
+	e.GET("/.well-known/jwks.json", jwksHandler.GetKeys)
 
 	api := e.Group("/api")
-	api.Use(middleware.JWTAuth(os.Getenv("JWT_SECRET")))
+	api.Use(auth)
 	api.GET("/info", infoHandler.GetUserInfo)
-	api.POST("/sendCoin", coinHandler.SendCoins)
-	api.GET("/buy/:item", shopHandler.BuyItem)
+	api.GET("/history", historyHandler.GetHistory)
+	api.GET("/history/summary", historyHandler.GetSummary)
+	api.GET("/activity", activityHandler.GetActivity)
+	api.GET("/statement", statementHandler.GetStatement)
+	api.POST("/logout", authHandler.Logout)
+	api.POST("/password", passwordHandler.ChangePassword)
+	api.POST("/sendCoin", coinHandler.SendCoins, idempotent)
+	api.GET("/buy/:item", shopHandler.BuyItem, idempotent)
+	api.GET("/orders", orderHandler.ListOrders)
+	api.POST("/orders", shopHandler.PlaceOrder, idempotent)
+	api.GET("/cart", cartHandler.GetCart)
+	api.PUT("/cart/:item", cartHandler.SetItem, idempotent)
+	api.DELETE("/cart/:item", cartHandler.RemoveItem, idempotent)
+	api.POST("/cart/checkout", cartHandler.Checkout, idempotent)
+	api.GET("/items", catalogHandler.ListItems)
+	api.GET("/items/:name", catalogHandler.GetItem)
+
+	admin := e.Group("/api/admin")
+	admin.Use(auth)
+
+	catalog := admin.Group("/items", middleware.RequireRole(model.RoleAdmin, model.RoleMerchManager), idempotent)
+	catalog.POST("", catalogAdminHandler.CreateItem)
+	catalog.PUT("/:name/price", catalogAdminHandler.UpdateItemPrice)
+	catalog.DELETE("/:name", catalogAdminHandler.DeactivateItem)
+
+	admin.POST("/tokens/revoke", tokenAdminHandler.RevokeToken,
+		middleware.RequireRole(model.RoleAdmin), idempotent)
+	admin.POST("/users/:username/tokens/revoke", tokenAdminHandler.RevokeUserTokens,
+		middleware.RequireRole(model.RoleAdmin), idempotent)
+	admin.POST("/invites", inviteAdminHandler.CreateInvite, middleware.RequireRole(model.RoleAdmin))
+	admin.POST("/users/:username/unlock", userAdminHandler.Unlock,
+		middleware.RequireRole(model.RoleAdmin), idempotent)
+	admin.PUT("/users/:username/status", userAdminHandler.SetStatus,
+		middleware.RequireRole(model.RoleAdmin), idempotent)
+	admin.PUT("/users/:username/role", userAdminHandler.SetRole,
+		middleware.RequireRole(model.RoleAdmin), idempotent)
+	admin.POST("/users/:username/adjustments", ledgerAdminHandler.AdjustBalance,
+		middleware.RequireRole(model.RoleAdmin, model.RoleFinance), idempotent)
+	admin.POST("/users/:username/password-reset", userAdminHandler.CreatePasswordReset,
+		middleware.RequireRole(model.RoleAdmin))
+	admin.GET("/reconciliation", ledgerAdminHandler.Reconciliation,
+		middleware.RequireRole(model.RoleAdmin, model.RoleFinance))
+	admin.POST("/reconciliation/fix", ledgerAdminHandler.FixReconciliation,
+		middleware.RequireRole(model.RoleAdmin, model.RoleFinance), idempotent)
 
 	s := &http.Server{
 		Addr: ":8080",
@@ -78,3 +228,65 @@ func main() {
 	log.Printf("Server exiting\n")
 
 }
+
+// Reads duration from environment variable name, falls back to def if it is unset or malformed
+func durationFromEnv(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s=%q, using %v", name, value, def)
+		return def
+	}
+	return d
+}
+
+// Builds transfer policies, self-transfers and transfers to inactive accounts are always rejected,
+// other rules are enabled by their environment variables
+func transferPolicies(tRepo repository.TransactionRepositoryInt) []service.TransferPolicy {
+	policies := []service.TransferPolicy{service.SelfTransferPolicy{}, service.ActiveRecipientPolicy{}}
+
+	if limit := intFromEnv("TRANSFER_MAX_AMOUNT", 0); limit > 0 {
+		policies = append(policies, service.MaxAmountPolicy{Max: limit})
+	}
+	if allowList := listFromEnv("TRANSFER_RECIPIENT_ALLOWLIST"); len(allowList) > 0 {
+		policies = append(policies, service.NewRecipientAllowListPolicy(allowList))
+	}
+	if limit := intFromEnv("TRANSFER_DAILY_LIMIT", 0); limit > 0 {
+		policies = append(policies, service.NewDailyLimitPolicy(tRepo, limit))
+	}
+	if count := intFromEnv("TRANSFER_VELOCITY_MAX", 0); count > 0 {
+		window := durationFromEnv("TRANSFER_VELOCITY_WINDOW", time.Minute)
+		policies = append(policies, service.NewVelocityPolicy(tRepo, count, window))
+	}
+	return policies
+}
+
+// Reads integer from environment variable name, falls back to def if it is unset or malformed
+func intFromEnv(name string, def int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Printf("Invalid %s=%q, using %v", name, value, def)
+		return def
+	}
+	return n
+}
+
+// Reads comma separated list from environment variable name, empty elements are skipped
+func listFromEnv(name string) []string {
+	var list []string
+	for _, value := range strings.Split(os.Getenv(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+	"github.com/garaevmir/avitocoinstore/internal/service"
+)
+
+// Command that audits ledger against transfers, orders and grants and cached balances against ledger, exits
+// with code 1 while ledger mismatches remain
+func main() {
+	format := flag.String("format", "json", "report format, json or csv")
+	all := flag.Bool("all", false, "report every user, not only mismatches")
+	fix := flag.Bool("fix", false, "write correcting adjustments for mismatches")
+	reason := flag.String("reason", "", "audit reason recorded with correcting adjustments, required with -fix")
+	flag.Parse()
+
+	if *format != "json" && *format != "csv" {
+		fail("unknown format %q", *format)
+	}
+	if *fix && *reason == "" {
+		fail("-reason is required with -fix")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, os.Getenv("DATABASE_URL"))
+	if err != nil {
+		fail("failed to connect to database: %v", err)
+	}
+	defer pool.Close()
+
+	reconciler := service.NewReconcileService(repository.NewLedgerRepository(pool))
+
+	if *fix {
+		fixed, err := reconciler.Fix(ctx, *reason)
+		if err != nil {
+			fail("failed to fix balances: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "corrected %d balances\n", len(fixed))
+	}
+
+	reports, err := reconciler.Report(ctx, !*all)
+	if err != nil {
+		fail("failed to build report: %v", err)
+	}
+
+	if *format == "csv" {
+		err = service.WriteReportsCSV(os.Stdout, reports)
+	} else {
+		err = service.WriteReportsJSON(os.Stdout, reports)
+	}
+	if err != nil {
+		fail("failed to write report: %v", err)
+	}
+
+	// Drift of cached balance can not be fixed by posting, so it is only warned about
+	mismatched, drifted := false, 0
+	for _, r := range reports {
+		mismatched = mismatched || r.Mismatched()
+		if r.Drifted() {
+			drifted++
+		}
+	}
+	if drifted > 0 {
+		fmt.Fprintf(os.Stderr, "cached balance of %d users drifted from ledger\n", drifted)
+	}
+	if mismatched {
+		pool.Close()
+		os.Exit(1)
+	}
+}
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "reconcile: "+format+"\n", args...)
+	os.Exit(2)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+)
+
+// A structure for an activity feed handler
+type ActivityHandler struct {
+	ledgerRepo repository.LedgerRepositoryInt
+}
+
+// Constructor for activity feed handler
+func NewActivityHandler(lRepo repository.LedgerRepositoryInt) *ActivityHandler {
+	return &ActivityHandler{ledgerRepo: lRepo}
+}
+
+// Function for /api/activity request, returns page of events that changed balance of user newest first
+// with balance after each of them, query parameters from, to, limit and cursor narrow it down
+func (h *ActivityHandler) GetActivity(c echo.Context) error {
+	filter := model.ActivityFilter{Limit: model.DefaultHistoryLimit}
+
+	if l := c.QueryParam("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+		}
+		filter.Limit = min(n, model.MaxHistoryLimit)
+	}
+
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		id, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || id <= 0 {
+			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidCursor.Error()})
+		}
+		filter.Cursor = id
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(c, "from"); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: err.Error()})
+	}
+	if filter.To, err = parseTimeParam(c, "to"); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: err.Error()})
+	}
+
+	page, err := h.ledgerRepo.GetActivity(c.Request().Context(), c.Get("user_id").(string), filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrActivity.Error()})
+	}
+	return c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestActivityHandler_GetActivity(t *testing.T) {
+	e := echo.New()
+	ledgerRepo := new(mocks.LedgerRepositoryMock)
+	activityHandler := NewActivityHandler(ledgerRepo)
+
+	newContext := func(query string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/api/activity?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", "user1")
+		return c, rec
+	}
+
+	t.Run("First page", func(t *testing.T) {
+		ledgerRepo.On("GetActivity", mock.Anything, "user1", model.ActivityFilter{Limit: model.DefaultHistoryLimit}).
+			Return(&model.ActivityPage{
+				Events: []model.ActivityEvent{
+					{ID: 2, Kind: model.PostingPurchase, Amount: -80, Balance: 920, Item: "t-shirt"},
+					{ID: 1, Kind: model.PostingGrant, Amount: 1000, Balance: 1000},
+				},
+			}, nil).Once()
+
+		c, rec := newContext("")
+		err := activityHandler.GetActivity(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var page model.ActivityPage
+		json.Unmarshal(rec.Body.Bytes(), &page)
+		assert.Len(t, page.Events, 2)
+		assert.Equal(t, 920, page.Events[0].Balance)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("Next page", func(t *testing.T) {
+		ledgerRepo.On("GetActivity", mock.Anything, "user1", model.ActivityFilter{Cursor: 42, Limit: 10}).
+			Return(&model.ActivityPage{Events: []model.ActivityEvent{}}, nil).Once()
+
+		c, rec := newContext("cursor=42&limit=10")
+		err := activityHandler.GetActivity(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		ledgerRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		c, rec := newContext("cursor=abc")
+		err := activityHandler.GetActivity(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrInvalidCursor.Error(), errorResp.Errors)
+	})
+
+	t.Run("Invalid date", func(t *testing.T) {
+		c, rec := newContext("to=tomorrow")
+		err := activityHandler.GetActivity(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		ledgerRepo.On("GetActivity", mock.Anything, "user1", model.ActivityFilter{Limit: 5}).
+			Return((*model.ActivityPage)(nil), model.ErrInternalError).Once()
+
+		c, rec := newContext("limit=5")
+		err := activityHandler.GetActivity(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrActivity.Error(), errorResp.Errors)
+	})
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+)
+
+// A structure for a catalog management handler
+type CatalogAdminHandler struct {
+	catalogRepo repository.CatalogRepositoryInt
+}
+
+// Constructor for catalog management handler
+func NewCatalogAdminHandler(cRepo repository.CatalogRepositoryInt) *CatalogAdminHandler {
+	return &CatalogAdminHandler{catalogRepo: cRepo}
+}
+
+// Function for POST /api/admin/items request
+func (h *CatalogAdminHandler) CreateItem(c echo.Context) error {
+	var req model.CreateItemRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	if req.Name == "" {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	if req.Price <= 0 {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrNegAmount.Error()})
+	}
+
+	item := &model.Item{Name: req.Name, Price: req.Price, Description: req.Description}
+	if err := h.catalogRepo.CreateItem(c.Request().Context(), item); err != nil {
+		switch err {
+		case model.ErrItemExists:
+			return c.JSON(http.StatusConflict, model.ErrorResponse{Errors: model.ErrItemExists.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+		}
+	}
+
+	return c.JSON(http.StatusCreated, item)
+}
+
+// Function for PUT /api/admin/items/:name/price request
+func (h *CatalogAdminHandler) UpdateItemPrice(c echo.Context) error {
+	var req model.UpdateItemPriceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	if req.Price <= 0 {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrNegAmount.Error()})
+	}
+
+	item, err := h.catalogRepo.UpdateItemPrice(c.Request().Context(), c.Param("name"), req.Price)
+	if err != nil {
+		return itemUpdateError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, item)
+}
+
+// Function for DELETE /api/admin/items/:name request, item is retired rather than removed
+// so it stays visible in users inventory
+func (h *CatalogAdminHandler) DeactivateItem(c echo.Context) error {
+	item, err := h.catalogRepo.DeactivateItem(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		return itemUpdateError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, item)
+}
+
+// Converts catalog update error to response
+func itemUpdateError(c echo.Context, err error) error {
+	switch err {
+	case model.ErrItemNotFound:
+		return c.JSON(http.StatusNotFound, model.ErrorResponse{Errors: model.ErrItemNotFound.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+}
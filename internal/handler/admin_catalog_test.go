@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestCatalogAdminHandler_CreateItem(t *testing.T) {
+	e := echo.New()
+	catRepo := new(mocks.CatalogRepositoryMock)
+	adminHandler := NewCatalogAdminHandler(catRepo)
+
+	newContext := func(body []byte) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/items", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("Successful item creation", func(t *testing.T) {
+		body, _ := json.Marshal(model.CreateItemRequest{Name: "scarf", Price: 120, Description: "winter"})
+		c, rec := newContext(body)
+
+		catRepo.On("CreateItem", mock.Anything, mock.MatchedBy(func(i *model.Item) bool {
+			return i.Name == "scarf" && i.Price == 120 && i.Description == "winter"
+		})).Run(func(args mock.Arguments) {
+			args[1].(*model.Item).Active = true
+		}).Return(nil).Once()
+
+		err := adminHandler.CreateItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response model.Item
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.Equal(t, "scarf", response.Name)
+		assert.True(t, response.Active)
+		catRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		c, rec := newContext([]byte(`{"name": 1}`))
+
+		err := adminHandler.CreateItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Empty name", func(t *testing.T) {
+		body, _ := json.Marshal(model.CreateItemRequest{Price: 120})
+		c, rec := newContext(body)
+
+		err := adminHandler.CreateItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Non-positive price", func(t *testing.T) {
+		body, _ := json.Marshal(model.CreateItemRequest{Name: "scarf", Price: 0})
+		c, rec := newContext(body)
+
+		err := adminHandler.CreateItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrNegAmount.Error(), errorResp.Errors)
+	})
+
+	t.Run("Item already exists", func(t *testing.T) {
+		body, _ := json.Marshal(model.CreateItemRequest{Name: "cup", Price: 20})
+		c, rec := newContext(body)
+
+		catRepo.On("CreateItem", mock.Anything, mock.Anything).
+			Return(model.ErrItemExists).Once()
+
+		err := adminHandler.CreateItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		body, _ := json.Marshal(model.CreateItemRequest{Name: "mug", Price: 20})
+		c, rec := newContext(body)
+
+		catRepo.On("CreateItem", mock.Anything, mock.Anything).
+			Return(model.ErrInternalError).Once()
+
+		err := adminHandler.CreateItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestCatalogAdminHandler_UpdateItemPrice(t *testing.T) {
+	e := echo.New()
+	catRepo := new(mocks.CatalogRepositoryMock)
+	adminHandler := NewCatalogAdminHandler(catRepo)
+
+	newContext := func(name string, body []byte) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPut, "/api/admin/items/"+name+"/price", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/admin/items/:name/price")
+		c.SetParamNames("name")
+		c.SetParamValues(name)
+		return c, rec
+	}
+
+	t.Run("Successful repricing", func(t *testing.T) {
+		catRepo.On("UpdateItemPrice", mock.Anything, "cup", 25).
+			Return(&model.Item{Name: "cup", Price: 25, Active: true}, nil).Once()
+
+		c, rec := newContext("cup", []byte(`{"price": 25}`))
+		err := adminHandler.UpdateItemPrice(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response model.Item
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.Equal(t, 25, response.Price)
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		c, rec := newContext("cup", []byte(`{"price": "free"}`))
+		err := adminHandler.UpdateItemPrice(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Negative price", func(t *testing.T) {
+		c, rec := newContext("cup", []byte(`{"price": -5}`))
+		err := adminHandler.UpdateItemPrice(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Item not found", func(t *testing.T) {
+		catRepo.On("UpdateItemPrice", mock.Anything, "sword", 25).
+			Return(nil, model.ErrItemNotFound).Once()
+
+		c, rec := newContext("sword", []byte(`{"price": 25}`))
+		err := adminHandler.UpdateItemPrice(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestCatalogAdminHandler_DeactivateItem(t *testing.T) {
+	e := echo.New()
+	catRepo := new(mocks.CatalogRepositoryMock)
+	adminHandler := NewCatalogAdminHandler(catRepo)
+
+	newContext := func(name string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodDelete, "/api/admin/items/"+name, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/admin/items/:name")
+		c.SetParamNames("name")
+		c.SetParamValues(name)
+		return c, rec
+	}
+
+	t.Run("Successful deactivation", func(t *testing.T) {
+		catRepo.On("DeactivateItem", mock.Anything, "umbrella").
+			Return(&model.Item{Name: "umbrella", Price: 200, Active: false}, nil).Once()
+
+		c, rec := newContext("umbrella")
+		err := adminHandler.DeactivateItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response model.Item
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.False(t, response.Active)
+	})
+
+	t.Run("Item not found", func(t *testing.T) {
+		catRepo.On("DeactivateItem", mock.Anything, "sword").
+			Return(nil, model.ErrItemNotFound).Once()
+
+		c, rec := newContext("sword")
+		err := adminHandler.DeactivateItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		catRepo.On("DeactivateItem", mock.Anything, "cup").
+			Return(nil, model.ErrInternalError).Once()
+
+		c, rec := newContext("cup")
+		err := adminHandler.DeactivateItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
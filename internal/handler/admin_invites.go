@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/service"
+)
+
+// A structure for an invite handler
+type InviteAdminHandler struct {
+	registration *service.RegistrationService
+}
+
+// Constructor for invite handler
+func NewInviteAdminHandler(s *service.RegistrationService) *InviteAdminHandler {
+	return &InviteAdminHandler{registration: s}
+}
+
+// Function for /api/admin/invites request
+func (h *InviteAdminHandler) CreateInvite(c echo.Context) error {
+	var req model.CreateInviteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	var expiresAt time.Time
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+		}
+		expiresAt = *req.ExpiresAt
+	}
+
+	userID := c.Get("user_id").(string)
+
+	invite, err := h.registration.CreateInvite(c.Request().Context(), userID, expiresAt)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	return c.JSON(http.StatusCreated, invite)
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/service"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestInviteAdminHandler_CreateInvite(t *testing.T) {
+	e := echo.New()
+	inviteRepo := new(mocks.InviteRepositoryMock)
+	registration := service.NewRegistrationService(new(mocks.UserRepositoryMock), inviteRepo, service.PasswordPolicy{}, nil, time.Hour)
+	inviteHandler := NewInviteAdminHandler(registration)
+
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/invites", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", "admin1")
+		return c, rec
+	}
+
+	t.Run("Successful creation", func(t *testing.T) {
+		inviteRepo.On("CreateInvite", mock.Anything, mock.MatchedBy(func(i *model.Invite) bool {
+			return i.CreatedBy == "admin1"
+		})).Return(nil).Once()
+
+		c, rec := newContext(`{}`)
+		err := inviteHandler.CreateInvite(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response model.InviteResponse
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NotEmpty(t, response.Code)
+		inviteRepo.AssertExpectations(t)
+	})
+
+	t.Run("Expiration in the past", func(t *testing.T) {
+		c, rec := newContext(`{"expiresAt": "2000-01-01T00:00:00Z"}`)
+		err := inviteHandler.CreateInvite(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		inviteRepo.On("CreateInvite", mock.Anything, mock.Anything).Return(model.ErrInternalError).Once()
+
+		c, rec := newContext(`{}`)
+		err := inviteHandler.CreateInvite(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+	"github.com/garaevmir/avitocoinstore/internal/service"
+)
+
+// A structure for a ledger administration handler
+type LedgerAdminHandler struct {
+	ledgerRepo repository.LedgerRepositoryInt
+	userRepo   repository.UserRepositoryInt
+	reconciler *service.ReconcileService
+}
+
+// Constructor for ledger administration handler
+func NewLedgerAdminHandler(
+	lRepo repository.LedgerRepositoryInt,
+	uRepo repository.UserRepositoryInt,
+	r *service.ReconcileService,
+) *LedgerAdminHandler {
+	return &LedgerAdminHandler{ledgerRepo: lRepo, userRepo: uRepo, reconciler: r}
+}
+
+// Function for /api/admin/users/:username/adjustments request
+func (h *LedgerAdminHandler) AdjustBalance(c echo.Context) error {
+	var req model.AdjustBalanceRequest
+	if err := c.Bind(&req); err != nil || req.Amount == 0 || strings.TrimSpace(req.Reason) == "" {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	user, err := h.userRepo.GetUserByUsername(c.Request().Context(), c.Param("username"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	if user == nil {
+		return c.JSON(http.StatusNotFound, model.ErrorResponse{Errors: model.ErrUserNotFound.Error()})
+	}
+
+	coins, err := h.ledgerRepo.AdjustBalance(c.Request().Context(), user.ID, req.Amount, req.Reason)
+	if err != nil {
+		switch err {
+		case model.ErrUserNotFound:
+			return c.JSON(http.StatusNotFound, model.ErrorResponse{Errors: model.ErrUserNotFound.Error()})
+		case model.ErrInsufficientFunds:
+			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInsufficientFunds.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+		}
+	}
+	return c.JSON(http.StatusOK, model.BalanceResponse{Coins: coins})
+}
+
+// Function for /api/admin/reconciliation request, format query parameter selects json or csv
+// and mismatches=true leaves only users whose balance differs
+func (h *LedgerAdminHandler) Reconciliation(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format != "" && format != "json" && format != "csv" {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	reports, err := h.reconciler.Report(c.Request().Context(), c.QueryParam("mismatches") == "true")
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+
+	if format == "csv" {
+		c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+		c.Response().WriteHeader(http.StatusOK)
+		return service.WriteReportsCSV(c.Response(), reports)
+	}
+	return c.JSON(http.StatusOK, reports)
+}
+
+// Function for /api/admin/reconciliation/fix request, writes correcting adjustments for every mismatch
+func (h *LedgerAdminHandler) FixReconciliation(c echo.Context) error {
+	var req model.ReconcileRequest
+	if err := c.Bind(&req); err != nil || strings.TrimSpace(req.Reason) == "" {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	fixed, err := h.reconciler.Fix(c.Request().Context(), req.Reason)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	return c.JSON(http.StatusOK, fixed)
+}
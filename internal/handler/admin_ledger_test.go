@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/service"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestLedgerAdminHandler_AdjustBalance(t *testing.T) {
+	e := echo.New()
+	userRepo := new(mocks.UserRepositoryMock)
+	ledgerRepo := new(mocks.LedgerRepositoryMock)
+	ledgerHandler := NewLedgerAdminHandler(ledgerRepo, userRepo, service.NewReconcileService(ledgerRepo))
+
+	userRepo.On("GetUserByUsername", mock.Anything, "alice").
+		Return(&model.User{ID: "user1", Username: "alice"}, nil)
+
+	newContext := func(username, body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/admin/users/:username/adjustments")
+		c.SetParamNames("username")
+		c.SetParamValues(username)
+		return c, rec
+	}
+
+	t.Run("Successful adjustment", func(t *testing.T) {
+		ledgerRepo.On("AdjustBalance", mock.Anything, "user1", -50, "duplicate grant").
+			Return(950, nil).Once()
+
+		c, rec := newContext("alice", `{"amount": -50, "reason": "duplicate grant"}`)
+		err := ledgerHandler.AdjustBalance(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response model.BalanceResponse
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.Equal(t, 950, response.Coins)
+		ledgerRepo.AssertExpectations(t)
+	})
+
+	t.Run("Missing reason", func(t *testing.T) {
+		c, rec := newContext("alice", `{"amount": 10}`)
+		err := ledgerHandler.AdjustBalance(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Balance would become negative", func(t *testing.T) {
+		ledgerRepo.On("AdjustBalance", mock.Anything, "user1", -5000, "typo").
+			Return(0, model.ErrInsufficientFunds).Once()
+
+		c, rec := newContext("alice", `{"amount": -5000, "reason": "typo"}`)
+		err := ledgerHandler.AdjustBalance(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "ghost").
+			Return((*model.User)(nil), nil).Once()
+
+		c, rec := newContext("ghost", `{"amount": 10, "reason": "bonus"}`)
+		err := ledgerHandler.AdjustBalance(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		ledgerRepo.On("AdjustBalance", mock.Anything, "user1", 10, "bonus").
+			Return(0, model.ErrInternalError).Once()
+
+		c, rec := newContext("alice", `{"amount": 10, "reason": "bonus"}`)
+		err := ledgerHandler.AdjustBalance(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestLedgerAdminHandler_Reconciliation(t *testing.T) {
+	e := echo.New()
+	ledgerRepo := new(mocks.LedgerRepositoryMock)
+	ledgerHandler := NewLedgerAdminHandler(ledgerRepo, new(mocks.UserRepositoryMock), service.NewReconcileService(ledgerRepo))
+
+	balances := []model.BalanceReport{
+		{UserID: "user1", Username: "alice", Coins: 1000, Ledger: 1000, Granted: 1000},
+		{UserID: "user2", Username: "bob", Coins: 1050, Ledger: 1050, Granted: 1000},
+	}
+	for i := range balances {
+		balances[i].Compute()
+	}
+
+	newContext := func(query string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/reconciliation?"+query, nil)
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("JSON report of mismatches", func(t *testing.T) {
+		ledgerRepo.On("GetBalanceReports", mock.Anything).Return(balances, nil).Once()
+
+		c, rec := newContext("mismatches=true")
+		err := ledgerHandler.Reconciliation(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response []model.BalanceReport
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.Len(t, response, 1)
+		assert.Equal(t, 50, response[0].LedgerDifference)
+	})
+
+	t.Run("CSV report", func(t *testing.T) {
+		ledgerRepo.On("GetBalanceReports", mock.Anything).Return(balances, nil).Once()
+
+		c, rec := newContext("format=csv")
+		err := ledgerHandler.Reconciliation(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentType), "text/csv")
+		assert.Contains(t, rec.Body.String(), "user2,bob,1050,1050,1000,50,0,1000,0,0,0,0,0,0")
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		c, rec := newContext("format=xml")
+		err := ledgerHandler.Reconciliation(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		ledgerRepo.On("GetBalanceReports", mock.Anything).
+			Return([]model.BalanceReport(nil), model.ErrInternalError).Once()
+
+		c, rec := newContext("")
+		err := ledgerHandler.Reconciliation(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestLedgerAdminHandler_FixReconciliation(t *testing.T) {
+	e := echo.New()
+	ledgerRepo := new(mocks.LedgerRepositoryMock)
+	ledgerHandler := NewLedgerAdminHandler(ledgerRepo, new(mocks.UserRepositoryMock), service.NewReconcileService(ledgerRepo))
+
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/reconciliation/fix", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("Successful fix", func(t *testing.T) {
+		report := model.BalanceReport{UserID: "user2", Username: "bob", Coins: 1050, Ledger: 1050, Granted: 1000}
+		report.Compute()
+		ledgerRepo.On("GetBalanceReports", mock.Anything).Return([]model.BalanceReport{report}, nil).Once()
+		ledgerRepo.On("ReconcileBalance", mock.Anything, "user2", "audit 2024-02").Return(-50, nil).Once()
+
+		c, rec := newContext(`{"reason": "audit 2024-02"}`)
+		err := ledgerHandler.FixReconciliation(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response []model.BalanceReport
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.Len(t, response, 1)
+		assert.Equal(t, -50, response[0].Corrected)
+		assert.Equal(t, 1000, response[0].Coins)
+		assert.Zero(t, response[0].LedgerDifference)
+		ledgerRepo.AssertExpectations(t)
+	})
+
+	t.Run("Missing reason", func(t *testing.T) {
+		c, rec := newContext(`{}`)
+		err := ledgerHandler.FixReconciliation(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+	"github.com/garaevmir/avitocoinstore/internal/service"
+)
+
+// A structure for a token revocation handler
+type TokenAdminHandler struct {
+	revocationService *service.RevocationService
+	userRepo          repository.UserRepositoryInt
+}
+
+// Constructor for token revocation handler
+func NewTokenAdminHandler(s *service.RevocationService, uRepo repository.UserRepositoryInt) *TokenAdminHandler {
+	return &TokenAdminHandler{revocationService: s, userRepo: uRepo}
+}
+
+// Function for /api/admin/tokens/revoke request
+func (h *TokenAdminHandler) RevokeToken(c echo.Context) error {
+	var req model.RevokeTokenRequest
+	if err := c.Bind(&req); err != nil || req.JTI == "" {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	if err := h.revocationService.RevokeToken(c.Request().Context(), req.JTI); err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"status": "success"})
+}
+
+// Function for /api/admin/users/:username/tokens/revoke request
+func (h *TokenAdminHandler) RevokeUserTokens(c echo.Context) error {
+	var req model.RevokeUserTokensRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	before := time.Now()
+	if req.Before != nil {
+		before = *req.Before
+	}
+
+	user, err := h.userRepo.GetUserByUsername(c.Request().Context(), c.Param("username"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	if user == nil {
+		return c.JSON(http.StatusNotFound, model.ErrorResponse{Errors: model.ErrUserNotFound.Error()})
+	}
+
+	if err := h.revocationService.RevokeUserTokens(c.Request().Context(), user.ID, before); err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"status": "success"})
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/service"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestTokenAdminHandler_RevokeToken(t *testing.T) {
+	e := echo.New()
+	revocationRepo := new(mocks.RevocationRepositoryMock)
+	revocationService := service.NewRevocationService(revocationRepo, new(mocks.RefreshTokenRepositoryMock), time.Minute)
+	tokenHandler := NewTokenAdminHandler(revocationService, new(mocks.UserRepositoryMock))
+
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/tokens/revoke", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("Successful revocation", func(t *testing.T) {
+		revocationRepo.On("RevokeToken", mock.Anything, "jti1", mock.Anything).
+			Return(nil).Once()
+
+		c, rec := newContext(`{"jti": "jti1"}`)
+		err := tokenHandler.RevokeToken(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, revocationService.IsRevoked("jti1", "", time.Now()))
+	})
+
+	t.Run("Missing jti", func(t *testing.T) {
+		c, rec := newContext(`{}`)
+		err := tokenHandler.RevokeToken(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		revocationRepo.On("RevokeToken", mock.Anything, "jti2", mock.Anything).
+			Return(model.ErrInternalError).Once()
+
+		c, rec := newContext(`{"jti": "jti2"}`)
+		err := tokenHandler.RevokeToken(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestTokenAdminHandler_RevokeUserTokens(t *testing.T) {
+	e := echo.New()
+	revocationRepo := new(mocks.RevocationRepositoryMock)
+	refreshRepo := new(mocks.RefreshTokenRepositoryMock)
+	userRepo := new(mocks.UserRepositoryMock)
+	revocationService := service.NewRevocationService(revocationRepo, refreshRepo, time.Minute)
+	tokenHandler := NewTokenAdminHandler(revocationService, userRepo)
+
+	newContext := func(username, body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/users/"+username+"/tokens/revoke",
+			bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/admin/users/:username/tokens/revoke")
+		c.SetParamNames("username")
+		c.SetParamValues(username)
+		return c, rec
+	}
+
+	before := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Successful revocation with timestamp", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "alice").
+			Return(&model.User{ID: "user1"}, nil).Once()
+		revocationRepo.On("RevokeUserTokens", mock.Anything, "user1", before).Return(nil).Once()
+		refreshRepo.On("RevokeUserTokens", mock.Anything, "user1", before).Return(nil).Once()
+
+		c, rec := newContext("alice", `{"before": "2025-02-01T12:00:00Z"}`)
+		err := tokenHandler.RevokeUserTokens(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		revocationRepo.AssertExpectations(t)
+		refreshRepo.AssertExpectations(t)
+	})
+
+	t.Run("Successful revocation without timestamp", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "bob").
+			Return(&model.User{ID: "user2"}, nil).Once()
+		revocationRepo.On("RevokeUserTokens", mock.Anything, "user2", mock.AnythingOfType("time.Time")).
+			Return(nil).Once()
+		refreshRepo.On("RevokeUserTokens", mock.Anything, "user2", mock.AnythingOfType("time.Time")).
+			Return(nil).Once()
+
+		c, rec := newContext("bob", `{}`)
+		err := tokenHandler.RevokeUserTokens(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Invalid timestamp", func(t *testing.T) {
+		c, rec := newContext("alice", `{"before": "yesterday"}`)
+		err := tokenHandler.RevokeUserTokens(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "ghost").
+			Return((*model.User)(nil), nil).Once()
+
+		c, rec := newContext("ghost", `{}`)
+		err := tokenHandler.RevokeUserTokens(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Getting user error", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "alice").
+			Return((*model.User)(nil), model.ErrInternalError).Once()
+
+		c, rec := newContext("alice", `{}`)
+		err := tokenHandler.RevokeUserTokens(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("Revocation error", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "carol").
+			Return(&model.User{ID: "user3"}, nil).Once()
+		revocationRepo.On("RevokeUserTokens", mock.Anything, "user3", mock.Anything).
+			Return(model.ErrInternalError).Once()
+
+		c, rec := newContext("carol", `{}`)
+		err := tokenHandler.RevokeUserTokens(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
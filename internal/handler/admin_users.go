@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+	"github.com/garaevmir/avitocoinstore/internal/service"
+)
+
+// A structure for a user administration handler
+type UserAdminHandler struct {
+	loginGuard        *service.LoginGuard
+	passwordService   *service.PasswordService
+	revocationService *service.RevocationService
+	userRepo          repository.UserRepositoryInt
+}
+
+// Constructor for user administration handler
+func NewUserAdminHandler(
+	g *service.LoginGuard,
+	p *service.PasswordService,
+	r *service.RevocationService,
+	uRepo repository.UserRepositoryInt,
+) *UserAdminHandler {
+	return &UserAdminHandler{loginGuard: g, passwordService: p, revocationService: r, userRepo: uRepo}
+}
+
+// Function for /api/admin/users/:username/unlock request
+func (h *UserAdminHandler) Unlock(c echo.Context) error {
+	user, err := h.userRepo.GetUserByUsername(c.Request().Context(), c.Param("username"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	if user == nil {
+		return c.JSON(http.StatusNotFound, model.ErrorResponse{Errors: model.ErrUserNotFound.Error()})
+	}
+
+	if err := h.loginGuard.Unlock(c.Request().Context(), user.Username); err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"status": "success"})
+}
+
+// Function for /api/admin/users/:username/password-reset request, the token is handed to user out of band
+func (h *UserAdminHandler) CreatePasswordReset(c echo.Context) error {
+	user, err := h.userRepo.GetUserByUsername(c.Request().Context(), c.Param("username"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	if user == nil {
+		return c.JSON(http.StatusNotFound, model.ErrorResponse{Errors: model.ErrUserNotFound.Error()})
+	}
+
+	adminID := c.Get("user_id").(string)
+
+	reset, err := h.passwordService.CreateResetToken(c.Request().Context(), user.ID, adminID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	return c.JSON(http.StatusCreated, reset)
+}
+
+// Function for /api/admin/users/:username/status request, frozen and deactivated users can not receive coins
+func (h *UserAdminHandler) SetStatus(c echo.Context) error {
+	var req model.UserStatusRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	switch req.Status {
+	case model.UserActive, model.UserFrozen, model.UserDeactivated:
+	default:
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidStatus.Error()})
+	}
+
+	user, err := h.userRepo.GetUserByUsername(c.Request().Context(), c.Param("username"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	if user == nil {
+		return c.JSON(http.StatusNotFound, model.ErrorResponse{Errors: model.ErrUserNotFound.Error()})
+	}
+
+	if err := h.userRepo.SetStatus(c.Request().Context(), user.ID, req.Status); err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"status": "success"})
+}
+
+// Function for /api/admin/users/:username/role request, tokens issued before the change are revoked,
+// so user has to log in again and gets tokens with new role
+func (h *UserAdminHandler) SetRole(c echo.Context) error {
+	var req model.UserRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+	if !model.ValidRole(req.Role) {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRole.Error()})
+	}
+
+	user, err := h.userRepo.GetUserByUsername(c.Request().Context(), c.Param("username"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	if user == nil {
+		return c.JSON(http.StatusNotFound, model.ErrorResponse{Errors: model.ErrUserNotFound.Error()})
+	}
+
+	if user.Role == req.Role {
+		return c.JSON(http.StatusOK, map[string]interface{}{"status": "success"})
+	}
+
+	if err := h.userRepo.SetRole(c.Request().Context(), user.ID, req.Role); err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	if err := h.revocationService.RevokeUserTokens(c.Request().Context(), user.ID, time.Now()); err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"status": "success"})
+}
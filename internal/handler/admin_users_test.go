@@ -0,0 +1,288 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/service"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestUserAdminHandler_Unlock(t *testing.T) {
+	e := echo.New()
+	userRepo := new(mocks.UserRepositoryMock)
+	attemptRepo := new(mocks.LoginAttemptRepositoryMock)
+	userHandler := NewUserAdminHandler(service.NewLoginGuard(attemptRepo, service.LockoutPolicy{}), nil, nil, userRepo)
+
+	newContext := func(username string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/admin/users/:username/unlock")
+		c.SetParamNames("username")
+		c.SetParamValues(username)
+		return c, rec
+	}
+
+	t.Run("Successful unlock", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "alice").
+			Return(&model.User{ID: "user1", Username: "alice"}, nil).Once()
+		attemptRepo.On("Reset", mock.Anything, []string{"user:alice"}).Return(nil).Once()
+
+		c, rec := newContext("alice")
+		err := userHandler.Unlock(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		attemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "ghost").
+			Return((*model.User)(nil), nil).Once()
+
+		c, rec := newContext("ghost")
+		err := userHandler.Unlock(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "bob").
+			Return(&model.User{ID: "user2", Username: "bob"}, nil).Once()
+		attemptRepo.On("Reset", mock.Anything, []string{"user:bob"}).Return(model.ErrInternalError).Once()
+
+		c, rec := newContext("bob")
+		err := userHandler.Unlock(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestUserAdminHandler_CreatePasswordReset(t *testing.T) {
+	e := echo.New()
+	userRepo := new(mocks.UserRepositoryMock)
+	resetRepo := new(mocks.PasswordResetRepositoryMock)
+	passwordService := service.NewPasswordService(userRepo, resetRepo, nil, nil, service.PasswordPolicy{}, time.Hour)
+	userHandler := NewUserAdminHandler(nil, passwordService, nil, userRepo)
+
+	newContext := func(username string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/admin/users/:username/password-reset")
+		c.SetParamNames("username")
+		c.SetParamValues(username)
+		c.Set("user_id", "admin1")
+		return c, rec
+	}
+
+	t.Run("Successful creation", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "alice").
+			Return(&model.User{ID: "user1", Username: "alice"}, nil).Once()
+		resetRepo.On("CreateReset", mock.Anything, mock.MatchedBy(func(r *model.PasswordReset) bool {
+			return r.UserID == "user1" && r.CreatedBy == "admin1"
+		})).Return(nil).Once()
+
+		c, rec := newContext("alice")
+		err := userHandler.CreatePasswordReset(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response model.PasswordResetResponse
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NotEmpty(t, response.Token)
+		resetRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "ghost").
+			Return((*model.User)(nil), nil).Once()
+
+		c, rec := newContext("ghost")
+		err := userHandler.CreatePasswordReset(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "bob").
+			Return(&model.User{ID: "user2", Username: "bob"}, nil).Once()
+		resetRepo.On("CreateReset", mock.Anything, mock.Anything).Return(model.ErrInternalError).Once()
+
+		c, rec := newContext("bob")
+		err := userHandler.CreatePasswordReset(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestUserAdminHandler_SetStatus(t *testing.T) {
+	e := echo.New()
+	userRepo := new(mocks.UserRepositoryMock)
+	revocationRepo := new(mocks.RevocationRepositoryMock)
+	refreshRepo := new(mocks.RefreshTokenRepositoryMock)
+	revocationService := service.NewRevocationService(revocationRepo, refreshRepo, time.Minute)
+	userHandler := NewUserAdminHandler(nil, nil, revocationService, userRepo)
+
+	newContext := func(username, body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/admin/users/:username/status")
+		c.SetParamNames("username")
+		c.SetParamValues(username)
+		return c, rec
+	}
+
+	t.Run("Account frozen", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "alice").
+			Return(&model.User{ID: "user1", Username: "alice"}, nil).Once()
+		userRepo.On("SetStatus", mock.Anything, "user1", model.UserFrozen).Return(nil).Once()
+
+		c, rec := newContext("alice", `{"status":"frozen"}`)
+		err := userHandler.SetStatus(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid status", func(t *testing.T) {
+		c, rec := newContext("alice", `{"status":"banned"}`)
+		err := userHandler.SetStatus(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrInvalidStatus.Error(), errorResp.Errors)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "ghost").
+			Return((*model.User)(nil), nil).Once()
+
+		c, rec := newContext("ghost", `{"status":"active"}`)
+		err := userHandler.SetStatus(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "bob").
+			Return(&model.User{ID: "user2", Username: "bob"}, nil).Once()
+		userRepo.On("SetStatus", mock.Anything, "user2", model.UserDeactivated).Return(model.ErrInternalError).Once()
+
+		c, rec := newContext("bob", `{"status":"deactivated"}`)
+		err := userHandler.SetStatus(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestUserAdminHandler_SetRole(t *testing.T) {
+	e := echo.New()
+	userRepo := new(mocks.UserRepositoryMock)
+	revocationRepo := new(mocks.RevocationRepositoryMock)
+	refreshRepo := new(mocks.RefreshTokenRepositoryMock)
+	revocationService := service.NewRevocationService(revocationRepo, refreshRepo, time.Minute)
+	userHandler := NewUserAdminHandler(nil, nil, revocationService, userRepo)
+
+	newContext := func(username, body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/admin/users/:username/role")
+		c.SetParamNames("username")
+		c.SetParamValues(username)
+		return c, rec
+	}
+
+	t.Run("Role assigned and tokens revoked", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "alice").
+			Return(&model.User{ID: "user1", Username: "alice", Role: model.RoleAdmin}, nil).Once()
+		userRepo.On("SetRole", mock.Anything, "user1", model.RoleFinance).Return(nil).Once()
+		revocationRepo.On("RevokeUserTokens", mock.Anything, "user1", mock.Anything).Return(nil).Once()
+		refreshRepo.On("RevokeUserTokens", mock.Anything, "user1", mock.Anything).Return(nil).Once()
+
+		c, rec := newContext("alice", `{"role":"finance"}`)
+		err := userHandler.SetRole(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		userRepo.AssertExpectations(t)
+		revocationRepo.AssertExpectations(t)
+		refreshRepo.AssertExpectations(t)
+		assert.True(t, revocationService.IsRevoked("", "user1", time.Now().Add(-time.Second)))
+	})
+
+	t.Run("Same role keeps tokens", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "bob").
+			Return(&model.User{ID: "user2", Username: "bob", Role: model.RoleFinance}, nil).Once()
+
+		c, rec := newContext("bob", `{"role":"finance"}`)
+		err := userHandler.SetRole(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		userRepo.AssertNotCalled(t, "SetRole", mock.Anything, "user2", mock.Anything)
+		revocationRepo.AssertNotCalled(t, "RevokeUserTokens", mock.Anything, "user2", mock.Anything)
+	})
+
+	t.Run("Revocation error", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "carol").
+			Return(&model.User{ID: "user3", Username: "carol", Role: model.RoleAdmin}, nil).Once()
+		userRepo.On("SetRole", mock.Anything, "user3", model.RoleEmployee).Return(nil).Once()
+		revocationRepo.On("RevokeUserTokens", mock.Anything, "user3", mock.Anything).
+			Return(model.ErrInternalError).Once()
+
+		c, rec := newContext("carol", `{"role":"employee"}`)
+		err := userHandler.SetRole(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("Invalid role", func(t *testing.T) {
+		c, rec := newContext("alice", `{"role":"owner"}`)
+		err := userHandler.SetRole(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrInvalidRole.Error(), errorResp.Errors)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", mock.Anything, "ghost").
+			Return((*model.User)(nil), nil).Once()
+
+		c, rec := newContext("ghost", `{"role":"admin"}`)
+		err := userHandler.SetRole(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
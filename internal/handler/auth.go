@@ -1,25 +1,75 @@
 package handler
 
 import (
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/garaevmir/avitocoinstore/internal/model"
 	"github.com/garaevmir/avitocoinstore/internal/repository"
+	"github.com/garaevmir/avitocoinstore/internal/service"
 )
 
 // A structure for a authentication handler
 type AuthHandler struct {
-	userRepo repository.UserRepositoryInt
-	secret   string
+	userRepo     repository.UserRepositoryInt
+	tokenService *service.TokenService
+	registration *service.RegistrationService
+	loginGuard   *service.LoginGuard
+	autoRegister bool
 }
 
-// Constructor for authentication handler
-func NewAuthHandler(userRepo repository.UserRepositoryInt, secret string) *AuthHandler {
-	return &AuthHandler{userRepo: userRepo, secret: secret}
+// Constructor for authentication handler, when autoRegister is set login creates unknown users
+// as the original assignment requires
+func NewAuthHandler(
+	userRepo repository.UserRepositoryInt,
+	tokenService *service.TokenService,
+	registration *service.RegistrationService,
+	loginGuard *service.LoginGuard,
+	autoRegister bool,
+) *AuthHandler {
+	return &AuthHandler{
+		userRepo:     userRepo,
+		tokenService: tokenService,
+		registration: registration,
+		loginGuard:   loginGuard,
+		autoRegister: autoRegister,
+	}
+}
+
+// Function for /api/register request
+func (h *AuthHandler) Register(c echo.Context) error {
+	var req model.RegisterRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	user, err := h.registration.Register(c.Request().Context(), req.Username, req.Password, req.InviteCode)
+	if err != nil {
+		switch err {
+		case model.ErrInvalidCredentials, model.ErrPasswordTooShort, model.ErrPasswordIsUsername:
+			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: err.Error()})
+		case model.ErrRegistrationClosed:
+			return c.JSON(http.StatusForbidden, model.ErrorResponse{Errors: model.ErrRegistrationClosed.Error()})
+		case model.ErrInvalidInvite:
+			return c.JSON(http.StatusForbidden, model.ErrorResponse{Errors: model.ErrInvalidInvite.Error()})
+		case model.ErrUserExists:
+			return c.JSON(http.StatusConflict, model.ErrorResponse{Errors: model.ErrUserExists.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrCreateUser.Error()})
+		}
+	}
+
+	tokens, err := h.tokenService.Issue(c.Request().Context(), user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	return c.JSON(http.StatusCreated, tokens)
 }
 
 // Function for /api/auth request
@@ -33,29 +83,97 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, model.ErrInvalidCredentials)
 	}
 
-	user, err := h.userRepo.GetUserByUsername(c.Request().Context(), req.Username)
+	ctx := c.Request().Context()
+	ip := c.RealIP()
+
+	retryAfter, err := h.loginGuard.Check(ctx, req.Username, ip)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrInternalError)
+	}
+	if retryAfter > 0 {
+		return tooManyAttempts(c, retryAfter)
+	}
+
+	user, err := h.userRepo.GetUserByUsername(ctx, req.Username)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, model.ErrInternalError)
 	}
 
 	if user == nil {
-		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-		newUser := &model.User{
-			Username:     req.Username,
-			PasswordHash: string(hashedPassword),
-			Coins:        1000,
+		if !h.autoRegister {
+			return h.loginFailed(c, req.Username, ip)
 		}
-		if err := h.userRepo.CreateUser(c.Request().Context(), newUser); err != nil {
+		user, err = h.registration.AutoRegister(ctx, req.Username, req.Password)
+		if err != nil {
 			return c.JSON(http.StatusInternalServerError, model.ErrCreateUser)
 		}
-		user = newUser
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return c.JSON(http.StatusUnauthorized, model.ErrInvalidCredentials)
+		return h.loginFailed(c, req.Username, ip)
+	}
+
+	if err := h.loginGuard.Success(ctx, req.Username); err != nil {
+		log.Printf("Resetting failed logins error: %v", err)
+	}
+
+	tokens, err := h.tokenService.Issue(ctx, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrInternalError)
+	}
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// Function for /api/auth/refresh request
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	var req model.RefreshRequest
+	if err := c.Bind(&req); err != nil || req.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": user.ID})
-	tokenString, _ := token.SignedString([]byte(h.secret))
-	return c.JSON(http.StatusOK, model.AuthResponse{Token: tokenString})
+	tokens, err := h.tokenService.Refresh(c.Request().Context(), req.RefreshToken)
+	if err != nil {
+		switch err {
+		case model.ErrInvalidRefresh:
+			return c.JSON(http.StatusUnauthorized, model.ErrorResponse{Errors: model.ErrInvalidRefresh.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+		}
+	}
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// Function for /api/logout request, revokes refresh token together with every token rotated from the same login
+func (h *AuthHandler) Logout(c echo.Context) error {
+	var req model.RefreshRequest
+	if err := c.Bind(&req); err != nil || req.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	userID := c.Get("user_id").(string)
+
+	if err := h.tokenService.Revoke(c.Request().Context(), userID, req.RefreshToken); err != nil {
+		switch err {
+		case model.ErrInvalidRefresh:
+			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRefresh.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+		}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"status": "success"})
+}
+
+// Records failed login and responds with invalid credentials, failure to record does not change the response
+func (h *AuthHandler) loginFailed(c echo.Context, username, ip string) error {
+	if err := h.loginGuard.Failure(c.Request().Context(), username, ip); err != nil {
+		log.Printf("Recording failed login error: %v", err)
+	}
+	return c.JSON(http.StatusUnauthorized, model.ErrInvalidCredentials)
+}
+
+// Responds that login is locked and when it can be retried
+func tooManyAttempts(c echo.Context, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+	return c.JSON(http.StatusTooManyRequests, model.ErrorResponse{Errors: model.ErrTooManyAttempts.Error()})
 }
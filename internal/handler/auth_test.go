@@ -6,20 +6,43 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/garaevmir/avitocoinstore/internal/keystore"
 	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/service"
 	"github.com/garaevmir/avitocoinstore/tests/mocks"
 )
 
+var testKeys = newTestKeys()
+
+func newTestKeys() *keystore.KeyStore {
+	key, err := keystore.GenerateKey()
+	if err != nil {
+		panic(err)
+	}
+	return keystore.NewFromKeys(key)
+}
+
 func TestAuthHandler_Login(t *testing.T) {
 	e := echo.New()
 	userRepo := new(mocks.UserRepositoryMock)
-	authHandler := NewAuthHandler(userRepo, "test-secret-key")
+	refreshRepo := new(mocks.RefreshTokenRepositoryMock)
+	tokenService := service.NewTokenService(userRepo, refreshRepo, testKeys, time.Minute, time.Hour)
+	registration := service.NewRegistrationService(userRepo, new(mocks.InviteRepositoryMock), service.PasswordPolicy{}, nil, time.Hour)
+	attemptRepo := new(mocks.LoginAttemptRepositoryMock)
+	loginGuard := service.NewLoginGuard(attemptRepo, service.LockoutPolicy{UserAttempts: 5, IPAttempts: 50})
+	authHandler := NewAuthHandler(userRepo, tokenService, registration, loginGuard, true)
+
+	attemptRepo.On("GetLockRemaining", mock.Anything, mock.Anything).Return(time.Duration(0), nil).Maybe()
+	attemptRepo.On("RecordFailure", mock.Anything, mock.Anything, mock.Anything).Return(1, nil).Maybe()
+	attemptRepo.On("Reset", mock.Anything, mock.Anything).Return(nil).Maybe()
 
 	t.Run("Invalid request", func(t *testing.T) {
 		reqBody := map[string]int{
@@ -61,6 +84,9 @@ func TestAuthHandler_Login(t *testing.T) {
 				bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte("testpass")) == nil
 		})).Return(nil).Once()
 
+		refreshRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).
+			Return(nil).Once()
+
 		err := authHandler.Login(c)
 
 		assert.NoError(t, err)
@@ -69,6 +95,13 @@ func TestAuthHandler_Login(t *testing.T) {
 		var response model.AuthResponse
 		json.Unmarshal(rec.Body.Bytes(), &response)
 		assert.NotEmpty(t, response.Token)
+		assert.NotEmpty(t, response.RefreshToken)
+
+		claims := jwt.MapClaims{}
+		_, err = jwt.ParseWithClaims(response.Token, claims, testKeys.Keyfunc)
+		assert.NoError(t, err)
+		assert.Equal(t, model.RoleEmployee, claims["role"])
+		assert.NotNil(t, claims["exp"])
 
 		userRepo.AssertExpectations(t)
 	})
@@ -186,4 +219,376 @@ func TestAuthHandler_Login(t *testing.T) {
 
 		userRepo.AssertExpectations(t)
 	})
+
+	t.Run("Unknown user without auto registration", func(t *testing.T) {
+		strictHandler := NewAuthHandler(userRepo, tokenService, registration, loginGuard, false)
+
+		body, _ := json.Marshal(model.AuthRequest{Username: "typo_user", Password: "testpass"})
+		req := httptest.NewRequest(http.MethodPost, "/api/auth", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		userRepo.On("GetUserByUsername", mock.Anything, "typo_user").
+			Return((*model.User)(nil), nil).Once()
+
+		err := strictHandler.Login(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		userRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.MatchedBy(func(u *model.User) bool {
+			return u.Username == "typo_user"
+		}))
+	})
+}
+
+func TestAuthHandler_LoginLockout(t *testing.T) {
+	e := echo.New()
+	userRepo := new(mocks.UserRepositoryMock)
+	attemptRepo := new(mocks.LoginAttemptRepositoryMock)
+	tokenService := service.NewTokenService(userRepo, new(mocks.RefreshTokenRepositoryMock), testKeys, time.Minute, time.Hour)
+	loginGuard := service.NewLoginGuard(attemptRepo, service.LockoutPolicy{
+		UserAttempts: 3,
+		IPAttempts:   50,
+		BaseDelay:    time.Second,
+		MaxDelay:     time.Minute,
+		Window:       time.Hour,
+	})
+	authHandler := NewAuthHandler(userRepo, tokenService, nil, loginGuard, false)
+
+	hashedPass, _ := bcrypt.GenerateFromPassword([]byte("correct_pass"), bcrypt.DefaultCost)
+	existingUser := &model.User{ID: "user1", Username: "alice", PasswordHash: string(hashedPass)}
+
+	newContext := func(password string) (echo.Context, *httptest.ResponseRecorder) {
+		body, _ := json.Marshal(model.AuthRequest{Username: "alice", Password: password})
+		req := httptest.NewRequest(http.MethodPost, "/api/auth", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = "10.0.0.1:1234"
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("Locked account", func(t *testing.T) {
+		attemptRepo.On("GetLockRemaining", mock.Anything, []string{"user:alice", "ip:10.0.0.1"}).
+			Return(1500*time.Millisecond, nil).Once()
+
+		c, rec := newContext("correct_pass")
+		err := authHandler.Login(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+		userRepo.AssertNotCalled(t, "GetUserByUsername", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Failure that reaches limit locks account", func(t *testing.T) {
+		attemptRepo.On("GetLockRemaining", mock.Anything, mock.Anything).Return(time.Duration(0), nil).Once()
+		userRepo.On("GetUserByUsername", mock.Anything, "alice").Return(existingUser, nil).Once()
+		attemptRepo.On("RecordFailure", mock.Anything, "user:alice", time.Hour).Return(3, nil).Once()
+		attemptRepo.On("Lock", mock.Anything, "user:alice", time.Second).Return(nil).Once()
+		attemptRepo.On("RecordFailure", mock.Anything, "ip:10.0.0.1", time.Hour).Return(3, nil).Once()
+
+		c, rec := newContext("wrong_pass")
+		err := authHandler.Login(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		attemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown user counts as failure", func(t *testing.T) {
+		body, _ := json.Marshal(model.AuthRequest{Username: "ghost", Password: "pass"})
+		req := httptest.NewRequest(http.MethodPost, "/api/auth", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = "10.0.0.1:1234"
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		attemptRepo.On("GetLockRemaining", mock.Anything, mock.Anything).Return(time.Duration(0), nil).Once()
+		userRepo.On("GetUserByUsername", mock.Anything, "ghost").Return((*model.User)(nil), nil).Once()
+		attemptRepo.On("RecordFailure", mock.Anything, "user:ghost", time.Hour).Return(1, nil).Once()
+		attemptRepo.On("RecordFailure", mock.Anything, "ip:10.0.0.1", time.Hour).Return(4, nil).Once()
+
+		err := authHandler.Login(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		attemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("Successful login resets failures", func(t *testing.T) {
+		refreshRepo := new(mocks.RefreshTokenRepositoryMock)
+		tokenService := service.NewTokenService(userRepo, refreshRepo, testKeys, time.Minute, time.Hour)
+		authHandler := NewAuthHandler(userRepo, tokenService, nil, loginGuard, false)
+
+		attemptRepo.On("GetLockRemaining", mock.Anything, mock.Anything).Return(time.Duration(0), nil).Once()
+		userRepo.On("GetUserByUsername", mock.Anything, "alice").Return(existingUser, nil).Once()
+		attemptRepo.On("Reset", mock.Anything, []string{"user:alice"}).Return(nil).Once()
+		refreshRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil).Once()
+
+		c, rec := newContext("correct_pass")
+		err := authHandler.Login(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		attemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		attemptRepo.On("GetLockRemaining", mock.Anything, mock.Anything).
+			Return(time.Duration(0), model.ErrInternalError).Once()
+
+		c, rec := newContext("correct_pass")
+		err := authHandler.Login(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestAuthHandler_Register(t *testing.T) {
+	e := echo.New()
+	userRepo := new(mocks.UserRepositoryMock)
+	inviteRepo := new(mocks.InviteRepositoryMock)
+	refreshRepo := new(mocks.RefreshTokenRepositoryMock)
+	txMock := new(mocks.TxMock)
+	tokenService := service.NewTokenService(userRepo, refreshRepo, testKeys, time.Minute, time.Hour)
+	policy := service.PasswordPolicy{MinLength: 8}
+	registration := service.NewRegistrationService(userRepo, inviteRepo, policy, []string{"allowed"}, time.Hour)
+	authHandler := NewAuthHandler(userRepo, tokenService, registration, nil, false)
+
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/api/register", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("Allowed username", func(t *testing.T) {
+		userRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(u *model.User) bool {
+			return u.Username == "allowed"
+		})).Return(nil).Once()
+		refreshRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil).Once()
+
+		c, rec := newContext(`{"username": "allowed", "password": "password1"}`)
+		err := authHandler.Register(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response model.AuthResponse
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NotEmpty(t, response.Token)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("Valid invite code", func(t *testing.T) {
+		userRepo.On("BeginTx", mock.Anything).Return(txMock, nil).Once()
+		userRepo.On("CreateUserTx", mock.Anything, txMock, mock.Anything).Return(nil).Once()
+		inviteRepo.On("UseInviteTx", mock.Anything, txMock, mock.Anything, mock.Anything).Return(true, nil).Once()
+		txMock.On("Commit", mock.Anything).Return(nil).Once()
+		txMock.On("Rollback", mock.Anything).Return(nil).Once()
+		refreshRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil).Once()
+
+		c, rec := newContext(`{"username": "invited", "password": "password1", "inviteCode": "code"}`)
+		err := authHandler.Register(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		inviteRepo.AssertExpectations(t)
+	})
+
+	t.Run("Registration closed", func(t *testing.T) {
+		c, rec := newContext(`{"username": "stranger", "password": "password1"}`)
+		err := authHandler.Register(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrRegistrationClosed.Error(), errorResp.Errors)
+	})
+
+	t.Run("Invalid invite code", func(t *testing.T) {
+		userRepo.On("BeginTx", mock.Anything).Return(txMock, nil).Once()
+		userRepo.On("CreateUserTx", mock.Anything, txMock, mock.Anything).Return(nil).Once()
+		inviteRepo.On("UseInviteTx", mock.Anything, txMock, mock.Anything, mock.Anything).Return(false, nil).Once()
+		txMock.On("Rollback", mock.Anything).Return(nil).Once()
+
+		c, rec := newContext(`{"username": "invited2", "password": "password1", "inviteCode": "used"}`)
+		err := authHandler.Register(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Username taken", func(t *testing.T) {
+		userRepo.On("CreateUser", mock.Anything, mock.Anything).Return(model.ErrUserExists).Once()
+
+		c, rec := newContext(`{"username": "allowed", "password": "password1"}`)
+		err := authHandler.Register(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("Empty credentials", func(t *testing.T) {
+		c, rec := newContext(`{"username": "", "password": ""}`)
+		err := authHandler.Register(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Weak password", func(t *testing.T) {
+		c, rec := newContext(`{"username": "allowed", "password": "short"}`)
+		err := authHandler.Register(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrPasswordTooShort.Error(), errorResp.Errors)
+	})
+}
+
+func TestAuthHandler_Refresh(t *testing.T) {
+	e := echo.New()
+	userRepo := new(mocks.UserRepositoryMock)
+	refreshRepo := new(mocks.RefreshTokenRepositoryMock)
+	tokenService := service.NewTokenService(userRepo, refreshRepo, testKeys, time.Minute, time.Hour)
+	authHandler := NewAuthHandler(userRepo, tokenService, nil, nil, false)
+
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("Successful refresh", func(t *testing.T) {
+		refreshRepo.On("ConsumeRefreshToken", mock.Anything, mock.Anything).
+			Return(&model.RefreshToken{UserID: "user1", FamilyID: "family1"}, nil).Once()
+		userRepo.On("GetUserByID", mock.Anything, "user1").
+			Return(&model.User{ID: "user1", Role: model.RoleEmployee}, nil).Once()
+		refreshRepo.On("CreateRefreshToken", mock.Anything, mock.MatchedBy(func(rt *model.RefreshToken) bool {
+			return rt.UserID == "user1" && rt.FamilyID == "family1"
+		})).Return(nil).Once()
+
+		c, rec := newContext(`{"refreshToken": "token"}`)
+		err := authHandler.Refresh(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response model.AuthResponse
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NotEmpty(t, response.Token)
+		assert.NotEmpty(t, response.RefreshToken)
+		refreshRepo.AssertExpectations(t)
+	})
+
+	t.Run("Empty refresh token", func(t *testing.T) {
+		c, rec := newContext(`{}`)
+		err := authHandler.Refresh(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Invalid refresh token", func(t *testing.T) {
+		refreshRepo.On("ConsumeRefreshToken", mock.Anything, mock.Anything).
+			Return(nil, nil).Once()
+		refreshRepo.On("GetRefreshToken", mock.Anything, mock.Anything).
+			Return(nil, nil).Once()
+
+		c, rec := newContext(`{"refreshToken": "unknown"}`)
+		err := authHandler.Refresh(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrInvalidRefresh.Error(), errorResp.Errors)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		refreshRepo.On("ConsumeRefreshToken", mock.Anything, mock.Anything).
+			Return(nil, model.ErrInternalError).Once()
+
+		c, rec := newContext(`{"refreshToken": "token"}`)
+		err := authHandler.Refresh(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestAuthHandler_Logout(t *testing.T) {
+	e := echo.New()
+	userRepo := new(mocks.UserRepositoryMock)
+	refreshRepo := new(mocks.RefreshTokenRepositoryMock)
+	tokenService := service.NewTokenService(userRepo, refreshRepo, testKeys, time.Minute, time.Hour)
+	authHandler := NewAuthHandler(userRepo, tokenService, nil, nil, false)
+
+	middleware := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", "user1")
+			return next(c)
+		}
+	}
+
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/api/logout", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("Successful logout", func(t *testing.T) {
+		refreshRepo.On("GetRefreshToken", mock.Anything, mock.Anything).
+			Return(&model.RefreshToken{UserID: "user1", FamilyID: "family1"}, nil).Once()
+		refreshRepo.On("RevokeFamily", mock.Anything, "family1").
+			Return(nil).Once()
+
+		c, rec := newContext(`{"refreshToken": "token"}`)
+		err := middleware(authHandler.Logout)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		refreshRepo.AssertExpectations(t)
+	})
+
+	t.Run("Empty refresh token", func(t *testing.T) {
+		c, rec := newContext(`{}`)
+		err := middleware(authHandler.Logout)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Token of another user", func(t *testing.T) {
+		refreshRepo.On("GetRefreshToken", mock.Anything, mock.Anything).
+			Return(&model.RefreshToken{UserID: "user2", FamilyID: "family2"}, nil).Once()
+
+		c, rec := newContext(`{"refreshToken": "token"}`)
+		err := middleware(authHandler.Logout)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		refreshRepo.On("GetRefreshToken", mock.Anything, mock.Anything).
+			Return(nil, model.ErrInternalError).Once()
+
+		c, rec := newContext(`{"refreshToken": "token"}`)
+		err := middleware(authHandler.Logout)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
 }
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/service"
+)
+
+// A structure for a cart handler
+type CartHandler struct {
+	cartService *service.CartService
+}
+
+// Constructor for cart handler
+func NewCartHandler(s *service.CartService) *CartHandler {
+	return &CartHandler{cartService: s}
+}
+
+// Function for GET /api/cart request
+func (h *CartHandler) GetCart(c echo.Context) error {
+	cart, err := h.cartService.GetCart(c.Request().Context(), c.Get("user_id").(string))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	return c.JSON(http.StatusOK, cart)
+}
+
+// Function for PUT /api/cart/:item request, adds item to cart or changes its quantity
+func (h *CartHandler) SetItem(c echo.Context) error {
+	var req model.CartItemRequest
+	if err := c.Bind(&req); err != nil || req.Quantity <= 0 {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	err := h.cartService.SetItem(c.Request().Context(), c.Get("user_id").(string), c.Param("item"), req.Quantity)
+	if err != nil {
+		switch err {
+		case model.ErrItemNotFound:
+			return c.JSON(http.StatusNotFound, model.ErrorResponse{Errors: model.ErrItemNotFound.Error()})
+		case model.ErrInvalidRequest:
+			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+		}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"status": "success"})
+}
+
+// Function for DELETE /api/cart/:item request
+func (h *CartHandler) RemoveItem(c echo.Context) error {
+	err := h.cartService.RemoveItem(c.Request().Context(), c.Get("user_id").(string), c.Param("item"))
+	if err != nil {
+		switch err {
+		case model.ErrItemNotFound:
+			return c.JSON(http.StatusNotFound, model.ErrorResponse{Errors: model.ErrItemNotFound.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+		}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"status": "success"})
+}
+
+// Function for /api/cart/checkout request
+func (h *CartHandler) Checkout(c echo.Context) error {
+	orders, changes, err := h.cartService.Checkout(c.Request().Context(), c.Get("user_id").(string))
+	if err != nil {
+		switch err {
+		case model.ErrPriceChanged:
+			return c.JSON(http.StatusConflict, model.PriceChangedResponse{Errors: model.ErrPriceChanged.Error(), Changes: changes})
+		case model.ErrCartEmpty:
+			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrCartEmpty.Error()})
+		default:
+			return buyError(c, err)
+		}
+	}
+
+	total := 0
+	for _, order := range orders {
+		total += order.UnitPrice * order.Quantity
+	}
+	return c.JSON(http.StatusCreated, model.PlaceOrderResponse{Orders: orders, Total: total})
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/service"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestCartHandler(t *testing.T) {
+	e := echo.New()
+	userRepo := new(mocks.UserRepositoryMock)
+	invRepo := new(mocks.InventoryRepositoryMock)
+	catRepo := new(mocks.CatalogRepositoryMock)
+	ledgerRepo := new(mocks.LedgerRepositoryMock)
+	orderRepo := new(mocks.OrderRepositoryMock)
+	cartRepo := new(mocks.CartRepositoryMock)
+	shopService := service.NewShopService(userRepo, new(mocks.TransactionRepositoryMock), invRepo, catRepo, ledgerRepo, orderRepo)
+	cartHandler := NewCartHandler(service.NewCartService(cartRepo, catRepo, shopService))
+
+	catRepo.On("GetItemByName", mock.Anything, "hoody").
+		Return(&model.Item{Name: "hoody", Price: 350, Active: true}, nil)
+	catRepo.On("GetItemByName", mock.Anything, "unknown_item").
+		Return(nil, nil)
+	catRepo.On("GetItemForShareTx", mock.Anything, mock.Anything, "hoody").
+		Return(&model.Item{Name: "hoody", Price: 350, Active: true}, nil)
+
+	newContext := func(method, item, body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, "/", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", "user1")
+		if item != "" {
+			c.SetPath("/api/cart/:item")
+			c.SetParamNames("item")
+			c.SetParamValues(item)
+		}
+		return c, rec
+	}
+
+	t.Run("Set line", func(t *testing.T) {
+		cartRepo.On("SetItem", mock.Anything, "user1", "hoody", 2, 350).Return(nil).Once()
+
+		c, rec := newContext(http.MethodPut, "hoody", `{"quantity": 2}`)
+		err := cartHandler.SetItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		cartRepo.AssertExpectations(t)
+	})
+
+	t.Run("Set unknown item", func(t *testing.T) {
+		c, rec := newContext(http.MethodPut, "unknown_item", `{"quantity": 1}`)
+		err := cartHandler.SetItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Set invalid quantity", func(t *testing.T) {
+		c, rec := newContext(http.MethodPut, "hoody", `{"quantity": -1}`)
+		err := cartHandler.SetItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Remove line not in cart", func(t *testing.T) {
+		cartRepo.On("RemoveItem", mock.Anything, "user1", "hoody").Return(false, nil).Once()
+
+		c, rec := newContext(http.MethodDelete, "hoody", "")
+		err := cartHandler.RemoveItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("List cart", func(t *testing.T) {
+		cartRepo.On("GetCart", mock.Anything, "user1").
+			Return([]model.CartItem{{Item: "hoody", Quantity: 2, UnitPrice: 350}}, nil).Once()
+
+		c, rec := newContext(http.MethodGet, "", "")
+		err := cartHandler.GetCart(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response model.CartResponse
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.Len(t, response.Items, 1)
+		assert.Equal(t, 700, response.Total)
+	})
+
+	t.Run("Checkout", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		txMock.On("Rollback", mock.Anything).Return(nil).Once()
+		txMock.On("Commit", mock.Anything).Return(nil).Once()
+
+		cartRepo.On("GetCartForUpdateTx", mock.Anything, txMock, "user1").
+			Return([]model.CartItem{{Item: "hoody", Quantity: 2, UnitPrice: 350}}, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, mock.Anything, "user1").Return(1000, nil).Once()
+		userRepo.On("BeginTx", mock.Anything).Return(txMock, nil).Once()
+		ledgerRepo.On("PostTx", mock.Anything, txMock, model.PurchasePosting("user1", 700, "hoody")).Return(nil).Once()
+		invRepo.On("AddToInventoryTx", mock.Anything, txMock, "user1", "hoody", 2).Return(nil).Once()
+		orderRepo.On("CreateOrderTx", mock.Anything, txMock, mock.AnythingOfType("*model.Order")).Return(nil).Once()
+		cartRepo.On("RemoveItemsTx", mock.Anything, txMock, "user1", []string{"hoody"}).Return(nil).Once()
+
+		c, rec := newContext(http.MethodPost, "", "")
+		err := cartHandler.Checkout(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response model.PlaceOrderResponse
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.Len(t, response.Orders, 1)
+		assert.Equal(t, 700, response.Total)
+	})
+
+	t.Run("Checkout after price change", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		txMock.On("Rollback", mock.Anything).Return(nil).Once()
+
+		cartRepo.On("GetCartForUpdateTx", mock.Anything, txMock, "user1").
+			Return([]model.CartItem{{Item: "hoody", Quantity: 2, UnitPrice: 300}}, nil).Once()
+		userRepo.On("BeginTx", mock.Anything).Return(txMock, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, txMock, "user1").Return(1000, nil).Once()
+
+		c, rec := newContext(http.MethodPost, "", "")
+		err := cartHandler.Checkout(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+
+		var response model.PriceChangedResponse
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.Equal(t, model.ErrPriceChanged.Error(), response.Errors)
+		assert.Equal(t, []model.PriceChange{{Item: "hoody", OldPrice: 300, NewPrice: 350}}, response.Changes)
+	})
+
+	t.Run("Checkout of empty cart", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		txMock.On("Rollback", mock.Anything).Return(nil).Once()
+
+		userRepo.On("BeginTx", mock.Anything).Return(txMock, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, txMock, "user1").Return(1000, nil).Once()
+		cartRepo.On("GetCartForUpdateTx", mock.Anything, txMock, "user1").Return([]model.CartItem{}, nil).Once()
+
+		c, rec := newContext(http.MethodPost, "", "")
+		err := cartHandler.Checkout(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
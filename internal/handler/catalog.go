@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+)
+
+// A structure for a catalog handler
+type CatalogHandler struct {
+	catalogRepo repository.CatalogRepositoryInt
+	userRepo    repository.UserRepositoryInt
+}
+
+// Constructor for catalog handler
+func NewCatalogHandler(cRepo repository.CatalogRepositoryInt, uRepo repository.UserRepositoryInt) *CatalogHandler {
+	return &CatalogHandler{catalogRepo: cRepo, userRepo: uRepo}
+}
+
+// Function for /api/items request
+func (h *CatalogHandler) ListItems(c echo.Context) error {
+	maxPrice := -1
+	if affordable := c.QueryParam("affordable"); affordable != "" {
+		onlyAffordable, err := strconv.ParseBool(affordable)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+		}
+		if onlyAffordable {
+			user, err := h.userRepo.GetUserByID(c.Request().Context(), c.Get("user_id").(string))
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+			}
+			maxPrice = user.Coins
+		}
+	}
+
+	items, err := h.catalogRepo.ListItems(c.Request().Context(), c.QueryParam("sort"), maxPrice)
+	if err != nil {
+		switch err {
+		case model.ErrInvalidRequest:
+			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+		}
+	}
+
+	response := make([]model.ItemResponse, 0, len(items))
+	for _, item := range items {
+		response = append(response, toItemResponse(item))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// Function for /api/items/:name request
+func (h *CatalogHandler) GetItem(c echo.Context) error {
+	item, err := h.catalogRepo.GetItemByName(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+	if item == nil {
+		return c.JSON(http.StatusNotFound, model.ErrorResponse{Errors: model.ErrItemNotFound.Error()})
+	}
+	return c.JSON(http.StatusOK, toItemResponse(*item))
+}
+
+// Converts catalog item to its public representation
+func toItemResponse(item model.Item) model.ItemResponse {
+	return model.ItemResponse{
+		Name:        item.Name,
+		Price:       item.Price,
+		Description: item.Description,
+		Available:   item.Active,
+	}
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestCatalogHandler_ListItems(t *testing.T) {
+	e := echo.New()
+	catRepo := new(mocks.CatalogRepositoryMock)
+	userRepo := new(mocks.UserRepositoryMock)
+	catalogHandler := NewCatalogHandler(catRepo, userRepo)
+
+	middleware := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", "user1")
+			return next(c)
+		}
+	}
+
+	mockItems := []model.Item{
+		{Name: "pen", Price: 10, Active: true},
+		{Name: "cup", Price: 20, Active: true},
+	}
+
+	t.Run("Successful listing", func(t *testing.T) {
+		catRepo.On("ListItems", mock.Anything, "price", -1).
+			Return(mockItems, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/items?sort=price", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := middleware(catalogHandler.ListItems)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response []model.ItemResponse
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.Len(t, response, 2)
+		assert.Equal(t, "pen", response[0].Name)
+		assert.True(t, response[0].Available)
+		catRepo.AssertExpectations(t)
+	})
+
+	t.Run("Only affordable items", func(t *testing.T) {
+		userRepo.On("GetUserByID", mock.Anything, "user1").
+			Return(&model.User{ID: "user1", Coins: 15}, nil).Once()
+		catRepo.On("ListItems", mock.Anything, "", 15).
+			Return(mockItems[:1], nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/items?affordable=true", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := middleware(catalogHandler.ListItems)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response []model.ItemResponse
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.Len(t, response, 1)
+		userRepo.AssertExpectations(t)
+		catRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid affordable flag", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/items?affordable=maybe", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := middleware(catalogHandler.ListItems)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Invalid sort", func(t *testing.T) {
+		catRepo.On("ListItems", mock.Anything, "weight", -1).
+			Return([]model.Item(nil), model.ErrInvalidRequest).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/items?sort=weight", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := middleware(catalogHandler.ListItems)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrInvalidRequest.Error(), errorResp.Errors)
+	})
+
+	t.Run("Getting user error", func(t *testing.T) {
+		userRepo.On("GetUserByID", mock.Anything, "user1").
+			Return(nil, model.ErrInternalError).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/items?affordable=true", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := middleware(catalogHandler.ListItems)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		catRepo.On("ListItems", mock.Anything, "", -1).
+			Return([]model.Item(nil), model.ErrInternalError).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/items", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := middleware(catalogHandler.ListItems)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestCatalogHandler_GetItem(t *testing.T) {
+	e := echo.New()
+	catRepo := new(mocks.CatalogRepositoryMock)
+	catalogHandler := NewCatalogHandler(catRepo, new(mocks.UserRepositoryMock))
+
+	newContext := func(name string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/api/items/"+name, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/items/:name")
+		c.SetParamNames("name")
+		c.SetParamValues(name)
+		return c, rec
+	}
+
+	t.Run("Successful item retrieval", func(t *testing.T) {
+		catRepo.On("GetItemByName", mock.Anything, "hoody").
+			Return(&model.Item{Name: "hoody", Price: 300, Description: "warm", Active: false}, nil).Once()
+
+		c, rec := newContext("hoody")
+		err := catalogHandler.GetItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response model.ItemResponse
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.Equal(t, model.ItemResponse{Name: "hoody", Price: 300, Description: "warm", Available: false}, response)
+	})
+
+	t.Run("Item not found", func(t *testing.T) {
+		catRepo.On("GetItemByName", mock.Anything, "sword").
+			Return(nil, nil).Once()
+
+		c, rec := newContext("sword")
+		err := catalogHandler.GetItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		catRepo.On("GetItemByName", mock.Anything, "cup").
+			Return(nil, model.ErrInternalError).Once()
+
+		c, rec := newContext("cup")
+		err := catalogHandler.GetItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
@@ -6,18 +6,17 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/garaevmir/avitocoinstore/internal/model"
-	"github.com/garaevmir/avitocoinstore/internal/repository"
+	"github.com/garaevmir/avitocoinstore/internal/service"
 )
 
 // A structure for a send coin handler
 type CoinHandler struct {
-	transactionRepo repository.TransactionRepositoryInt
-	userRepo        repository.UserRepositoryInt
+	transferService *service.TransferService
 }
 
 // Constructor for send coin handler
-func NewCoinHandler(tRepo repository.TransactionRepositoryInt, uRepo repository.UserRepositoryInt) *CoinHandler {
-	return &CoinHandler{transactionRepo: tRepo, userRepo: uRepo}
+func NewCoinHandler(s *service.TransferService) *CoinHandler {
+	return &CoinHandler{transferService: s}
 }
 
 // Function for /api/sendCoin request
@@ -27,28 +26,23 @@ func (h *CoinHandler) SendCoins(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
 	}
 
-	if req.Amount <= 0 {
-		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrNegAmount.Error()})
-	}
-
 	if req.ToUser == "" {
 		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrUserNotFound.Error()})
 	}
 
 	fromUserID := c.Get("user_id").(string)
-	toUser, err := h.userRepo.GetUserByUsername(c.Request().Context(), req.ToUser)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrUserNotFound.Error()})
-	}
-
-	if toUser == nil {
-		return c.JSON(http.StatusNotFound, model.ErrorResponse{Errors: model.ErrUserNotFound.Error()})
-	}
-
-	if err := h.transactionRepo.TransferCoins(c.Request().Context(), fromUserID, toUser.ID, req.Amount); err != nil {
+	note := model.TransferNote{Message: req.Message, Reason: req.Reason}
+	if err := h.transferService.SendCoins(c.Request().Context(), fromUserID, req.ToUser, req.Amount, note); err != nil {
 		switch err {
-		case model.ErrInsufficientFunds:
-			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInsufficientFunds.Error()})
+		case model.ErrNegAmount, model.ErrInsufficientFunds, model.ErrSelfTransfer, model.ErrRecipientInactive,
+			model.ErrTransferLimit, model.ErrDailyLimit, model.ErrMessageTooLong, model.ErrInvalidReason:
+			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: err.Error()})
+		case model.ErrRecipientNotAllowed:
+			return c.JSON(http.StatusForbidden, model.ErrorResponse{Errors: err.Error()})
+		case model.ErrTooManyTransfers:
+			return c.JSON(http.StatusTooManyRequests, model.ErrorResponse{Errors: err.Error()})
+		case model.ErrUserNotFound:
+			return c.JSON(http.StatusNotFound, model.ErrorResponse{Errors: model.ErrUserNotFound.Error()})
 		default:
 			return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
 		}
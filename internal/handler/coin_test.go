@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/service"
 	"github.com/garaevmir/avitocoinstore/tests/mocks"
 )
 
@@ -20,7 +21,8 @@ func TestCoinHandler_SendCoins(t *testing.T) {
 	e := echo.New()
 	userRepo := new(mocks.UserRepositoryMock)
 	txRepo := new(mocks.TransactionRepositoryMock)
-	coinHandler := NewCoinHandler(txRepo, userRepo)
+	coinHandler := NewCoinHandler(service.NewTransferService(userRepo, txRepo,
+		service.SelfTransferPolicy{}, service.ActiveRecipientPolicy{}, service.MaxAmountPolicy{Max: 500}))
 
 	middleware := func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -42,9 +44,9 @@ func TestCoinHandler_SendCoins(t *testing.T) {
 		c := e.NewContext(req, rec)
 
 		userRepo.On("GetUserByUsername", mock.Anything, "user2").
-			Return(&model.User{ID: "user2"}, nil).Once()
+			Return(&model.User{ID: "user2", Status: model.UserActive}, nil).Once()
 
-		txRepo.On("TransferCoins", mock.Anything, "user1", "user2", 100).
+		txRepo.On("TransferCoins", mock.Anything, "user1", "user2", 100, model.TransferNote{}).
 			Return(nil).Once()
 
 		err := middleware(coinHandler.SendCoins)(c)
@@ -80,7 +82,7 @@ func TestCoinHandler_SendCoins(t *testing.T) {
 	t.Run("Insufficient funds", func(t *testing.T) {
 		reqBody := model.SendCoinRequest{
 			ToUser: "user2",
-			Amount: 1000,
+			Amount: 400,
 		}
 		body, _ := json.Marshal(reqBody)
 
@@ -90,9 +92,9 @@ func TestCoinHandler_SendCoins(t *testing.T) {
 		c := e.NewContext(req, rec)
 
 		userRepo.On("GetUserByUsername", mock.Anything, "user2").
-			Return(&model.User{ID: "user2"}, nil).Once()
+			Return(&model.User{ID: "user2", Status: model.UserActive}, nil).Once()
 
-		txRepo.On("TransferCoins", mock.Anything, "user1", "user2", 1000).
+		txRepo.On("TransferCoins", mock.Anything, "user1", "user2", 400, model.TransferNote{}).
 			Return(model.ErrInsufficientFunds).Once()
 
 		err := middleware(coinHandler.SendCoins)(c)
@@ -149,7 +151,7 @@ func TestCoinHandler_SendCoins(t *testing.T) {
 
 		var errorResp model.ErrorResponse
 		json.Unmarshal(rec.Body.Bytes(), &errorResp)
-		assert.Equal(t, model.ErrUserNotFound.Error(), errorResp.Errors)
+		assert.Equal(t, model.ErrInternalError.Error(), errorResp.Errors)
 	})
 
 	t.Run("User not found error", func(t *testing.T) {
@@ -190,9 +192,9 @@ func TestCoinHandler_SendCoins(t *testing.T) {
 		c := e.NewContext(req, rec)
 
 		userRepo.On("GetUserByUsername", mock.Anything, "unknown_user").
-			Return(&model.User{ID: "unknown_user"}, nil).Once()
+			Return(&model.User{ID: "unknown_user", Status: model.UserActive}, nil).Once()
 
-		txRepo.On("TransferCoins", mock.Anything, "user1", "unknown_user", 100).
+		txRepo.On("TransferCoins", mock.Anything, "user1", "unknown_user", 100, model.TransferNote{}).
 			Return(model.ErrInternalError).Once()
 
 		err := middleware(coinHandler.SendCoins)(c)
@@ -233,4 +235,29 @@ func TestCoinHandler_SendCoins(t *testing.T) {
 		json.Unmarshal(rec.Body.Bytes(), &errorResp)
 		assert.Equal(t, model.ErrNegAmount.Error(), errorResp.Errors)
 	})
+
+	t.Run("Self transfer", func(t *testing.T) {
+		reqBody := model.SendCoinRequest{
+			ToUser: "me",
+			Amount: 100,
+		}
+		body, _ := json.Marshal(reqBody)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		userRepo.On("GetUserByUsername", mock.Anything, "me").
+			Return(&model.User{ID: "user1", Status: model.UserActive}, nil).Once()
+
+		err := middleware(coinHandler.SendCoins)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrSelfTransfer.Error(), errorResp.Errors)
+	})
 }
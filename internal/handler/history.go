@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+)
+
+// A structure for a coin history handler
+type HistoryHandler struct {
+	transactionRepo repository.TransactionRepositoryInt
+}
+
+// Constructor for coin history handler
+func NewHistoryHandler(tRepo repository.TransactionRepositoryInt) *HistoryHandler {
+	return &HistoryHandler{transactionRepo: tRepo}
+}
+
+// Function for /api/history request, returns page of transfers newest first, query parameters direction,
+// counterparty, reason, from, to, limit and cursor narrow it down
+func (h *HistoryHandler) GetHistory(c echo.Context) error {
+	filter, err := parseHistoryFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: err.Error()})
+	}
+
+	page, err := h.transactionRepo.GetHistory(c.Request().Context(), c.Get("user_id").(string), filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrHistory.Error()})
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+// Function for /api/history/summary request, returns coins moved with every counterparty, optional reason
+// query parameter counts only transfers of this category
+func (h *HistoryHandler) GetSummary(c echo.Context) error {
+	reason := c.QueryParam("reason")
+	if reason != "" && !model.ValidTransferReason(reason) {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidReason.Error()})
+	}
+
+	summary, err := h.transactionRepo.GetCounterpartySummary(c.Request().Context(), c.Get("user_id").(string), reason)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrHistory.Error()})
+	}
+	return c.JSON(http.StatusOK, summary)
+}
+
+// Function that reads history filter from query parameters, dates are in RFC 3339, returns filter and error
+func parseHistoryFilter(c echo.Context) (model.HistoryFilter, error) {
+	filter := model.HistoryFilter{
+		Direction:    c.QueryParam("direction"),
+		Counterparty: c.QueryParam("counterparty"),
+		Reason:       c.QueryParam("reason"),
+		Limit:        model.DefaultHistoryLimit,
+	}
+
+	switch filter.Direction {
+	case "", model.DirectionSent, model.DirectionReceived:
+	default:
+		return filter, model.ErrInvalidRequest
+	}
+
+	if filter.Reason != "" && !model.ValidTransferReason(filter.Reason) {
+		return filter, model.ErrInvalidReason
+	}
+
+	if l := c.QueryParam("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			return filter, model.ErrInvalidRequest
+		}
+		filter.Limit = min(n, model.MaxHistoryLimit)
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(c, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseTimeParam(c, "to"); err != nil {
+		return filter, err
+	}
+
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		if filter.Cursor, err = model.ParseHistoryCursor(cursor); err != nil {
+			return filter, err
+		}
+	}
+	return filter, nil
+}
+
+// Function that reads optional RFC 3339 time from query parameter name, returns time in UTC or nil and error
+func parseTimeParam(c echo.Context, name string) (*time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, model.ErrInvalidRequest
+	}
+	t = t.UTC()
+	return &t, nil
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestHistoryHandler_GetHistory(t *testing.T) {
+	e := echo.New()
+	txRepo := new(mocks.TransactionRepositoryMock)
+	historyHandler := NewHistoryHandler(txRepo)
+
+	newContext := func(query string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/api/history?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", "user1")
+		return c, rec
+	}
+
+	t.Run("First page with default limit", func(t *testing.T) {
+		txRepo.On("GetHistory", mock.Anything, "user1", model.HistoryFilter{Limit: model.DefaultHistoryLimit}).
+			Return(&model.HistoryPage{
+				Entries:    []model.HistoryEntry{{ID: "tx1", Direction: model.DirectionSent, Counterparty: "user2", Amount: 10}},
+				NextCursor: "next",
+			}, nil).Once()
+
+		c, rec := newContext("")
+		err := historyHandler.GetHistory(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var page model.HistoryPage
+		json.Unmarshal(rec.Body.Bytes(), &page)
+		assert.Len(t, page.Entries, 1)
+		assert.Equal(t, "next", page.NextCursor)
+	})
+
+	t.Run("All filters", func(t *testing.T) {
+		from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+		cursor := model.HistoryCursor{CreatedAt: time.Date(2025, 1, 20, 10, 30, 0, 123456000, time.UTC), ID: "6f1c2b7e-3a4d-4e5f-8a9b-0c1d2e3f4a5b"}
+
+		txRepo.On("GetHistory", mock.Anything, "user1", mock.MatchedBy(func(f model.HistoryFilter) bool {
+			return f.Direction == model.DirectionReceived && f.Counterparty == "bob" && f.Reason == model.ReasonBet &&
+				f.From.Equal(from) && f.To.Equal(to) && f.Limit == model.MaxHistoryLimit &&
+				f.Cursor.ID == cursor.ID && f.Cursor.CreatedAt.Equal(cursor.CreatedAt)
+		})).Return(&model.HistoryPage{Entries: []model.HistoryEntry{}}, nil).Once()
+
+		c, rec := newContext("direction=received&counterparty=bob&reason=bet&from=2025-01-01T03:00:00%2B03:00" +
+			"&to=2025-02-01T00:00:00Z&limit=1000&cursor=" + cursor.Encode())
+		err := historyHandler.GetHistory(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		txRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		c, rec := newContext("cursor=garbage")
+		err := historyHandler.GetHistory(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrInvalidCursor.Error(), errorResp.Errors)
+	})
+
+	t.Run("Cursor with malformed id", func(t *testing.T) {
+		cursor := model.HistoryCursor{CreatedAt: time.Date(2025, 1, 20, 10, 30, 0, 0, time.UTC), ID: "not-a-uuid"}
+		c, rec := newContext("cursor=" + cursor.Encode())
+		err := historyHandler.GetHistory(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrInvalidCursor.Error(), errorResp.Errors)
+	})
+
+	t.Run("Invalid direction", func(t *testing.T) {
+		c, rec := newContext("direction=sideways")
+		err := historyHandler.GetHistory(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Invalid date", func(t *testing.T) {
+		c, rec := newContext("from=yesterday")
+		err := historyHandler.GetHistory(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		c, rec := newContext("limit=0")
+		err := historyHandler.GetHistory(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		txRepo.On("GetHistory", mock.Anything, "user1", model.HistoryFilter{Limit: 5}).
+			Return((*model.HistoryPage)(nil), model.ErrInternalError).Once()
+
+		c, rec := newContext("limit=5")
+		err := historyHandler.GetHistory(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrHistory.Error(), errorResp.Errors)
+	})
+}
+
+func TestHistoryHandler_GetSummary(t *testing.T) {
+	e := echo.New()
+	txRepo := new(mocks.TransactionRepositoryMock)
+	historyHandler := NewHistoryHandler(txRepo)
+
+	newContext := func(query string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/api/history/summary?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", "user1")
+		return c, rec
+	}
+
+	t.Run("Summary per counterparty", func(t *testing.T) {
+		txRepo.On("GetCounterpartySummary", mock.Anything, "user1", model.ReasonGift).
+			Return([]model.CounterpartySummary{{Counterparty: "user2", Sent: 30, Received: 5, Count: 4}}, nil).Once()
+
+		c, rec := newContext("reason=gift")
+		err := historyHandler.GetSummary(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var summary []model.CounterpartySummary
+		json.Unmarshal(rec.Body.Bytes(), &summary)
+		assert.Equal(t, 30, summary[0].Sent)
+		assert.Equal(t, 5, summary[0].Received)
+	})
+
+	t.Run("Invalid reason", func(t *testing.T) {
+		c, rec := newContext("reason=bribe")
+		err := historyHandler.GetSummary(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		txRepo.On("GetCounterpartySummary", mock.Anything, "user1", "").
+			Return([]model.CounterpartySummary(nil), model.ErrInternalError).Once()
+
+		c, rec := newContext("")
+		err := historyHandler.GetSummary(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
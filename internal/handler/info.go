@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 
@@ -15,25 +16,56 @@ type InfoHandler struct {
 	userRepo        repository.UserRepositoryInt
 	inventoryRepo   repository.InventoryRepositoryInt
 	transactionRepo repository.TransactionRepositoryInt
+	orderRepo       repository.OrderRepositoryInt
+	historyLimit    int
+	ordersLimit     int
 }
 
-// Constructor for info handler
+// Constructor for info handler, coin history is cut to historyLimit most recent transfers and orders
+// to ordersLimit most recent orders
 func NewInfoHandler(
 	uRepo repository.UserRepositoryInt,
 	iRepo repository.InventoryRepositoryInt,
 	tRepo repository.TransactionRepositoryInt,
+	oRepo repository.OrderRepositoryInt,
+	historyLimit int,
+	ordersLimit int,
 ) *InfoHandler {
+	if historyLimit <= 0 {
+		historyLimit = model.DefaultHistoryLimit
+	}
+	if ordersLimit <= 0 {
+		ordersLimit = model.DefaultHistoryLimit
+	}
 	return &InfoHandler{
 		userRepo:        uRepo,
 		inventoryRepo:   iRepo,
 		transactionRepo: tRepo,
+		orderRepo:       oRepo,
+		historyLimit:    historyLimit,
+		ordersLimit:     ordersLimit,
 	}
 }
 
-// Function for /api/info request
+// Function for /api/info request, optional reason query parameter filters coin history by transfer category,
+// group=counterparty replaces coin history with summary per counterparty
 func (h *InfoHandler) GetUserInfo(c echo.Context) error {
 	userID := c.Get("user_id").(string)
 
+	reason := c.QueryParam("reason")
+	if reason != "" && !model.ValidTransferReason(reason) {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidReason.Error()})
+	}
+
+	var group bool
+	switch c.QueryParam("group") {
+	case "":
+	case "counterparty":
+		group = true
+	default:
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
 	user, err := h.userRepo.GetUserByID(c.Request().Context(), userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -47,14 +79,56 @@ func (h *InfoHandler) GetUserInfo(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInventory.Error()})
 	}
 
-	history, err := h.transactionRepo.GetTransactionHistory(c.Request().Context(), userID)
+	response := model.InfoResponse{Coins: user.Coins, Inventory: inventory}
+	if group {
+		var summary []model.CounterpartySummary
+		summary, err = h.transactionRepo.GetCounterpartySummary(c.Request().Context(), userID, reason)
+		// User without transfers gets empty summary rather than null
+		if summary == nil {
+			summary = make([]model.CounterpartySummary, 0)
+		}
+		response.CoinSummary = &summary
+	} else {
+		response.CoinHistory, err = h.recentHistory(c.Request().Context(), userID, reason)
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrHistory.Error()})
 	}
 
-	return c.JSON(http.StatusOK, model.InfoResponse{
-		Coins:       user.Coins,
-		Inventory:   inventory,
-		CoinHistory: *history,
-	})
+	orders, err := h.orderRepo.GetUserOrders(c.Request().Context(), userID, nil, h.ordersLimit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrOrders.Error()})
+	}
+
+	response.Orders = orders.Orders
+	response.OrdersNextCursor = orders.NextCursor
+	return c.JSON(http.StatusOK, response)
+}
+
+// Function that reads most recent transfers of user and splits them into received and sent as /api/info
+// returns them, returns history and error
+func (h *InfoHandler) recentHistory(ctx context.Context, userID, reason string) (*model.TransactionHistory, error) {
+	page, err := h.transactionRepo.GetHistory(ctx, userID, model.HistoryFilter{Reason: reason, Limit: h.historyLimit})
+	if err != nil {
+		return nil, err
+	}
+
+	history := &model.TransactionHistory{
+		Received:   make([]model.ReceivedTransaction, 0),
+		Sent:       make([]model.SentTransaction, 0),
+		NextCursor: page.NextCursor,
+	}
+
+	for _, e := range page.Entries {
+		if e.Direction == model.DirectionSent {
+			history.Sent = append(history.Sent, model.SentTransaction{
+				ToUser: e.Counterparty, Amount: e.Amount, Message: e.Message, Reason: e.Reason, Timestamp: e.Timestamp,
+			})
+		} else {
+			history.Received = append(history.Received, model.ReceivedTransaction{
+				FromUser: e.Counterparty, Amount: e.Amount, Message: e.Message, Reason: e.Reason, Timestamp: e.Timestamp,
+			})
+		}
+	}
+	return history, nil
 }
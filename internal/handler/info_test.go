@@ -22,7 +22,8 @@ func TestInfoHandler_GetUserInfo(t *testing.T) {
 	userRepo := new(mocks.UserRepositoryMock)
 	invRepo := new(mocks.InventoryRepositoryMock)
 	txRepo := new(mocks.TransactionRepositoryMock)
-	infoHandler := NewInfoHandler(userRepo, invRepo, txRepo)
+	orderRepo := new(mocks.OrderRepositoryMock)
+	infoHandler := NewInfoHandler(userRepo, invRepo, txRepo, orderRepo, 2, 3)
 
 	middleware := func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -42,13 +43,12 @@ func TestInfoHandler_GetUserInfo(t *testing.T) {
 			{Name: "cup", Quantity: 1},
 		}
 
-		mockHistory := &model.TransactionHistory{
-			Received: []model.ReceivedTransaction{
-				{FromUser: "user2", Amount: 200, Timestamp: time.Now()},
-			},
-			Sent: []model.SentTransaction{
-				{ToUser: "user3", Amount: 100, Timestamp: time.Now()},
+		mockHistory := &model.HistoryPage{
+			Entries: []model.HistoryEntry{
+				{ID: "tx2", Direction: model.DirectionReceived, Counterparty: "user2", Amount: 200, Timestamp: time.Now()},
+				{ID: "tx1", Direction: model.DirectionSent, Counterparty: "user3", Amount: 100, Timestamp: time.Now()},
 			},
+			NextCursor: "next",
 		}
 
 		userRepo.On("GetUserByID", mock.Anything, "user1").
@@ -57,9 +57,15 @@ func TestInfoHandler_GetUserInfo(t *testing.T) {
 		invRepo.On("GetUserInventory", mock.Anything, "user1").
 			Return(mockInventory, nil).Once()
 
-		txRepo.On("GetTransactionHistory", mock.Anything, "user1").
+		txRepo.On("GetHistory", mock.Anything, "user1", model.HistoryFilter{Limit: 2}).
 			Return(mockHistory, nil).Once()
 
+		orderRepo.On("GetUserOrders", mock.Anything, "user1", (*model.HistoryCursor)(nil), 3).
+			Return(&model.OrderPage{
+				Orders:     []model.Order{{ID: "order1", Item: "cup", UnitPrice: 20, Quantity: 1, Status: model.OrderCompleted}},
+				NextCursor: "orders-next",
+			}, nil).Once()
+
 		req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -74,6 +80,12 @@ func TestInfoHandler_GetUserInfo(t *testing.T) {
 		assert.Equal(t, 1500, response.Coins)
 		assert.Len(t, response.Inventory, 2)
 		assert.Len(t, response.CoinHistory.Received, 1)
+		assert.Equal(t, "user3", response.CoinHistory.Sent[0].ToUser)
+		assert.Equal(t, "next", response.CoinHistory.NextCursor)
+		assert.Len(t, response.Orders, 1)
+		assert.Equal(t, 20, response.Orders[0].UnitPrice)
+		assert.Equal(t, "orders-next", response.OrdersNextCursor)
+		assert.NotContains(t, rec.Body.String(), "coinSummary")
 		userRepo.AssertExpectations(t)
 		invRepo.AssertExpectations(t)
 		txRepo.AssertExpectations(t)
@@ -133,6 +145,116 @@ func TestInfoHandler_GetUserInfo(t *testing.T) {
 		assert.Equal(t, model.ErrInventory.Error(), errorResp.Errors)
 	})
 
+	t.Run("History filtered by reason", func(t *testing.T) {
+		userRepo.On("GetUserByID", mock.Anything, "user1").
+			Return(&model.User{ID: "user1"}, nil).Once()
+
+		invRepo.On("GetUserInventory", mock.Anything, "user1").
+			Return([]model.InventoryItem{}, nil).Once()
+
+		txRepo.On("GetHistory", mock.Anything, "user1", model.HistoryFilter{Reason: model.ReasonThanks, Limit: 2}).
+			Return(&model.HistoryPage{
+				Entries: []model.HistoryEntry{{
+					Direction: model.DirectionReceived, Counterparty: "user2", Amount: 5,
+					Message: "for the review", Reason: model.ReasonThanks,
+				}},
+			}, nil).Once()
+
+		orderRepo.On("GetUserOrders", mock.Anything, "user1", (*model.HistoryCursor)(nil), 3).
+			Return(&model.OrderPage{Orders: []model.Order{}}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/info?reason=thanks", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		err := middleware(infoHandler.GetUserInfo)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response model.InfoResponse
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.Equal(t, "for the review", response.CoinHistory.Received[0].Message)
+		txRepo.AssertExpectations(t)
+	})
+
+	t.Run("History grouped by counterparty", func(t *testing.T) {
+		userRepo.On("GetUserByID", mock.Anything, "user1").
+			Return(&model.User{ID: "user1"}, nil).Once()
+
+		invRepo.On("GetUserInventory", mock.Anything, "user1").
+			Return([]model.InventoryItem{}, nil).Once()
+
+		txRepo.On("GetCounterpartySummary", mock.Anything, "user1", "").
+			Return([]model.CounterpartySummary{{Counterparty: "user2", Sent: 10, Received: 20, Count: 3}}, nil).Once()
+
+		orderRepo.On("GetUserOrders", mock.Anything, "user1", (*model.HistoryCursor)(nil), 3).
+			Return(&model.OrderPage{Orders: []model.Order{}}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/info?group=counterparty", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		err := middleware(infoHandler.GetUserInfo)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response model.InfoResponse
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.Nil(t, response.CoinHistory)
+		assert.NotNil(t, response.CoinSummary)
+		assert.Equal(t, 20, (*response.CoinSummary)[0].Received)
+	})
+
+	t.Run("Grouping without transfers", func(t *testing.T) {
+		userRepo.On("GetUserByID", mock.Anything, "user1").
+			Return(&model.User{ID: "user1"}, nil).Once()
+
+		invRepo.On("GetUserInventory", mock.Anything, "user1").
+			Return([]model.InventoryItem{}, nil).Once()
+
+		txRepo.On("GetCounterpartySummary", mock.Anything, "user1", "").
+			Return([]model.CounterpartySummary(nil), nil).Once()
+
+		orderRepo.On("GetUserOrders", mock.Anything, "user1", (*model.HistoryCursor)(nil), 3).
+			Return(&model.OrderPage{Orders: []model.Order{}}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/info?group=counterparty", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		err := middleware(infoHandler.GetUserInfo)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response map[string]json.RawMessage
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.JSONEq(t, `[]`, string(response["coinSummary"]))
+	})
+
+	t.Run("Invalid grouping", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/info?group=item", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		err := middleware(infoHandler.GetUserInfo)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Invalid reason filter", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/info?reason=bribe", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		err := middleware(infoHandler.GetUserInfo)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrInvalidReason.Error(), errorResp.Errors)
+	})
+
 	t.Run("Error getting transaction history", func(t *testing.T) {
 		userRepo.On("GetUserByID", mock.Anything, "user1").
 			Return(&model.User{ID: "user1"}, nil).Once()
@@ -140,8 +262,8 @@ func TestInfoHandler_GetUserInfo(t *testing.T) {
 		invRepo.On("GetUserInventory", mock.Anything, "user1").
 			Return([]model.InventoryItem{}, nil).Once()
 
-		txRepo.On("GetTransactionHistory", mock.Anything, "user1").
-			Return(&model.TransactionHistory{}, model.ErrInternalError).Once()
+		txRepo.On("GetHistory", mock.Anything, "user1", model.HistoryFilter{Limit: 2}).
+			Return((*model.HistoryPage)(nil), model.ErrInternalError).Once()
 
 		req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
 		rec := httptest.NewRecorder()
@@ -156,6 +278,32 @@ func TestInfoHandler_GetUserInfo(t *testing.T) {
 		assert.Equal(t, model.ErrHistory.Error(), errorResp.Errors)
 	})
 
+	t.Run("Error getting orders", func(t *testing.T) {
+		userRepo.On("GetUserByID", mock.Anything, "user1").
+			Return(&model.User{ID: "user1"}, nil).Once()
+
+		invRepo.On("GetUserInventory", mock.Anything, "user1").
+			Return([]model.InventoryItem{}, nil).Once()
+
+		txRepo.On("GetHistory", mock.Anything, "user1", model.HistoryFilter{Limit: 2}).
+			Return(&model.HistoryPage{}, nil).Once()
+
+		orderRepo.On("GetUserOrders", mock.Anything, "user1", (*model.HistoryCursor)(nil), 3).
+			Return((*model.OrderPage)(nil), model.ErrInternalError).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		err := middleware(infoHandler.GetUserInfo)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrOrders.Error(), errorResp.Errors)
+	})
+
 	t.Run("Empty transaction history", func(t *testing.T) {
 		userRepo.On("GetUserByID", mock.Anything, "user1").
 			Return(&model.User{ID: "user1"}, nil).Once()
@@ -163,8 +311,11 @@ func TestInfoHandler_GetUserInfo(t *testing.T) {
 		invRepo.On("GetUserInventory", mock.Anything, "user1").
 			Return([]model.InventoryItem{}, nil).Once()
 
-		txRepo.On("GetTransactionHistory", mock.Anything, "user1").
-			Return(&model.TransactionHistory{}, nil).Once()
+		txRepo.On("GetHistory", mock.Anything, "user1", model.HistoryFilter{Limit: 2}).
+			Return(&model.HistoryPage{}, nil).Once()
+
+		orderRepo.On("GetUserOrders", mock.Anything, "user1", (*model.HistoryCursor)(nil), 3).
+			Return(&model.OrderPage{Orders: []model.Order{}}, nil).Once()
 
 		req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
 		rec := httptest.NewRecorder()
@@ -178,5 +329,7 @@ func TestInfoHandler_GetUserInfo(t *testing.T) {
 		json.Unmarshal(rec.Body.Bytes(), &response)
 		assert.Empty(t, response.Inventory)
 		assert.Empty(t, response.CoinHistory.Received)
+		assert.Empty(t, response.Orders)
+		assert.Empty(t, response.OrdersNextCursor)
 	})
 }
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/garaevmir/avitocoinstore/internal/keystore"
+)
+
+// A structure for a public keys handler
+type JWKSHandler struct {
+	keys *keystore.KeyStore
+}
+
+// Constructor for public keys handler
+func NewJWKSHandler(keys *keystore.KeyStore) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// Function for /.well-known/jwks.json request, lets other services verify tokens without shared secret
+func (h *JWKSHandler) GetKeys(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, h.keys.JWKS())
+}
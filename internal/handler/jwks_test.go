@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+)
+
+func TestJWKSHandler_GetKeys(t *testing.T) {
+	e := echo.New()
+	jwksHandler := NewJWKSHandler(testKeys)
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := jwksHandler.GetKeys(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response model.JWKS
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.Len(t, response.Keys, 1)
+	assert.Equal(t, "OKP", response.Keys[0].Kty)
+	assert.Equal(t, "sig", response.Keys[0].Use)
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+)
+
+// A structure for an order handler
+type OrderHandler struct {
+	orderRepo repository.OrderRepositoryInt
+}
+
+// Constructor for order handler
+func NewOrderHandler(oRepo repository.OrderRepositoryInt) *OrderHandler {
+	return &OrderHandler{orderRepo: oRepo}
+}
+
+// Function for /api/orders request, returns page of orders newest first, query parameters limit and cursor
+// select the page
+func (h *OrderHandler) ListOrders(c echo.Context) error {
+	limit := model.DefaultHistoryLimit
+	if l := c.QueryParam("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+		}
+		limit = min(n, model.MaxHistoryLimit)
+	}
+
+	var cursor *model.HistoryCursor
+	if s := c.QueryParam("cursor"); s != "" {
+		var err error
+		if cursor, err = model.ParseHistoryCursor(s); err != nil {
+			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: err.Error()})
+		}
+	}
+
+	page, err := h.orderRepo.GetUserOrders(c.Request().Context(), c.Get("user_id").(string), cursor, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrOrders.Error()})
+	}
+	return c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestOrderHandler_ListOrders(t *testing.T) {
+	e := echo.New()
+	orderRepo := new(mocks.OrderRepositoryMock)
+	orderHandler := NewOrderHandler(orderRepo)
+
+	newContext := func(query string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/api/orders"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", "user1")
+		return c, rec
+	}
+
+	t.Run("Successful retrieval", func(t *testing.T) {
+		orderRepo.On("GetUserOrders", mock.Anything, "user1", (*model.HistoryCursor)(nil), model.DefaultHistoryLimit).
+			Return(&model.OrderPage{Orders: []model.Order{
+				{ID: "order2", Item: "hoody", UnitPrice: 350, Quantity: 1, Status: model.OrderCompleted, CreatedAt: time.Now()},
+				{ID: "order1", Item: "hoody", UnitPrice: 300, Quantity: 1, Status: model.OrderCompleted, CreatedAt: time.Now()},
+			}}, nil).Once()
+
+		c, rec := newContext("")
+		err := orderHandler.ListOrders(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response model.OrderPage
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.Len(t, response.Orders, 2)
+		assert.Equal(t, 350, response.Orders[0].UnitPrice)
+		assert.Equal(t, 300, response.Orders[1].UnitPrice)
+		assert.Empty(t, response.NextCursor)
+	})
+
+	t.Run("Page with limit and cursor", func(t *testing.T) {
+		cursor := model.HistoryCursor{CreatedAt: time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC), ID: "3b9e1f0a-7c2d-4b8e-9f6a-5d4c3b2a1e0f"}
+		orderRepo.On("GetUserOrders", mock.Anything, "user1", &cursor, 1).
+			Return(&model.OrderPage{
+				Orders:     []model.Order{{ID: "order2", Item: "hoody", UnitPrice: 350, Quantity: 1, Status: model.OrderCompleted}},
+				NextCursor: "next",
+			}, nil).Once()
+
+		c, rec := newContext("?limit=1&cursor=" + cursor.Encode())
+		err := orderHandler.ListOrders(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response model.OrderPage
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.Len(t, response.Orders, 1)
+		assert.Equal(t, "next", response.NextCursor)
+	})
+
+	t.Run("Limit is capped", func(t *testing.T) {
+		orderRepo.On("GetUserOrders", mock.Anything, "user1", (*model.HistoryCursor)(nil), model.MaxHistoryLimit).
+			Return(&model.OrderPage{Orders: []model.Order{}}, nil).Once()
+
+		c, rec := newContext("?limit=1000")
+		err := orderHandler.ListOrders(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		c, rec := newContext("?limit=0")
+		err := orderHandler.ListOrders(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		c, rec := newContext("?cursor=bad")
+		err := orderHandler.ListOrders(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrInvalidCursor.Error(), errorResp.Errors)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		orderRepo.On("GetUserOrders", mock.Anything, "user1", (*model.HistoryCursor)(nil), model.DefaultHistoryLimit).
+			Return((*model.OrderPage)(nil), model.ErrInternalError).Once()
+
+		c, rec := newContext("")
+		err := orderHandler.ListOrders(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	orderRepo.AssertExpectations(t)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/service"
+)
+
+// A structure for a password handler
+type PasswordHandler struct {
+	passwordService *service.PasswordService
+}
+
+// Constructor for password handler
+func NewPasswordHandler(s *service.PasswordService) *PasswordHandler {
+	return &PasswordHandler{passwordService: s}
+}
+
+// Function for /api/password request, responds with new tokens as every other token of user is revoked
+func (h *PasswordHandler) ChangePassword(c echo.Context) error {
+	var req model.ChangePasswordRequest
+	if err := c.Bind(&req); err != nil || req.OldPassword == "" || req.NewPassword == "" {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	userID := c.Get("user_id").(string)
+
+	tokens, err := h.passwordService.ChangePassword(c.Request().Context(), userID, req.OldPassword, req.NewPassword)
+	if err != nil {
+		return passwordError(c, err)
+	}
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// Function for /api/password/reset request
+func (h *PasswordHandler) ResetPassword(c echo.Context) error {
+	var req model.ResetPasswordRequest
+	if err := c.Bind(&req); err != nil || req.Token == "" || req.NewPassword == "" {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	if err := h.passwordService.ResetPassword(c.Request().Context(), req.Token, req.NewPassword); err != nil {
+		return passwordError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"status": "success"})
+}
+
+// Responds with status matching password change or reset error
+func passwordError(c echo.Context, err error) error {
+	switch err {
+	case model.ErrInvalidCredentials:
+		return c.JSON(http.StatusForbidden, model.ErrorResponse{Errors: err.Error()})
+	case model.ErrPasswordTooShort, model.ErrPasswordIsUsername, model.ErrInvalidResetToken:
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/service"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestPasswordHandler_ChangePassword(t *testing.T) {
+	e := echo.New()
+	userRepo := new(mocks.UserRepositoryMock)
+	refreshRepo := new(mocks.RefreshTokenRepositoryMock)
+	revocationRepo := new(mocks.RevocationRepositoryMock)
+	revocationService := service.NewRevocationService(revocationRepo, refreshRepo, time.Minute)
+	tokenService := service.NewTokenService(userRepo, refreshRepo, testKeys, time.Minute, time.Hour)
+	passwordService := service.NewPasswordService(userRepo, new(mocks.PasswordResetRepositoryMock),
+		revocationService, tokenService, service.PasswordPolicy{MinLength: 8}, time.Hour)
+	passwordHandler := NewPasswordHandler(passwordService)
+
+	hashedPass, _ := bcrypt.GenerateFromPassword([]byte("old_password"), bcrypt.DefaultCost)
+	user := &model.User{ID: "user1", Username: "alice", PasswordHash: string(hashedPass)}
+
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/api/password", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", "user1")
+		return c, rec
+	}
+
+	t.Run("Successful change", func(t *testing.T) {
+		userRepo.On("GetUserByID", mock.Anything, "user1").Return(user, nil).Once()
+		userRepo.On("UpdatePassword", mock.Anything, "user1", mock.Anything).Return(nil).Once()
+		revocationRepo.On("RevokeUserTokens", mock.Anything, "user1", mock.Anything).Return(nil).Once()
+		refreshRepo.On("RevokeUserTokens", mock.Anything, "user1", mock.Anything).Return(nil).Once()
+		refreshRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(nil).Once()
+
+		c, rec := newContext(`{"oldPassword": "old_password", "newPassword": "new_password"}`)
+		err := passwordHandler.ChangePassword(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("Wrong old password", func(t *testing.T) {
+		userRepo.On("GetUserByID", mock.Anything, "user1").Return(user, nil).Once()
+
+		c, rec := newContext(`{"oldPassword": "wrong_password", "newPassword": "new_password"}`)
+		err := passwordHandler.ChangePassword(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Weak new password", func(t *testing.T) {
+		userRepo.On("GetUserByID", mock.Anything, "user1").Return(user, nil).Once()
+
+		c, rec := newContext(`{"oldPassword": "old_password", "newPassword": "alice"}`)
+		err := passwordHandler.ChangePassword(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Missing fields", func(t *testing.T) {
+		c, rec := newContext(`{"newPassword": "new_password"}`)
+		err := passwordHandler.ChangePassword(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestPasswordHandler_ResetPassword(t *testing.T) {
+	e := echo.New()
+	userRepo := new(mocks.UserRepositoryMock)
+	resetRepo := new(mocks.PasswordResetRepositoryMock)
+	passwordService := service.NewPasswordService(userRepo, resetRepo, nil, nil, service.PasswordPolicy{MinLength: 8}, time.Hour)
+	passwordHandler := NewPasswordHandler(passwordService)
+
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/api/password/reset", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("Invalid token", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		userRepo.On("BeginTx", mock.Anything).Return(txMock, nil).Once()
+		resetRepo.On("ConsumeResetTx", mock.Anything, txMock, mock.Anything).Return(nil, nil).Once()
+		txMock.On("Rollback", mock.Anything).Return(nil).Once()
+
+		c, rec := newContext(`{"token": "used", "newPassword": "new_password"}`)
+		err := passwordHandler.ResetPassword(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		userRepo.On("BeginTx", mock.Anything).Return(new(mocks.TxMock), model.ErrInternalError).Once()
+
+		c, rec := newContext(`{"token": "token", "newPassword": "new_password"}`)
+		err := passwordHandler.ResetPassword(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		c, rec := newContext(`{"newPassword": "new_password"}`)
+		err := passwordHandler.ResetPassword(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 
@@ -19,20 +20,53 @@ func NewShopHandler(s *service.ShopService) *ShopHandler {
 	return &ShopHandler{shopService: s}
 }
 
-// Function for /api/buy request
+// Function for /api/buy request, optional quantity query parameter sets number of units
 func (h *ShopHandler) BuyItem(c echo.Context) error {
-	itemName := c.Param("item")
-	userID := c.Get("user_id").(string)
-
-	if err := h.shopService.BuyItem(c.Request().Context(), userID, itemName); err != nil {
-		switch err {
-		case model.ErrItemNotFound:
-			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrItemNotFound.Error()})
-		case model.ErrInsufficientFunds:
-			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInsufficientFunds.Error()})
-		default:
-			return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	quantity := 1
+	if q := c.QueryParam("quantity"); q != "" {
+		n, err := strconv.Atoi(q)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
 		}
+		quantity = n
+	}
+
+	lines := []model.OrderLine{{Item: c.Param("item"), Quantity: quantity}}
+	if _, err := h.shopService.BuyItems(c.Request().Context(), c.Get("user_id").(string), lines); err != nil {
+		return buyError(c, err)
 	}
 	return c.JSON(http.StatusOK, map[string]interface{}{"status": "success"})
 }
+
+// Function for /api/orders request, buys every line of request in one transaction
+func (h *ShopHandler) PlaceOrder(c echo.Context) error {
+	var req model.PlaceOrderRequest
+	if err := c.Bind(&req); err != nil || len(req.Items) == 0 {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	orders, err := h.shopService.BuyItems(c.Request().Context(), c.Get("user_id").(string), req.Items)
+	if err != nil {
+		return buyError(c, err)
+	}
+
+	total := 0
+	for _, order := range orders {
+		total += order.UnitPrice * order.Quantity
+	}
+	return c.JSON(http.StatusCreated, model.PlaceOrderResponse{Orders: orders, Total: total})
+}
+
+// Responds with status matching purchase error
+func buyError(c echo.Context, err error) error {
+	switch err {
+	case model.ErrItemNotFound:
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrItemNotFound.Error()})
+	case model.ErrInsufficientFunds:
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInsufficientFunds.Error()})
+	case model.ErrInvalidRequest:
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -21,14 +22,22 @@ func TestShopHandler_BuyItem(t *testing.T) {
 	userRepo := new(mocks.UserRepositoryMock)
 	txRepo := new(mocks.TransactionRepositoryMock)
 	invRepo := new(mocks.InventoryRepositoryMock)
+	catRepo := new(mocks.CatalogRepositoryMock)
+	ledgerRepo := new(mocks.LedgerRepositoryMock)
+	orderRepo := new(mocks.OrderRepositoryMock)
 	txMock := new(mocks.TxMock)
-	shopService := service.NewShopService(userRepo, txRepo, invRepo)
+	shopService := service.NewShopService(userRepo, txRepo, invRepo, catRepo, ledgerRepo, orderRepo)
 	shopHandler := NewShopHandler(shopService)
 
 	txMock.On("Commit", mock.Anything).Return(nil)
 	txMock.On("Rollback", mock.Anything).Return(nil)
 	txMock.On("Begin", mock.Anything).Return(txMock, nil)
 
+	catRepo.On("GetItemForShareTx", mock.Anything, mock.Anything, "hoody").
+		Return(&model.Item{Name: "hoody", Price: 300, Active: true}, nil)
+	catRepo.On("GetItemForShareTx", mock.Anything, mock.Anything, "unknown_item").
+		Return(nil, nil)
+
 	middleware := func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			c.Set("user_id", "user1")
@@ -37,13 +46,14 @@ func TestShopHandler_BuyItem(t *testing.T) {
 	}
 
 	t.Run("Successful item purchase", func(t *testing.T) {
-		userRepo.On("GetUserByID", mock.Anything, "user1").
-			Return(&model.User{ID: "user1", Coins: 500}, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, mock.Anything, "user1").Return(500, nil).Once()
 
 		invRepo.On("AddToInventoryTx", mock.Anything, mock.Anything, "user1", "hoody", 1).
 			Return(nil).Once()
 
-		userRepo.On("UpdateUserCoinsTx", mock.Anything, mock.Anything, "user1", -300).
+		ledgerRepo.On("PostTx", mock.Anything, mock.Anything, model.PurchasePosting("user1", 300, "hoody")).
+			Return(nil).Once()
+		orderRepo.On("CreateOrderTx", mock.Anything, mock.Anything, mock.AnythingOfType("*model.Order")).
 			Return(nil).Once()
 		userRepo.On("BeginTx", mock.Anything).
 			Return(txMock, nil)
@@ -64,8 +74,7 @@ func TestShopHandler_BuyItem(t *testing.T) {
 	})
 
 	t.Run("Insufficient funds error", func(t *testing.T) {
-		userRepo.On("GetUserByID", mock.Anything, "user1").
-			Return(&model.User{ID: "user1", Coins: 100}, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, mock.Anything, "user1").Return(100, nil).Once()
 
 		req := httptest.NewRequest(http.MethodGet, "/api/buy/hoody", nil)
 		rec := httptest.NewRecorder()
@@ -85,6 +94,8 @@ func TestShopHandler_BuyItem(t *testing.T) {
 	})
 
 	t.Run("Item not found error", func(t *testing.T) {
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, mock.Anything, "user1").Return(500, nil).Once()
+
 		req := httptest.NewRequest(http.MethodGet, "/api/buy/unknown_item", nil)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -103,10 +114,9 @@ func TestShopHandler_BuyItem(t *testing.T) {
 	})
 
 	t.Run("Database error during balance update", func(t *testing.T) {
-		userRepo.On("GetUserByID", mock.Anything, "user1").
-			Return(&model.User{ID: "user1", Coins: 500}, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, mock.Anything, "user1").Return(500, nil).Once()
 
-		userRepo.On("UpdateUserCoinsTx", mock.Anything, mock.Anything, "user1", -300).
+		ledgerRepo.On("PostTx", mock.Anything, mock.Anything, model.PurchasePosting("user1", 300, "hoody")).
 			Return(errors.New("database error")).Once()
 
 		userRepo.On("BeginTx", mock.Anything).
@@ -124,4 +134,125 @@ func TestShopHandler_BuyItem(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	})
+	t.Run("Several units", func(t *testing.T) {
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, mock.Anything, "user1").Return(1000, nil).Once()
+		ledgerRepo.On("PostTx", mock.Anything, mock.Anything, model.PurchasePosting("user1", 900, "hoody")).
+			Return(nil).Once()
+		invRepo.On("AddToInventoryTx", mock.Anything, mock.Anything, "user1", "hoody", 3).
+			Return(nil).Once()
+		orderRepo.On("CreateOrderTx", mock.Anything, mock.Anything, mock.AnythingOfType("*model.Order")).
+			Return(nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/buy/hoody?quantity=3", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/buy/:item")
+		c.SetParamNames("item")
+		c.SetParamValues("hoody")
+
+		err := middleware(shopHandler.BuyItem)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		invRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid quantity", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/buy/hoody?quantity=-1", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/buy/:item")
+		c.SetParamNames("item")
+		c.SetParamValues("hoody")
+
+		err := middleware(shopHandler.BuyItem)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestShopHandler_PlaceOrder(t *testing.T) {
+	e := echo.New()
+	userRepo := new(mocks.UserRepositoryMock)
+	invRepo := new(mocks.InventoryRepositoryMock)
+	catRepo := new(mocks.CatalogRepositoryMock)
+	ledgerRepo := new(mocks.LedgerRepositoryMock)
+	orderRepo := new(mocks.OrderRepositoryMock)
+	shopService := service.NewShopService(userRepo, new(mocks.TransactionRepositoryMock), invRepo, catRepo, ledgerRepo, orderRepo)
+	shopHandler := NewShopHandler(shopService)
+
+	catRepo.On("GetItemForShareTx", mock.Anything, mock.Anything, "hoody").
+		Return(&model.Item{Name: "hoody", Price: 300, Active: true}, nil)
+	catRepo.On("GetItemForShareTx", mock.Anything, mock.Anything, "pen").
+		Return(&model.Item{Name: "pen", Price: 10, Active: true}, nil)
+
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/api/orders", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", "user1")
+		return c, rec
+	}
+
+	t.Run("Successful order", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		txMock.On("Rollback", mock.Anything).Return(nil).Once()
+		txMock.On("Commit", mock.Anything).Return(nil).Once()
+
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, mock.Anything, "user1").Return(1000, nil).Once()
+		userRepo.On("BeginTx", mock.Anything).Return(txMock, nil).Once()
+		ledgerRepo.On("PostTx", mock.Anything, txMock, mock.Anything).Return(nil).Twice()
+		invRepo.On("AddToInventoryTx", mock.Anything, txMock, "user1", mock.Anything, mock.Anything).
+			Return(nil).Twice()
+		orderRepo.On("CreateOrderTx", mock.Anything, txMock, mock.AnythingOfType("*model.Order")).
+			Return(nil).Twice()
+
+		c, rec := newContext(`{"items": [{"item": "hoody", "quantity": 2}, {"item": "pen", "quantity": 3}]}`)
+		err := shopHandler.PlaceOrder(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response model.PlaceOrderResponse
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.Len(t, response.Orders, 2)
+		assert.Equal(t, 630, response.Total)
+		assert.Equal(t, 3, response.Orders[1].Quantity)
+	})
+
+	t.Run("Total exceeds balance", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		txMock.On("Rollback", mock.Anything).Return(nil).Once()
+
+		userRepo.On("BeginTx", mock.Anything).Return(txMock, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, txMock, "user1").Return(500, nil).Once()
+
+		c, rec := newContext(`{"items": [{"item": "hoody", "quantity": 2}]}`)
+		err := shopHandler.PlaceOrder(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var errorResp model.ErrorResponse
+		json.Unmarshal(rec.Body.Bytes(), &errorResp)
+		assert.Equal(t, model.ErrInsufficientFunds.Error(), errorResp.Errors)
+	})
+
+	t.Run("Empty order", func(t *testing.T) {
+		c, rec := newContext(`{"items": []}`)
+		err := shopHandler.PlaceOrder(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Invalid quantity", func(t *testing.T) {
+		c, rec := newContext(`{"items": [{"item": "hoody", "quantity": 0}]}`)
+		err := shopHandler.PlaceOrder(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
 }
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+	"github.com/garaevmir/avitocoinstore/internal/service"
+)
+
+// A structure for a wallet statement handler
+type StatementHandler struct {
+	ledgerRepo repository.LedgerRepositoryInt
+	userRepo   repository.UserRepositoryInt
+}
+
+// Constructor for wallet statement handler
+func NewStatementHandler(lRepo repository.LedgerRepositoryInt, uRepo repository.UserRepositoryInt) *StatementHandler {
+	return &StatementHandler{ledgerRepo: lRepo, userRepo: uRepo}
+}
+
+// Interface for statement writers of both formats
+type statementWriter interface {
+	model.StatementWriter
+	Close() error
+}
+
+// Function for /api/statement request, streams opening balance, every movement oldest first and closing balance
+// for period given by from and to as CSV or JSON depending on format
+func (h *StatementHandler) GetStatement(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = model.StatementCSV
+	}
+	if format != model.StatementCSV && format != model.StatementJSON {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	from, err := parseTimeParam(c, "from")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: err.Error()})
+	}
+	to, err := parseTimeParam(c, "to")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: err.Error()})
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return c.JSON(http.StatusBadRequest, model.ErrorResponse{Errors: model.ErrInvalidRequest.Error()})
+	}
+
+	ctx := c.Request().Context()
+	userID := c.Get("user_id").(string)
+
+	user, err := h.userRepo.GetUserByID(ctx, userID)
+	if err != nil || user == nil {
+		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+	}
+
+	header := model.StatementHeader{Username: user.Username, From: from, To: to}
+	var w statementWriter
+	if format == model.StatementJSON {
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		w = service.NewStatementJSONWriter(c.Response(), header)
+	} else {
+		c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+		w = service.NewStatementCSVWriter(c.Response(), header)
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="statement.`+format+`"`)
+
+	// Status is sent with the first written byte, so failure before it still gets proper error response
+	if err := h.ledgerRepo.StreamStatement(ctx, userID, from, to, w); err != nil {
+		if !c.Response().Committed {
+			c.Response().Header().Del(echo.HeaderContentDisposition)
+			return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrStatement.Error()})
+		}
+		log.Printf("Statement interrupted: %v", err)
+		return nil
+	}
+	return w.Close()
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestStatementHandler_GetStatement(t *testing.T) {
+	e := echo.New()
+	ledgerRepo := new(mocks.LedgerRepositoryMock)
+	userRepo := new(mocks.UserRepositoryMock)
+	statementHandler := NewStatementHandler(ledgerRepo, userRepo)
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	userRepo.On("GetUserByID", mock.Anything, "user1").Return(&model.User{ID: "user1", Username: "alice"}, nil)
+
+	newContext := func(query string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/api/statement?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", "user1")
+		return c, rec
+	}
+
+	// Makes repository pass opening balance and one transfer to statement writer
+	streamStatement := func(from, to *time.Time) {
+		ledgerRepo.On("StreamStatement", mock.Anything, "user1", from, to, mock.Anything).
+			Run(func(args mock.Arguments) {
+				w := args.Get(4).(model.StatementWriter)
+				w.Opening(1000)
+				w.Movement(model.ActivityEvent{
+					ID: 5, Kind: model.PostingTransfer, Amount: -10, Balance: 990, Counterparty: "bob",
+					Timestamp: from.Add(time.Hour),
+				})
+			}).Return(nil).Once()
+	}
+
+	t.Run("CSV statement", func(t *testing.T) {
+		streamStatement(&from, &to)
+
+		c, rec := newContext("from=2025-03-01T00:00:00Z&to=2025-04-01T00:00:00Z")
+		err := statementHandler.GetStatement(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, `attachment; filename="statement.csv"`, rec.Header().Get(echo.HeaderContentDisposition))
+
+		lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+		assert.Equal(t, []string{
+			"timestamp,entry_id,kind,amount,balance,counterparty,item,reason",
+			"2025-03-01T00:00:00Z,,opening,,1000,,,",
+			"2025-03-01T01:00:00Z,5,transfer,-10,990,bob,,",
+			"2025-04-01T00:00:00Z,,closing,,990,,,",
+		}, lines)
+	})
+
+	t.Run("JSON statement", func(t *testing.T) {
+		streamStatement(&from, (*time.Time)(nil))
+
+		c, rec := newContext("format=json&from=2025-03-01T00:00:00Z")
+		err := statementHandler.GetStatement(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var statement struct {
+			model.StatementHeader
+			Movements      []model.ActivityEvent `json:"movements"`
+			ClosingBalance int                   `json:"closingBalance"`
+		}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &statement))
+		assert.Equal(t, "alice", statement.Username)
+		assert.Equal(t, 1000, statement.OpeningBalance)
+		assert.Len(t, statement.Movements, 1)
+		assert.Equal(t, 990, statement.ClosingBalance)
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		c, rec := newContext("format=xml")
+		err := statementHandler.GetStatement(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Empty period", func(t *testing.T) {
+		c, rec := newContext("from=2025-04-01T00:00:00Z&to=2025-03-01T00:00:00Z")
+		err := statementHandler.GetStatement(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Database error before output", func(t *testing.T) {
+		ledgerRepo.On("StreamStatement", mock.Anything, "user1", (*time.Time)(nil), (*time.Time)(nil), mock.Anything).
+			Return(model.ErrInternalError).Once()
+
+		c, rec := newContext("")
+		err := statementHandler.GetStatement(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Empty(t, rec.Header().Get(echo.HeaderContentDisposition))
+		assert.Contains(t, rec.Body.String(), model.ErrStatement.Error())
+	})
+}
@@ -0,0 +1,295 @@
+package keystore
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+)
+
+// Signing algorithms accepted by the service, anything else is rejected before key lookup
+var Algorithms = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+
+var (
+	ErrNoSigningKey = errors.New("no signing key")
+	ErrUnknownKey   = errors.New("unknown key id")
+	ErrAlgMismatch  = errors.New("algorithm does not match key")
+)
+
+// A key identified by kid, private is nil for keys kept only to verify tokens signed before rotation
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Public  crypto.PublicKey
+	Private crypto.Signer
+}
+
+// Structure that holds keys used to sign and verify tokens
+type KeyStore struct {
+	dir string
+
+	mu      sync.RWMutex
+	keys    map[string]*Key
+	signing *Key
+}
+
+// Constructor for key store over directory dir, every <kid>.pem file holds private key and every
+// <kid>.pub.pem file holds public key of a retired key, new tokens are signed by the private key
+// with the greatest kid, so naming keys by date makes rotation a matter of adding a file
+func New(dir string) *KeyStore {
+	return &KeyStore{dir: dir, keys: make(map[string]*Key)}
+}
+
+// Constructor for key store over given keys, used when keys are not stored in files
+func NewFromKeys(keys ...*Key) *KeyStore {
+	s := &KeyStore{keys: make(map[string]*Key)}
+	s.set(keys)
+	return s
+}
+
+// Function that reloads keys from directory, when directory is not configured or holds no keys
+// ephemeral Ed25519 key is generated so tokens do not survive restart, returns error
+func (s *KeyStore) Load() error {
+	var keys []*Key
+	if s.dir != "" {
+		var err error
+		keys, err = readDir(s.dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(keys) == 0 {
+		s.mu.RLock()
+		loaded := s.signing != nil
+		s.mu.RUnlock()
+		if loaded {
+			return nil
+		}
+
+		log.Printf("No JWT keys found in %q, generating ephemeral key", s.dir)
+		key, err := GenerateKey()
+		if err != nil {
+			return err
+		}
+		keys = []*Key{key}
+	}
+
+	s.set(keys)
+	return nil
+}
+
+// Function that reloads keys every interval until ctx is done
+func (s *KeyStore) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Load(); err != nil {
+				log.Printf("JWT keys reload error: %v", err)
+			}
+		}
+	}
+}
+
+// Function that signs claims with current signing key, kid of the key is put to the token header
+func (s *KeyStore) Sign(claims jwt.Claims) (string, error) {
+	s.mu.RLock()
+	key := s.signing
+	s.mu.RUnlock()
+
+	if key == nil {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// Function that finds verification key by kid of the token, key must be used with its own algorithm
+func (s *KeyStore) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrAlgMismatch
+	}
+	return key.Public, nil
+}
+
+// Function that returns public part of every known key in JWK Set format
+func (s *KeyStore) JWKS() model.JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set := model.JWKS{Keys: make([]model.JWK, 0, len(s.keys))}
+	for _, kid := range sortedIDs(s.keys) {
+		key := s.keys[kid]
+		jwk := model.JWK{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// Replaces known keys and picks signing key
+func (s *KeyStore) set(keys []*Key) {
+	byID := make(map[string]*Key, len(keys))
+	for _, key := range keys {
+		if existing, ok := byID[key.ID]; ok && existing.Private != nil {
+			continue
+		}
+		byID[key.ID] = key
+	}
+
+	var signing *Key
+	for _, kid := range sortedIDs(byID) {
+		if byID[kid].Private != nil {
+			signing = byID[kid]
+		}
+	}
+
+	s.mu.Lock()
+	s.keys = byID
+	s.signing = signing
+	s.mu.Unlock()
+}
+
+// Reads every .pem file of directory
+func readDir(dir string) ([]*Key, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var keys []*Key
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".pem") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		kid := strings.TrimSuffix(strings.TrimSuffix(name, ".pem"), ".pub")
+		key, err := ParsePEM(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Function that parses PEM encoded RSA or Ed25519 key, private keys in PKCS#8 or PKCS#1
+// and public keys in PKIX form are accepted, returns key and error
+func ParsePEM(kid string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &Key{ID: kid, Method: jwt.SigningMethodRS256, Public: &k.PublicKey, Private: k}, nil
+	case ed25519.PrivateKey:
+		return &Key{ID: kid, Method: jwt.SigningMethodEdDSA, Public: k.Public(), Private: k}, nil
+	case *rsa.PublicKey:
+		return &Key{ID: kid, Method: jwt.SigningMethodRS256, Public: k}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: kid, Method: jwt.SigningMethodEdDSA, Public: k}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+}
+
+// Function that generates Ed25519 key with random kid, returns key and error
+func GenerateKey() (*Key, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+
+	return &Key{
+		ID:      "ephemeral-" + hex.EncodeToString(suffix),
+		Method:  jwt.SigningMethodEdDSA,
+		Public:  pub,
+		Private: priv,
+	}, nil
+}
+
+// Returns kids in ascending order
+func sortedIDs(keys map[string]*Key) []string {
+	ids := make([]string, 0, len(keys))
+	for kid := range keys {
+		ids = append(ids, kid)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,163 @@
+package keystore
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePrivateKey(t *testing.T, dir, name string, key interface{}) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o600))
+}
+
+func writePublicKey(t *testing.T, dir, name string, key interface{}) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o600))
+}
+
+func claims() jwt.MapClaims {
+	return jwt.MapClaims{"user_id": "user1", "exp": time.Now().Add(time.Minute).Unix()}
+}
+
+func TestKeyStore_Load(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("Newest private key signs", func(t *testing.T) {
+		dir := t.TempDir()
+		writePrivateKey(t, dir, "2025-01.pem", rsaKey)
+		writePrivateKey(t, dir, "2025-02.pem", edKey)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("ignored"), 0o600))
+
+		keys := New(dir)
+		require.NoError(t, keys.Load())
+
+		tokenString, err := keys.Sign(claims())
+		require.NoError(t, err)
+
+		token, err := jwt.Parse(tokenString, keys.Keyfunc, jwt.WithValidMethods(Algorithms))
+		require.NoError(t, err)
+		assert.Equal(t, "2025-02", token.Header["kid"])
+		assert.Equal(t, jwt.SigningMethodEdDSA.Alg(), token.Method.Alg())
+	})
+
+	t.Run("Rotation keeps old tokens valid", func(t *testing.T) {
+		dir := t.TempDir()
+		writePrivateKey(t, dir, "2025-01.pem", rsaKey)
+
+		keys := New(dir)
+		require.NoError(t, keys.Load())
+		oldToken, err := keys.Sign(claims())
+		require.NoError(t, err)
+
+		require.NoError(t, os.Remove(filepath.Join(dir, "2025-01.pem")))
+		writePublicKey(t, dir, "2025-01.pub.pem", &rsaKey.PublicKey)
+		writePrivateKey(t, dir, "2025-02.pem", edKey)
+		require.NoError(t, keys.Load())
+
+		token, err := jwt.Parse(oldToken, keys.Keyfunc, jwt.WithValidMethods(Algorithms))
+		require.NoError(t, err)
+		assert.Equal(t, "2025-01", token.Header["kid"])
+
+		newToken, err := keys.Sign(claims())
+		require.NoError(t, err)
+		token, err = jwt.Parse(newToken, keys.Keyfunc, jwt.WithValidMethods(Algorithms))
+		require.NoError(t, err)
+		assert.Equal(t, "2025-02", token.Header["kid"])
+	})
+
+	t.Run("Empty directory generates ephemeral key", func(t *testing.T) {
+		keys := New(t.TempDir())
+		require.NoError(t, keys.Load())
+
+		_, err := keys.Sign(claims())
+		assert.NoError(t, err)
+		assert.Len(t, keys.JWKS().Keys, 1)
+	})
+
+	t.Run("Malformed key file", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.pem"), []byte("garbage"), 0o600))
+
+		assert.Error(t, New(dir).Load())
+	})
+}
+
+func TestKeyStore_Keyfunc(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keys := NewFromKeys(&Key{ID: "rsa", Method: jwt.SigningMethodRS256, Public: &rsaKey.PublicKey, Private: rsaKey})
+
+	t.Run("Unknown kid", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims())
+		token.Header["kid"] = "other"
+		signed, err := token.SignedString(rsaKey)
+		require.NoError(t, err)
+
+		_, err = jwt.Parse(signed, keys.Keyfunc, jwt.WithValidMethods(Algorithms))
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("Algorithm does not match key", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+		token.Header["kid"] = "rsa"
+		signed, err := token.SignedString(x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey))
+		require.NoError(t, err)
+
+		_, err = jwt.Parse(signed, keys.Keyfunc)
+		assert.ErrorIs(t, err, ErrAlgMismatch)
+	})
+}
+
+func TestKeyStore_JWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keys := NewFromKeys(
+		&Key{ID: "a-rsa", Method: jwt.SigningMethodRS256, Public: &rsaKey.PublicKey, Private: rsaKey},
+		&Key{ID: "b-ed", Method: jwt.SigningMethodEdDSA, Public: edPub},
+	)
+
+	set := keys.JWKS()
+	require.Len(t, set.Keys, 2)
+
+	assert.Equal(t, "RSA", set.Keys[0].Kty)
+	assert.Equal(t, "RS256", set.Keys[0].Alg)
+	assert.Equal(t, "AQAB", set.Keys[0].E)
+	assert.NotEmpty(t, set.Keys[0].N)
+
+	assert.Equal(t, "OKP", set.Keys[1].Kty)
+	assert.Equal(t, "Ed25519", set.Keys[1].Crv)
+	assert.Equal(t, "EdDSA", set.Keys[1].Alg)
+	assert.NotEmpty(t, set.Keys[1].X)
+}
+
+func TestKeyStore_Sign(t *testing.T) {
+	t.Run("No signing key", func(t *testing.T) {
+		edPub, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		keys := NewFromKeys(&Key{ID: "public-only", Method: jwt.SigningMethodEdDSA, Public: edPub})
+		_, err = keys.Sign(claims())
+		assert.ErrorIs(t, err, ErrNoSigningKey)
+	})
+}
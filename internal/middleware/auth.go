@@ -1,14 +1,27 @@
 package middleware
 
 import (
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 )
 
-// Function for a authentication of a user by token
-func JWTAuth(secret string) echo.MiddlewareFunc {
+// Interface of the access token revocation list consulted on every request
+type RevocationChecker interface {
+	IsRevoked(jti, userID string, issuedAt time.Time) bool
+}
+
+// Interface of the key set used to verify token signatures
+type KeyProvider interface {
+	Keyfunc(token *jwt.Token) (interface{}, error)
+}
+
+// Function for a authentication of a user by token, only tokens signed by one of the known keys
+// with one of the allowed algorithms are accepted
+func JWTAuth(keys KeyProvider, algorithms []string, revocations RevocationChecker) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			authHeader := c.Request().Header.Get("Authorization")
@@ -21,18 +34,53 @@ func JWTAuth(secret string) echo.MiddlewareFunc {
 				return c.JSON(401, map[string]string{"error": "invalid token format"})
 			}
 
-			token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-				return []byte(secret), nil
-			})
+			token, err := jwt.Parse(tokenString, keys.Keyfunc,
+				jwt.WithValidMethods(algorithms), jwt.WithExpirationRequired(), jwt.WithIssuedAt())
+
+			if errors.Is(err, jwt.ErrTokenExpired) {
+				return c.JSON(401, map[string]string{"error": "token expired"})
+			}
 
 			if err != nil || !token.Valid {
 				return c.JSON(401, map[string]string{"error": "invalid token"})
 			}
 
 			claims := token.Claims.(jwt.MapClaims)
+
+			jti, _ := claims["jti"].(string)
+			userID, _ := claims["user_id"].(string)
+			var issuedAt time.Time
+			if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+				issuedAt = iat.Time
+			}
+			if revocations.IsRevoked(jti, userID, issuedAt) {
+				return c.JSON(401, map[string]string{"error": "token revoked"})
+			}
+
 			c.Set("user_id", claims["user_id"])
+			c.Set("jti", jti)
+			role, _ := claims["role"].(string)
+			c.Set("role", role)
 
 			return next(c)
 		}
 	}
 }
+
+// Function that lets through only users authenticated by JWTAuth with one of the given roles
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	allowed := make(map[string]struct{}, len(roles))
+	for _, role := range roles {
+		allowed[role] = struct{}{}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			role, _ := c.Get("role").(string)
+			if _, ok := allowed[role]; !ok {
+				return c.JSON(403, map[string]string{"error": "insufficient permissions"})
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/garaevmir/avitocoinstore/internal/keystore"
+	"github.com/garaevmir/avitocoinstore/internal/model"
+)
+
+var testKeys = newTestKeys()
+
+func newTestKeys() *keystore.KeyStore {
+	key, err := keystore.GenerateKey()
+	if err != nil {
+		panic(err)
+	}
+	return keystore.NewFromKeys(key)
+}
+
+type revocationStub struct {
+	jti    string
+	userID string
+	before time.Time
+}
+
+func (r revocationStub) IsRevoked(jti, userID string, issuedAt time.Time) bool {
+	return jti == r.jti || (userID == r.userID && issuedAt.Before(r.before))
+}
+
+func signToken(t *testing.T, claims jwt.MapClaims, keys *keystore.KeyStore) string {
+	token, err := keys.Sign(claims)
+	assert.NoError(t, err)
+	return token
+}
+
+func TestJWTAuth(t *testing.T) {
+	e := echo.New()
+	auth := JWTAuth(testKeys, keystore.Algorithms, revocationStub{jti: "revoked", userID: "user2", before: time.Now()})
+
+	next := func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]interface{}{"user_id": c.Get("user_id"), "role": c.Get("role")})
+	}
+
+	t.Run("Valid token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
+		req.Header.Set("Authorization", "Bearer "+signToken(t,
+			jwt.MapClaims{
+				"jti":     "token1",
+				"user_id": "user1",
+				"role":    model.RoleFinance,
+				"exp":     time.Now().Add(time.Minute).Unix(),
+			}, testKeys))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := auth(next)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "user1", c.Get("user_id"))
+		assert.Equal(t, model.RoleFinance, c.Get("role"))
+		assert.Equal(t, "token1", c.Get("jti"))
+	})
+
+	t.Run("Revoked token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
+		req.Header.Set("Authorization", "Bearer "+signToken(t, jwt.MapClaims{
+			"jti":     "revoked",
+			"user_id": "user1",
+			"exp":     time.Now().Add(time.Minute).Unix(),
+		}, testKeys))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := auth(next)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "token revoked")
+	})
+
+	t.Run("Token issued before user revocation", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
+		req.Header.Set("Authorization", "Bearer "+signToken(t, jwt.MapClaims{
+			"jti":     "fresh",
+			"user_id": "user2",
+			"iat":     time.Now().Add(-time.Hour).Unix(),
+			"exp":     time.Now().Add(time.Minute).Unix(),
+		}, testKeys))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := auth(next)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "token revoked")
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := auth(next)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
+		req.Header.Set("Authorization", "Bearer "+signToken(t, jwt.MapClaims{
+			"user_id": "user1",
+			"exp":     time.Now().Add(-time.Minute).Unix(),
+		}, testKeys))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := auth(next)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "token expired")
+	})
+
+	t.Run("Token without expiration", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
+		req.Header.Set("Authorization", "Bearer "+signToken(t, jwt.MapClaims{"user_id": "user1"}, testKeys))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := auth(next)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid token")
+	})
+
+	t.Run("Symmetric token is rejected", func(t *testing.T) {
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"user_id": "user1",
+			"exp":     time.Now().Add(time.Minute).Unix(),
+		}).SignedString([]byte("secret"))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := auth(next)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Token signed with unknown key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/info", nil)
+		req.Header.Set("Authorization", "Bearer "+signToken(t, jwt.MapClaims{
+			"user_id": "user1",
+			"exp":     time.Now().Add(time.Minute).Unix(),
+		}, newTestKeys()))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := auth(next)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestRequireRole(t *testing.T) {
+	e := echo.New()
+	guard := RequireRole(model.RoleAdmin, model.RoleMerchManager)
+
+	next := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+
+	for _, tc := range []struct {
+		name string
+		role interface{}
+		code int
+	}{
+		{"Admin is allowed", model.RoleAdmin, http.StatusOK},
+		{"Merch manager is allowed", model.RoleMerchManager, http.StatusOK},
+		{"Employee is forbidden", model.RoleEmployee, http.StatusForbidden},
+		{"Missing role is forbidden", nil, http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/items", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("role", tc.role)
+
+			err := guard(next)(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+}
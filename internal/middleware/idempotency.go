@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+)
+
+// Name of header carrying client generated idempotency key
+const IdempotencyHeader = "Idempotency-Key"
+
+// Maximal length of idempotency key
+const maxIdempotencyKeyLength = 255
+
+// Interface of the storage of responses to requests made with idempotency key
+type IdempotencyStore interface {
+	Reserve(ctx context.Context, userID, key, requestHash string, ttl, lease time.Duration) (string, *model.IdempotencyRecord, error)
+	Complete(ctx context.Context, userID, key, token string, status int, contentType string, body []byte) error
+	Release(ctx context.Context, userID, key, token string) error
+}
+
+// Function for replaying responses of requests retried with the same Idempotency-Key header during ttl,
+// reuse of key for another request is rejected, requests without header are passed through. Key of request
+// that has not finished within lease, e.g. because the server crashed, is given to the next request
+func Idempotency(store IdempotencyStore, ttl, lease time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(IdempotencyHeader)
+			userID, _ := c.Get("user_id").(string)
+			if key == "" || userID == "" {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return c.JSON(400, map[string]string{"error": "idempotency key is too long"})
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return c.JSON(400, map[string]string{"error": model.ErrInvalidRequest.Error()})
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx := c.Request().Context()
+			hash := requestHash(c.Request(), body)
+			token, record, err := store.Reserve(ctx, userID, key, hash, ttl, lease)
+			if err != nil {
+				return c.JSON(500, map[string]string{"error": model.ErrInternalError.Error()})
+			}
+			if record != nil {
+				switch {
+				case record.RequestHash != hash:
+					return c.JSON(422, map[string]string{"error": model.ErrIdempotencyReused.Error()})
+				case record.StatusCode == 0:
+					return c.JSON(409, map[string]string{"error": model.ErrIdempotencyPending.Error()})
+				}
+				c.Response().Header().Set("Idempotent-Replayed", "true")
+				return c.Blob(record.StatusCode, record.ContentType, record.Body)
+			}
+
+			// Outcome is saved even if client disconnects, otherwise key stays pending until lease runs out
+			ctx = context.WithoutCancel(ctx)
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			err = next(c)
+			c.Response().Writer = recorder.ResponseWriter
+
+			// Failed requests did not change anything, so key is freed for retry
+			status := c.Response().Status
+			if err != nil || status >= 500 || !c.Response().Committed {
+				if err := store.Release(ctx, userID, key, token); err != nil {
+					log.Printf("Releasing idempotency key error: %v", err)
+				}
+				return err
+			}
+
+			contentType := c.Response().Header().Get(echo.HeaderContentType)
+			if err := store.Complete(ctx, userID, key, token, status, contentType, recorder.body.Bytes()); err != nil {
+				log.Printf("Storing idempotent response error: %v", err)
+			}
+			return nil
+		}
+	}
+}
+
+// Hash identifying request by method, path with query and body
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Response writer keeping copy of written body
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestIdempotency(t *testing.T) {
+	e := echo.New()
+	store := new(mocks.IdempotencyRepositoryMock)
+	idempotent := Idempotency(store, time.Hour, time.Minute)
+
+	calls := 0
+	next := func(c echo.Context) error {
+		calls++
+		return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+	}
+
+	newContext := func(key, body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(IdempotencyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", "user1")
+		return c, rec
+	}
+
+	body := `{"toUser": "bob", "amount": 10}`
+	hash := requestHash(httptest.NewRequest(http.MethodPost, "/api/sendCoin", nil), []byte(body))
+
+	t.Run("First request is stored", func(t *testing.T) {
+		calls = 0
+		store.On("Reserve", mock.Anything, "user1", "key1", hash, time.Hour, time.Minute).Return("token-1", nil, nil).Once()
+		store.On("Complete", mock.Anything, "user1", "key1", "token-1", http.StatusOK, echo.MIMEApplicationJSON, mock.Anything).
+			Return(nil).Once()
+
+		c, rec := newContext("key1", body)
+		err := idempotent(next)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 1, calls)
+		store.AssertExpectations(t)
+		assert.JSONEq(t, `{"status": "success"}`, string(store.Calls[len(store.Calls)-1].Arguments.Get(6).([]byte)))
+	})
+
+	t.Run("Retry replays stored response", func(t *testing.T) {
+		calls = 0
+		store.On("Reserve", mock.Anything, "user1", "key1", hash, time.Hour, time.Minute).Return("", &model.IdempotencyRecord{
+			RequestHash: hash,
+			StatusCode:  http.StatusOK,
+			ContentType: echo.MIMEApplicationJSON,
+			Body:        []byte(`{"status":"success"}`),
+		}, nil).Once()
+
+		c, rec := newContext("key1", body)
+		err := idempotent(next)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"status":"success"}`, rec.Body.String())
+		assert.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+		assert.Zero(t, calls)
+	})
+
+	t.Run("Key reused for another request", func(t *testing.T) {
+		calls = 0
+		store.On("Reserve", mock.Anything, "user1", "key1", mock.Anything, time.Hour, time.Minute).
+			Return("", &model.IdempotencyRecord{RequestHash: hash, StatusCode: http.StatusOK}, nil).Once()
+
+		c, rec := newContext("key1", `{"toUser": "bob", "amount": 1000}`)
+		err := idempotent(next)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Zero(t, calls)
+	})
+
+	t.Run("Original request still in progress", func(t *testing.T) {
+		store.On("Reserve", mock.Anything, "user1", "key2", hash, time.Hour, time.Minute).
+			Return("", &model.IdempotencyRecord{RequestHash: hash}, nil).Once()
+
+		c, rec := newContext("key2", body)
+		err := idempotent(next)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("Failed request releases key", func(t *testing.T) {
+		store.On("Reserve", mock.Anything, "user1", "key3", hash, time.Hour, time.Minute).Return("token-3", nil, nil).Once()
+		store.On("Release", mock.Anything, "user1", "key3", "token-3").Return(nil).Once()
+
+		failing := func(c echo.Context) error {
+			return c.JSON(http.StatusInternalServerError, model.ErrorResponse{Errors: model.ErrInternalError.Error()})
+		}
+
+		c, rec := newContext("key3", body)
+		err := idempotent(failing)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		store.AssertExpectations(t)
+	})
+
+	t.Run("Request body reaches handler", func(t *testing.T) {
+		store.On("Reserve", mock.Anything, "user1", "key4", hash, time.Hour, time.Minute).Return("token-4", nil, nil).Once()
+		store.On("Complete", mock.Anything, "user1", "key4", "token-4", http.StatusOK, mock.Anything, mock.Anything).
+			Return(nil).Once()
+
+		var received map[string]interface{}
+		binding := func(c echo.Context) error {
+			if err := c.Bind(&received); err != nil {
+				return err
+			}
+			return c.JSON(http.StatusOK, received)
+		}
+
+		c, _ := newContext("key4", body)
+		err := idempotent(binding)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "bob", received["toUser"])
+	})
+
+	t.Run("Request without key", func(t *testing.T) {
+		calls = 0
+		c, rec := newContext("", body)
+		err := idempotent(next)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Store error", func(t *testing.T) {
+		store.On("Reserve", mock.Anything, "user1", "key5", hash, time.Hour, time.Minute).
+			Return("", nil, model.ErrInternalError).Once()
+
+		c, rec := newContext("key5", body)
+		err := idempotent(next)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("Response is stored after client disconnects", func(t *testing.T) {
+		store.On("Reserve", mock.Anything, "user1", "key6", hash, time.Hour, time.Minute).Return("token-6", nil, nil).Once()
+		store.On("Complete", mock.MatchedBy(func(ctx context.Context) bool { return ctx.Err() == nil }),
+			"user1", "key6", "token-6", http.StatusOK, mock.Anything, mock.Anything).Return(nil).Once()
+
+		c, _ := newContext("key6", body)
+		ctx, cancel := context.WithCancel(c.Request().Context())
+		c.SetRequest(c.Request().WithContext(ctx))
+		disconnecting := func(c echo.Context) error {
+			cancel()
+			return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+		}
+
+		err := idempotent(disconnecting)(c)
+
+		assert.NoError(t, err)
+		store.AssertExpectations(t)
+	})
+}
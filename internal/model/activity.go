@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// Event that changed balance of user, Amount is signed and Balance is balance of user right after the event,
+// Counterparty is set for transfers and Item for purchases
+type ActivityEvent struct {
+	ID           int64     `json:"id"`
+	Kind         string    `json:"kind"`
+	Amount       int       `json:"amount"`
+	Balance      int       `json:"balance"`
+	Counterparty string    `json:"counterparty,omitempty"`
+	Item         string    `json:"item,omitempty"`
+	Reason       string    `json:"reason,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// Structure for activity query, Cursor is id of the last event of previous page or 0,
+// From is inclusive and To is exclusive
+type ActivityFilter struct {
+	From   *time.Time
+	To     *time.Time
+	Cursor int64
+	Limit  int
+}
+
+// Page of activity, newest events first, NextCursor is empty on the last page
+type ActivityPage struct {
+	Events     []ActivityEvent `json:"events"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+}
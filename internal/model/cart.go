@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Line of user cart, UnitPrice is the catalog price when line was last set
+type CartItem struct {
+	Item      string    `json:"item"`
+	Quantity  int       `json:"quantity"`
+	UnitPrice int       `json:"unitPrice"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Price of cart line that differs from current catalog price
+type PriceChange struct {
+	Item     string `json:"item"`
+	OldPrice int    `json:"oldPrice"`
+	NewPrice int    `json:"newPrice"`
+}
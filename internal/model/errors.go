@@ -3,14 +3,43 @@ package model
 import "errors"
 
 var (
-	ErrItemNotFound       = errors.New("item not found")
-	ErrUserNotFound       = errors.New("user not found")
-	ErrInventory          = errors.New("failed to get inventory")
-	ErrNegAmount          = errors.New("amount must be positive")
-	ErrHistory            = errors.New("failed to get history")
-	ErrInsufficientFunds  = errors.New("insufficient funds")
-	ErrInvalidRequest     = errors.New("invalid request")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrInternalError      = errors.New("something went wrong")
-	ErrCreateUser         = errors.New("create user error")
+	ErrItemNotFound        = errors.New("item not found")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrInventory           = errors.New("failed to get inventory")
+	ErrNegAmount           = errors.New("amount must be positive")
+	ErrHistory             = errors.New("failed to get history")
+	ErrInsufficientFunds   = errors.New("insufficient funds")
+	ErrInvalidRequest      = errors.New("invalid request")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrInternalError       = errors.New("something went wrong")
+	ErrCreateUser          = errors.New("create user error")
+	ErrItemExists          = errors.New("item already exists")
+	ErrInvalidRefresh      = errors.New("invalid refresh token")
+	ErrUserExists          = errors.New("user already exists")
+	ErrRegistrationClosed  = errors.New("registration is not allowed")
+	ErrInvalidInvite       = errors.New("invalid invite code")
+	ErrTooManyAttempts     = errors.New("too many login attempts, try again later")
+	ErrPasswordTooShort    = errors.New("password is too short")
+	ErrPasswordIsUsername  = errors.New("password must differ from username")
+	ErrInvalidResetToken   = errors.New("invalid password reset token")
+	ErrUnbalancedPosting   = errors.New("ledger posting does not sum up to zero")
+	ErrOrders              = errors.New("failed to get orders")
+	ErrCartEmpty           = errors.New("cart is empty")
+	ErrPriceChanged        = errors.New("prices changed since items were added to cart")
+	ErrIdempotencyReused   = errors.New("idempotency key was already used for another request")
+	ErrIdempotencyPending  = errors.New("request with this idempotency key is still in progress")
+	ErrSelfTransfer        = errors.New("can not send coins to yourself")
+	ErrRecipientInactive   = errors.New("recipient account is frozen or deactivated")
+	ErrTransferLimit       = errors.New("amount exceeds single transfer limit")
+	ErrInvalidStatus       = errors.New("invalid account status")
+	ErrDailyLimit          = errors.New("daily transfer limit exceeded")
+	ErrRecipientNotAllowed = errors.New("transfers to this recipient are not allowed")
+	ErrTooManyTransfers    = errors.New("too many transfers, try again later")
+	ErrMessageTooLong      = errors.New("transfer message is too long")
+	ErrInvalidReason       = errors.New("invalid transfer reason")
+	ErrInvalidCursor       = errors.New("invalid page cursor")
+	ErrActivity            = errors.New("failed to get activity")
+	ErrStatement           = errors.New("failed to get statement")
+	ErrInvalidRole         = errors.New("invalid user role")
+	ErrPlaceholderPassword = errors.New("admin password is a placeholder")
 )
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Directions of transfer relative to user whose history is read
+const (
+	DirectionSent     = "sent"
+	DirectionReceived = "received"
+)
+
+// Limits of history page size
+const (
+	DefaultHistoryLimit = 50
+	MaxHistoryLimit     = 100
+)
+
+// Transfer as seen in history of one of its participants, Counterparty is username of the other one
+type HistoryEntry struct {
+	ID           string    `json:"id"`
+	Direction    string    `json:"direction"`
+	Counterparty string    `json:"counterparty"`
+	Amount       int       `json:"amount"`
+	Message      string    `json:"message,omitempty"`
+	Reason       string    `json:"reason,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// Format of id in cursor, ids of transfers and orders are UUIDs
+var cursorIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Position in history, the next page starts right after transfer with given time and id
+type HistoryCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Function that encodes cursor into opaque string for clients
+func (c HistoryCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Function that decodes cursor returned by Encode, returns cursor and ErrInvalidCursor if it is malformed
+func ParseHistoryCursor(s string) (*HistoryCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok || !cursorIDPattern.MatchString(id) {
+		return nil, ErrInvalidCursor
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &HistoryCursor{CreatedAt: t, ID: id}, nil
+}
+
+// Structure for history query, empty fields do not filter, From is inclusive and To is exclusive
+type HistoryFilter struct {
+	Direction    string
+	Counterparty string
+	Reason       string
+	From         *time.Time
+	To           *time.Time
+	Cursor       *HistoryCursor
+	Limit        int
+}
+
+// Page of history, NextCursor is empty on the last page
+type HistoryPage struct {
+	Entries    []HistoryEntry `json:"entries"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
+
+// Coins moved between user and one counterparty, Count is number of transfers in both directions
+type CounterpartySummary struct {
+	Counterparty string    `json:"counterparty"`
+	Sent         int       `json:"sent"`
+	Received     int       `json:"received"`
+	Count        int       `json:"count"`
+	FirstAt      time.Time `json:"firstAt"`
+	LastAt       time.Time `json:"lastAt"`
+}
@@ -0,0 +1,9 @@
+package model
+
+// Stored outcome of request made with idempotency key, StatusCode is zero while request is in progress
+type IdempotencyRecord struct {
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
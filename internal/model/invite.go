@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// An invite code that allows registration of a single user, only hash of the code is stored
+type Invite struct {
+	ID        string
+	CodeHash  string
+	CreatedBy string
+	ExpiresAt time.Time
+	UsedBy    *string
+	UsedAt    *time.Time
+}
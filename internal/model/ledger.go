@@ -0,0 +1,97 @@
+package model
+
+// Kinds of ledger postings
+const (
+	PostingGrant      = "grant"
+	PostingTransfer   = "transfer"
+	PostingPurchase   = "purchase"
+	PostingRefund     = "refund"
+	PostingAdjustment = "adjustment"
+	PostingCorrection = "correction"
+)
+
+// System accounts that are counterparts of user accounts in postings
+const (
+	AccountIssuance    = "issuance"
+	AccountShop        = "shop"
+	AccountAdjustments = "adjustments"
+)
+
+// Single movement of coins, positive amount credits the account and negative debits it,
+// entry belongs either to user or to system account
+type LedgerEntry struct {
+	UserID        string
+	SystemAccount string
+	Amount        int
+}
+
+// Set of ledger entries that are written together and sum up to zero
+type Posting struct {
+	Kind    string
+	Reason  string
+	Entries []LedgerEntry
+}
+
+// Posting of coins every new user starts with
+func GrantPosting(userID string, amount int) *Posting {
+	return userPosting(PostingGrant, "", userID, AccountIssuance, amount)
+}
+
+// Posting of coins sent by one user to another
+func TransferPosting(fromUserID, toUserID string, amount int) *Posting {
+	return &Posting{
+		Kind: PostingTransfer,
+		Entries: []LedgerEntry{
+			{UserID: fromUserID, Amount: -amount},
+			{UserID: toUserID, Amount: amount},
+		},
+	}
+}
+
+// Posting of coins user paid for item
+func PurchasePosting(userID string, amount int, item string) *Posting {
+	return userPosting(PostingPurchase, item, userID, AccountShop, -amount)
+}
+
+// Posting of coins returned to user by the shop
+func RefundPosting(userID string, amount int, reason string) *Posting {
+	return userPosting(PostingRefund, reason, userID, AccountShop, amount)
+}
+
+// Posting of coins added to or, with negative amount, taken from user by administrator
+func AdjustmentPosting(userID string, amount int, reason string) *Posting {
+	return userPosting(PostingAdjustment, reason, userID, AccountAdjustments, amount)
+}
+
+// Posting written by reconciliation to bring ledger of user to balance expected from other records
+func CorrectionPosting(userID string, amount int, reason string) *Posting {
+	return userPosting(PostingCorrection, reason, userID, AccountAdjustments, amount)
+}
+
+// Function that checks that entries sum up to zero and none of them is empty
+func (p *Posting) Balanced() bool {
+	if len(p.Entries) < 2 {
+		return false
+	}
+
+	sum := 0
+	for _, entry := range p.Entries {
+		if entry.Amount == 0 || (entry.UserID == "") == (entry.SystemAccount == "") {
+			return false
+		}
+		sum += entry.Amount
+	}
+	return sum == 0
+}
+
+// Builds posting that credits user with amount and debits system account with the same amount
+func userPosting(kind, reason, userID, account string, amount int) *Posting {
+	return &Posting{
+		Kind:   kind,
+		Reason: reason,
+		Entries: []LedgerEntry{
+			{UserID: userID, Amount: amount},
+			{SystemAccount: account, Amount: -amount},
+		},
+	}
+}
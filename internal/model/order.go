@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Statuses of order
+const (
+	OrderCompleted = "completed"
+	OrderRefunded  = "refunded"
+)
+
+// Purchase of item by user, UnitPrice keeps the price paid even after catalog price changes
+type Order struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"-"`
+	Item      string    `json:"item"`
+	UnitPrice int       `json:"unitPrice"`
+	Quantity  int       `json:"quantity"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Page of orders newest first, NextCursor is empty on the last page
+type OrderPage struct {
+	Orders     []Order `json:"orders"`
+	NextCursor string  `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// A one-time password reset token issued by administrator, only hash of the token is stored
+type PasswordReset struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	CreatedBy string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
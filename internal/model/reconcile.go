@@ -0,0 +1,40 @@
+package model
+
+// Balance of user audited against records kept apart from the ledger. Expected is rebuilt from grants,
+// transfers in transactions, purchases and refunds in orders and administrator adjustments, LedgerDifference
+// is the part of Ledger total it does not explain. CacheDrift is how far cached Coins went from Ledger,
+// it is reported apart as no posting can fix it. Corrected sums postings written by reconciliation and is left
+// out of Expected, as they close the difference
+type BalanceReport struct {
+	UserID           string `json:"userId"`
+	Username         string `json:"username"`
+	Coins            int    `json:"coins"`
+	Ledger           int    `json:"ledger"`
+	Expected         int    `json:"expected"`
+	LedgerDifference int    `json:"ledgerDifference"`
+	CacheDrift       int    `json:"cacheDrift"`
+	Granted          int    `json:"granted"`
+	Received         int    `json:"received"`
+	Sent             int    `json:"sent"`
+	Spent            int    `json:"spent"`
+	Refunded         int    `json:"refunded"`
+	Adjusted         int    `json:"adjusted"`
+	Corrected        int    `json:"corrected"`
+}
+
+// Function that computes expected balance, difference of ledger from it and drift of cached balance from ledger
+func (b *BalanceReport) Compute() {
+	b.Expected = b.Granted + b.Received - b.Sent - b.Spent + b.Refunded + b.Adjusted
+	b.LedgerDifference = b.Ledger - b.Expected
+	b.CacheDrift = b.Coins - b.Ledger
+}
+
+// Function that reports whether ledger disagrees with expected balance, which correction posting fixes
+func (b *BalanceReport) Mismatched() bool {
+	return b.LedgerDifference != 0
+}
+
+// Function that reports whether cached balance disagrees with ledger, so users.coins was changed bypassing it
+func (b *BalanceReport) Drifted() bool {
+	return b.CacheDrift != 0
+}
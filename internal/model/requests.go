@@ -1,9 +1,13 @@
 package model
 
-// Structure that describes send coin request
+import "time"
+
+// Structure that describes send coin request, Message and Reason are optional
 type SendCoinRequest struct {
-	ToUser string `json:"toUser" validate:"required"`
-	Amount int    `json:"amount" validate:"required,gt=0"`
+	ToUser  string `json:"toUser" validate:"required"`
+	Amount  int    `json:"amount" validate:"required,gt=0"`
+	Message string `json:"message"`
+	Reason  string `json:"reason" validate:"omitempty,oneof=thanks gift bet reimbursement"`
 }
 
 // Structure that describes authentication request
@@ -11,3 +15,92 @@ type AuthRequest struct {
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
 }
+
+// Structure that describes registration request, InviteCode is required unless username is in allow-list
+type RegisterRequest struct {
+	Username   string `json:"username" validate:"required"`
+	Password   string `json:"password" validate:"required"`
+	InviteCode string `json:"inviteCode"`
+}
+
+// Structure that describes invite creation request, when ExpiresAt is omitted default lifetime is used
+type CreateInviteRequest struct {
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// Structure that describes password change request
+type ChangePasswordRequest struct {
+	OldPassword string `json:"oldPassword" validate:"required"`
+	NewPassword string `json:"newPassword" validate:"required"`
+}
+
+// Structure that describes password reset by one-time token
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"newPassword" validate:"required"`
+}
+
+// Structure that describes manual balance adjustment, negative amount takes coins from user
+type AdjustBalanceRequest struct {
+	Amount int    `json:"amount" validate:"required"`
+	Reason string `json:"reason" validate:"required"`
+}
+
+// Structure for writing correcting adjustments after reconciliation
+type ReconcileRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// Structure that describes token refresh and logout requests
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// Structure that describes change of user role, one of employee, merch-manager, finance and admin
+type UserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=employee merch-manager finance admin"`
+}
+
+// Structure that describes change of account status, one of active, frozen and deactivated
+type UserStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=active frozen deactivated"`
+}
+
+// Structure that describes catalog item creation request
+type CreateItemRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Price       int    `json:"price" validate:"required,gt=0"`
+	Description string `json:"description"`
+}
+
+// Structure that describes item price change request
+type UpdateItemPriceRequest struct {
+	Price int `json:"price" validate:"required,gt=0"`
+}
+
+// Structure that describes single access token revocation request
+type RevokeTokenRequest struct {
+	JTI string `json:"jti" validate:"required"`
+}
+
+// Structure that describes revocation of user tokens, tokens issued before Before are revoked,
+// when Before is omitted every token issued so far is revoked
+type RevokeUserTokensRequest struct {
+	Before *time.Time `json:"before"`
+}
+
+// Structure for one line of order, Quantity units of Item
+type OrderLine struct {
+	Item     string `json:"item" validate:"required"`
+	Quantity int    `json:"quantity" validate:"required"`
+}
+
+// Structure for buying several items in one order
+type PlaceOrderRequest struct {
+	Items []OrderLine `json:"items" validate:"required"`
+}
+
+// Structure for setting quantity of cart line
+type CartItemRequest struct {
+	Quantity int `json:"quantity" validate:"required"`
+}
@@ -7,7 +7,8 @@ type ErrorResponse struct {
 }
 
 type AuthResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken,omitempty"`
 }
 
 type InventoryItem struct {
@@ -15,25 +16,92 @@ type InventoryItem struct {
 	Quantity int    `json:"quantity"`
 }
 
+// Most recent transfers of user, older ones are read from /api/history starting at NextCursor
 type TransactionHistory struct {
-	Received []ReceivedTransaction `json:"received"`
-	Sent     []SentTransaction     `json:"sent"`
+	Received   []ReceivedTransaction `json:"received"`
+	Sent       []SentTransaction     `json:"sent"`
+	NextCursor string                `json:"nextCursor,omitempty"`
 }
 
 type ReceivedTransaction struct {
 	FromUser  string    `json:"fromUser"`
 	Amount    int       `json:"amount"`
+	Message   string    `json:"message,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
 	Timestamp time.Time `json:"timestamp"`
 }
 
 type SentTransaction struct {
 	ToUser    string    `json:"toUser"`
 	Amount    int       `json:"amount"`
+	Message   string    `json:"message,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
 	Timestamp time.Time `json:"timestamp"`
 }
 
 type InfoResponse struct {
-	Coins       int                `json:"coins"`
-	Inventory   []InventoryItem    `json:"inventory"`
-	CoinHistory TransactionHistory `json:"coinHistory"`
+	Coins       int                    `json:"coins"`
+	Inventory   []InventoryItem        `json:"inventory"`
+	CoinHistory *TransactionHistory    `json:"coinHistory,omitempty"`
+	CoinSummary *[]CounterpartySummary `json:"coinSummary,omitempty"`
+	Orders      []Order                `json:"orders"`
+	// Cursor for GET /api/orders when user has more orders than /api/info returns
+	OrdersNextCursor string `json:"ordersNextCursor,omitempty"`
+}
+
+type ItemResponse struct {
+	Name        string `json:"name"`
+	Price       int    `json:"price"`
+	Description string `json:"description"`
+	Available   bool   `json:"available"`
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Structure that describes created invite, code is shown only once
+type InviteResponse struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Structure that describes issued password reset token, token is shown only once
+type PasswordResetResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Structure that describes balance after adjustment
+type BalanceResponse struct {
+	Coins int `json:"coins"`
+}
+
+// Structure that describes placed order, one order for every line and total price paid
+type PlaceOrderResponse struct {
+	Orders []Order `json:"orders"`
+	Total  int     `json:"total"`
+}
+
+// Structure that describes cart with total price at prices of cart lines
+type CartResponse struct {
+	Items []CartItem `json:"items"`
+	Total int        `json:"total"`
+}
+
+// Structure that describes failed checkout, lists lines whose price changed
+type PriceChangedResponse struct {
+	Errors  string        `json:"errors"`
+	Changes []PriceChange `json:"changes"`
 }
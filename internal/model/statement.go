@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Formats of wallet statement
+const (
+	StatementCSV  = "csv"
+	StatementJSON = "json"
+)
+
+// Header of wallet statement for period, nil From or To leave period open on that side
+type StatementHeader struct {
+	Username       string     `json:"username"`
+	From           *time.Time `json:"from"`
+	To             *time.Time `json:"to"`
+	OpeningBalance int        `json:"openingBalance"`
+}
+
+// Interface for receivers of statement that is read row by row, Opening is called once before all movements
+type StatementWriter interface {
+	Opening(balance int) error
+	Movement(event ActivityEvent) error
+}
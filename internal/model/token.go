@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// A refresh token issued together with access token, only hash of the token is stored
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
@@ -0,0 +1,27 @@
+package model
+
+// Categories of transfers
+const (
+	ReasonThanks        = "thanks"
+	ReasonGift          = "gift"
+	ReasonBet           = "bet"
+	ReasonReimbursement = "reimbursement"
+)
+
+// Longest message that can be attached to transfer, in characters
+const MaxTransferMessageLength = 200
+
+// Structure for optional note attached to transfer, Reason is one of transfer categories or empty
+type TransferNote struct {
+	Message string
+	Reason  string
+}
+
+// Function that reports whether reason is one of transfer categories
+func ValidTransferReason(reason string) bool {
+	switch reason {
+	case ReasonThanks, ReasonGift, ReasonBet, ReasonReimbursement:
+		return true
+	}
+	return false
+}
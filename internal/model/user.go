@@ -1,8 +1,34 @@
 package model
 
+// Roles of users
+const (
+	RoleEmployee     = "employee"
+	RoleMerchManager = "merch-manager"
+	RoleFinance      = "finance"
+	RoleAdmin        = "admin"
+)
+
+// Function that reports whether role is one of known roles
+func ValidRole(role string) bool {
+	switch role {
+	case RoleEmployee, RoleMerchManager, RoleFinance, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// Statuses of user accounts, frozen and deactivated accounts can not receive coins
+const (
+	UserActive      = "active"
+	UserFrozen      = "frozen"
+	UserDeactivated = "deactivated"
+)
+
 type User struct {
 	ID           string `json:"id"`
 	Username     string `json:"username"`
 	PasswordHash string `json:"-"`
 	Coins        int    `json:"coins"`
+	Role         string `json:"role"`
+	Status       string `json:"status"`
 }
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+)
+
+// Interface for cart repository, needed for testing
+type CartRepositoryInt interface {
+	SetItem(ctx context.Context, userID, item string, quantity, unitPrice int) error
+	RemoveItem(ctx context.Context, userID, item string) (bool, error)
+	GetCart(ctx context.Context, userID string) ([]model.CartItem, error)
+	GetCartForUpdateTx(ctx context.Context, tx pgx.Tx, userID string) ([]model.CartItem, error)
+	RemoveItemsTx(ctx context.Context, tx pgx.Tx, userID string, items []string) error
+}
+
+// Cart repository for lines users keep before checkout
+type CartRepository struct {
+	pool DB
+}
+
+// Constructor for cart repository
+func NewCartRepository(db DB) *CartRepository {
+	return &CartRepository{pool: db}
+}
+
+// Function that adds line to cart of user or replaces its quantity and price, returns error
+func (r CartRepository) SetItem(ctx context.Context, userID, item string, quantity, unitPrice int) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO cart_items (user_id, item_name, quantity, unit_price)
+         VALUES ($1, $2, $3, $4)
+         ON CONFLICT (user_id, item_name) DO UPDATE
+         SET quantity = excluded.quantity, unit_price = excluded.unit_price, updated_at = CURRENT_TIMESTAMP`,
+		userID, item, quantity, unitPrice,
+	)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Function that removes line from cart of user, returns whether line was in cart and error
+func (r CartRepository) RemoveItem(ctx context.Context, userID, item string) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		"DELETE FROM cart_items WHERE user_id = $1 AND item_name = $2",
+		userID, item,
+	)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Function that returns lines of cart of user in order they were set and error
+func (r CartRepository) GetCart(ctx context.Context, userID string) ([]model.CartItem, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT item_name, quantity, unit_price, updated_at
+         FROM cart_items
+         WHERE user_id = $1
+         ORDER BY updated_at, item_name`,
+		userID,
+	)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	return scanCart(rows)
+}
+
+// Function that returns lines of cart of user during transaction and locks them until it ends, so they can
+// not be changed or removed before checkout finishes, returns lines in order they were set and error
+func (r CartRepository) GetCartForUpdateTx(ctx context.Context, tx pgx.Tx, userID string) ([]model.CartItem, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT item_name, quantity, unit_price, updated_at
+         FROM cart_items
+         WHERE user_id = $1
+         ORDER BY updated_at, item_name
+         FOR UPDATE`,
+		userID,
+	)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	return scanCart(rows)
+}
+
+// Scans cart lines and closes rows
+func scanCart(rows pgx.Rows) ([]model.CartItem, error) {
+	defer rows.Close()
+
+	items := make([]model.CartItem, 0)
+	for rows.Next() {
+		var item model.CartItem
+		if err := rows.Scan(&item.Item, &item.Quantity, &item.UnitPrice, &item.UpdatedAt); err != nil {
+			log.Printf("Database error: %v", err)
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	return items, nil
+}
+
+// Function that removes given lines from cart of user during transaction, other lines are kept, returns error
+func (r CartRepository) RemoveItemsTx(ctx context.Context, tx pgx.Tx, userID string, items []string) error {
+	_, err := tx.Exec(ctx, "DELETE FROM cart_items WHERE user_id = $1 AND item_name = ANY($2)", userID, items)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return err
+	}
+	return nil
+}
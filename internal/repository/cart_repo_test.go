@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestCartRepository_SetItem(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewCartRepository(dbMock)
+	ctx := context.Background()
+
+	t.Run("Successful set", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user1", "hoody", 2, 300}).
+			Return(pgconn.NewCommandTag("INSERT 0 1"), nil).Once()
+
+		assert.NoError(t, repo.SetItem(ctx, "user1", "hoody", 2, 300))
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user1", "hoody", 2, 300}).
+			Return(pgconn.CommandTag{}, model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, repo.SetItem(ctx, "user1", "hoody", 2, 300), model.ErrInternalError)
+	})
+}
+
+func TestCartRepository_RemoveItem(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewCartRepository(dbMock)
+	ctx := context.Background()
+
+	t.Run("Line removed", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user1", "hoody"}).
+			Return(pgconn.NewCommandTag("DELETE 1"), nil).Once()
+
+		removed, err := repo.RemoveItem(ctx, "user1", "hoody")
+		assert.NoError(t, err)
+		assert.True(t, removed)
+	})
+
+	t.Run("Line not in cart", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user1", "pen"}).
+			Return(pgconn.NewCommandTag("DELETE 0"), nil).Once()
+
+		removed, err := repo.RemoveItem(ctx, "user1", "pen")
+		assert.NoError(t, err)
+		assert.False(t, removed)
+	})
+}
+
+func TestCartRepository_GetCart(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewCartRepository(dbMock)
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("Successful retrieval", func(t *testing.T) {
+		rowsMock := new(mocks.PgxRowsMock)
+		dbMock.On("Query", ctx, mock.Anything, []interface{}{"user1"}).Return(rowsMock, nil).Once()
+
+		rowsMock.On("Next").Return(true).Once()
+		rowsMock.On("Next").Return(false).Once()
+		rowsMock.On("Scan", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				*args[0].(*string) = "hoody"
+				*args[1].(*int) = 2
+				*args[2].(*int) = 300
+				*args[3].(*time.Time) = now
+			}).Return(nil).Once()
+		rowsMock.On("Err").Return(nil).Once()
+		rowsMock.On("Close").Return().Once()
+
+		items, err := repo.GetCart(ctx, "user1")
+		assert.NoError(t, err)
+		assert.Equal(t, []model.CartItem{{Item: "hoody", Quantity: 2, UnitPrice: 300, UpdatedAt: now}}, items)
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		dbMock.On("Query", ctx, mock.Anything, []interface{}{"user1"}).
+			Return(new(mocks.PgxRowsMock), model.ErrInternalError).Once()
+
+		_, err := repo.GetCart(ctx, "user1")
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestCartRepository_GetCartForUpdateTx(t *testing.T) {
+	repo := NewCartRepository(new(mocks.DBMock))
+	txMock := new(mocks.TxMock)
+	rowsMock := new(mocks.PgxRowsMock)
+	ctx := context.Background()
+
+	txMock.On("Query", ctx, mock.MatchedBy(func(sql string) bool {
+		return strings.HasSuffix(sql, "FOR UPDATE")
+	}), []interface{}{"user1"}).Return(rowsMock, nil).Once()
+	rowsMock.On("Next").Return(true).Once()
+	rowsMock.On("Next").Return(false).Once()
+	rowsMock.On("Scan", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args[0].(*string) = "pen"
+			*args[1].(*int) = 3
+			*args[2].(*int) = 10
+		}).Return(nil).Once()
+	rowsMock.On("Err").Return(nil).Once()
+	rowsMock.On("Close").Return().Once()
+
+	items, err := repo.GetCartForUpdateTx(ctx, txMock, "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CartItem{{Item: "pen", Quantity: 3, UnitPrice: 10}}, items)
+	txMock.AssertExpectations(t)
+	rowsMock.AssertExpectations(t)
+}
+
+func TestCartRepository_RemoveItemsTx(t *testing.T) {
+	repo := NewCartRepository(new(mocks.DBMock))
+	txMock := new(mocks.TxMock)
+	ctx := context.Background()
+
+	txMock.On("Exec", ctx, mock.Anything, []interface{}{"user1", []string{"hoody", "pen"}}).
+		Return(pgconn.NewCommandTag("DELETE 2"), nil).Once()
+
+	assert.NoError(t, repo.RemoveItemsTx(ctx, txMock, "user1", []string{"hoody", "pen"}))
+	txMock.AssertExpectations(t)
+}
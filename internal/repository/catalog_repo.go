@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+)
+
+// Interface for catalog repository, needed for testing
+type CatalogRepositoryInt interface {
+	GetItemByName(ctx context.Context, name string) (*model.Item, error)
+	GetItemForShareTx(ctx context.Context, tx pgx.Tx, name string) (*model.Item, error)
+	ListItems(ctx context.Context, sortBy string, maxPrice int) ([]model.Item, error)
+	CreateItem(ctx context.Context, item *model.Item) error
+	UpdateItemPrice(ctx context.Context, name string, price int) (*model.Item, error)
+	DeactivateItem(ctx context.Context, name string) (*model.Item, error)
+}
+
+// Allowed orderings for catalog listing
+var itemOrderings = map[string]string{
+	"":       "name ASC",
+	"name":   "name ASC",
+	"-name":  "name DESC",
+	"price":  "price ASC, name ASC",
+	"-price": "price DESC, name ASC",
+}
+
+// Catalog repository for shop items manipulations
+type CatalogRepository struct {
+	pool DB
+}
+
+// Constructor for catalog repository
+func NewCatalogRepository(db DB) *CatalogRepository {
+	return &CatalogRepository{pool: db}
+}
+
+// Extracts item by given name if there exists such an item returns it's data otherwise returns nil,
+// returns item and error
+func (r CatalogRepository) GetItemByName(ctx context.Context, name string) (*model.Item, error) {
+	return scanItem(r.pool.QueryRow(ctx,
+		`SELECT name, price, description, active, created_at, updated_at
+         FROM items WHERE name = $1`,
+		name,
+	))
+}
+
+// Function that extracts item by given name during transaction and keeps its price and status from changing
+// until end of transaction, returns nil if there is no such item, returns item and error
+func (r CatalogRepository) GetItemForShareTx(ctx context.Context, tx pgx.Tx, name string) (*model.Item, error) {
+	return scanItem(tx.QueryRow(ctx,
+		`SELECT name, price, description, active, created_at, updated_at
+         FROM items WHERE name = $1 FOR SHARE`,
+		name,
+	))
+}
+
+// Scans single item, missing item is reported as nil
+func scanItem(row pgx.Row) (*model.Item, error) {
+	var item model.Item
+	err := row.Scan(&item.Name, &item.Price, &item.Description, &item.Active, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Function to extract active items of the catalog ordered by sortBy, unless maxPrice is negative only items
+// not more expensive than maxPrice are returned, returns slice of Item and error
+func (r CatalogRepository) ListItems(ctx context.Context, sortBy string, maxPrice int) ([]model.Item, error) {
+	ordering, ok := itemOrderings[sortBy]
+	if !ok {
+		return nil, model.ErrInvalidRequest
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT name, price, description, active, created_at, updated_at
+         FROM items
+         WHERE active AND ($1 < 0 OR price <= $1)
+         ORDER BY `+ordering,
+		maxPrice,
+	)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]model.Item, 0)
+	for rows.Next() {
+		var item model.Item
+		if err := rows.Scan(&item.Name, &item.Price, &item.Description,
+			&item.Active, &item.CreatedAt, &item.UpdatedAt); err != nil {
+			log.Printf("Database error: %v", err)
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	return items, nil
+}
+
+// Function that writes new item to the catalog and fills its generated fields, returns error
+func (r CatalogRepository) CreateItem(ctx context.Context, item *model.Item) error {
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO items (name, price, description)
+         VALUES ($1, $2, $3)
+         RETURNING active, created_at, updated_at`,
+		item.Name, item.Price, item.Description,
+	).Scan(&item.Active, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return model.ErrItemExists
+		}
+		log.Printf("Error creating item: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Function that sets new price of the item with given name, returns updated item and error
+func (r CatalogRepository) UpdateItemPrice(ctx context.Context, name string, price int) (*model.Item, error) {
+	return r.updateItem(ctx,
+		`UPDATE items SET price = $2, updated_at = CURRENT_TIMESTAMP
+         WHERE name = $1
+         RETURNING name, price, description, active, created_at, updated_at`,
+		name, price,
+	)
+}
+
+// Function that retires the item with given name so it can no longer be bought, returns updated item and error
+func (r CatalogRepository) DeactivateItem(ctx context.Context, name string) (*model.Item, error) {
+	return r.updateItem(ctx,
+		`UPDATE items SET active = FALSE, updated_at = CURRENT_TIMESTAMP
+         WHERE name = $1
+         RETURNING name, price, description, active, created_at, updated_at`,
+		name,
+	)
+}
+
+// Executes update query returning a single item, missing item is reported as ErrItemNotFound
+func (r CatalogRepository) updateItem(ctx context.Context, query string, args ...any) (*model.Item, error) {
+	var item model.Item
+	err := r.pool.QueryRow(ctx, query, args...).
+		Scan(&item.Name, &item.Price, &item.Description, &item.Active, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, model.ErrItemNotFound
+		}
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	return &item, nil
+}
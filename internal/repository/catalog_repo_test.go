@@ -0,0 +1,332 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestCatalogRepository_GetItemByName(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewCatalogRepository(dbMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+
+	testItem := &model.Item{
+		Name:        "hoody",
+		Price:       300,
+		Description: "warm hoody",
+		Active:      true,
+	}
+
+	t.Run("Successful item retrieval", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"hoody"}).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan",
+			mock.AnythingOfType("*string"),
+			mock.AnythingOfType("*int"),
+			mock.AnythingOfType("*string"),
+			mock.AnythingOfType("*bool"),
+			mock.AnythingOfType("*time.Time"),
+			mock.AnythingOfType("*time.Time"),
+		).Run(func(args mock.Arguments) {
+			*args[0].(*string) = testItem.Name
+			*args[1].(*int) = testItem.Price
+			*args[2].(*string) = testItem.Description
+			*args[3].(*bool) = testItem.Active
+			*args[4].(*time.Time) = testItem.CreatedAt
+			*args[5].(*time.Time) = testItem.UpdatedAt
+		}).Return(nil).Once()
+
+		item, err := repo.GetItemByName(ctx, "hoody")
+		assert.NoError(t, err)
+		assert.Equal(t, testItem, item)
+		dbMock.AssertExpectations(t)
+		rowMock.AssertExpectations(t)
+	})
+
+	t.Run("Item not found", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"sword"}).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan",
+			mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything,
+		).Return(pgx.ErrNoRows).Once()
+
+		item, err := repo.GetItemByName(ctx, "sword")
+		assert.NoError(t, err)
+		assert.Nil(t, item)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"cup"}).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan",
+			mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything,
+		).Return(model.ErrInternalError).Once()
+
+		item, err := repo.GetItemByName(ctx, "cup")
+		assert.Nil(t, item)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestCatalogRepository_GetItemForShareTx(t *testing.T) {
+	repo := NewCatalogRepository(new(mocks.DBMock))
+	txMock := new(mocks.TxMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+
+	t.Run("Item locked", func(t *testing.T) {
+		txMock.On("QueryRow", ctx, mock.MatchedBy(func(sql string) bool {
+			return strings.HasSuffix(sql, "FOR SHARE")
+		}), []interface{}{"hoody"}).Return(rowMock).Once()
+
+		rowMock.On("Scan",
+			mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything,
+		).Run(func(args mock.Arguments) {
+			*args[0].(*string) = "hoody"
+			*args[1].(*int) = 300
+			*args[3].(*bool) = true
+		}).Return(nil).Once()
+
+		item, err := repo.GetItemForShareTx(ctx, txMock, "hoody")
+		assert.NoError(t, err)
+		assert.Equal(t, &model.Item{Name: "hoody", Price: 300, Active: true}, item)
+		txMock.AssertExpectations(t)
+	})
+
+	t.Run("Item not found", func(t *testing.T) {
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"sword"}).Return(rowMock).Once()
+
+		rowMock.On("Scan",
+			mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything,
+		).Return(pgx.ErrNoRows).Once()
+
+		item, err := repo.GetItemForShareTx(ctx, txMock, "sword")
+		assert.NoError(t, err)
+		assert.Nil(t, item)
+	})
+}
+
+func TestCatalogRepository_ListItems(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewCatalogRepository(dbMock)
+	ctx := context.Background()
+
+	t.Run("Successful listing", func(t *testing.T) {
+		rowsMock := new(mocks.PgxRowsMock)
+		expectedItems := []model.Item{
+			{Name: "pen", Price: 10, Active: true},
+			{Name: "cup", Price: 20, Active: true},
+		}
+
+		dbMock.On("Query", ctx, mock.Anything, []interface{}{100}).
+			Return(rowsMock, nil).Once()
+
+		rowsMock.On("Next").Return(true).Twice()
+		rowsMock.On("Next").Return(false).Once()
+		rowsMock.On("Scan",
+			mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything,
+		).Run(func(args mock.Arguments) {
+			*args[0].(*string) = expectedItems[0].Name
+			*args[1].(*int) = expectedItems[0].Price
+			*args[3].(*bool) = expectedItems[0].Active
+			expectedItems = expectedItems[1:]
+		}).Return(nil).Twice()
+		rowsMock.On("Err").Return(nil).Once()
+		rowsMock.On("Close").Return(nil).Once()
+
+		items, err := repo.ListItems(ctx, "price", 100)
+		assert.NoError(t, err)
+		assert.Len(t, items, 2)
+		assert.Equal(t, "pen", items[0].Name)
+	})
+
+	t.Run("Unknown ordering", func(t *testing.T) {
+		items, err := repo.ListItems(ctx, "weight", -1)
+		assert.Nil(t, items)
+		assert.ErrorIs(t, err, model.ErrInvalidRequest)
+	})
+
+	t.Run("Query execution error", func(t *testing.T) {
+		rowsMock := new(mocks.PgxRowsMock)
+
+		dbMock.On("Query", ctx, mock.Anything, []interface{}{-1}).
+			Return(rowsMock, model.ErrInternalError).Once()
+
+		items, err := repo.ListItems(ctx, "", -1)
+		assert.Nil(t, items)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+
+	t.Run("Row scanning error", func(t *testing.T) {
+		rowsMock := new(mocks.PgxRowsMock)
+
+		dbMock.On("Query", ctx, mock.Anything, []interface{}{-1}).
+			Return(rowsMock, nil).Once()
+
+		rowsMock.On("Next").Return(true).Once()
+		rowsMock.On("Scan",
+			mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything,
+		).Return(model.ErrInternalError).Once()
+		rowsMock.On("Close").Return(nil).Once()
+
+		items, err := repo.ListItems(ctx, "-name", -1)
+		assert.Nil(t, items)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+
+	t.Run("Interrupted iteration", func(t *testing.T) {
+		rowsMock := new(mocks.PgxRowsMock)
+
+		dbMock.On("Query", ctx, mock.Anything, []interface{}{-1}).
+			Return(rowsMock, nil).Once()
+
+		rowsMock.On("Next").Return(false).Once()
+		rowsMock.On("Err").Return(model.ErrInternalError).Once()
+		rowsMock.On("Close").Return(nil).Once()
+
+		items, err := repo.ListItems(ctx, "", -1)
+		assert.Nil(t, items)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestCatalogRepository_CreateItem(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewCatalogRepository(dbMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+
+	t.Run("Successful item creation", func(t *testing.T) {
+		item := &model.Item{Name: "scarf", Price: 120, Description: "winter"}
+
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"scarf", 120, "winter"}).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				*args[0].(*bool) = true
+			}).Return(nil).Once()
+
+		err := repo.CreateItem(ctx, item)
+		assert.NoError(t, err)
+		assert.True(t, item.Active)
+	})
+
+	t.Run("Duplicate item", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, mock.Anything).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan", mock.Anything, mock.Anything, mock.Anything).
+			Return(&pgconn.PgError{Code: "23505"}).Once()
+
+		err := repo.CreateItem(ctx, &model.Item{Name: "cup", Price: 20})
+		assert.ErrorIs(t, err, model.ErrItemExists)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, mock.Anything).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan", mock.Anything, mock.Anything, mock.Anything).
+			Return(model.ErrInternalError).Once()
+
+		err := repo.CreateItem(ctx, &model.Item{Name: "mug", Price: 20})
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestCatalogRepository_UpdateItemPrice(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewCatalogRepository(dbMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+
+	t.Run("Successful repricing", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"cup", 25}).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan",
+			mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything,
+		).Run(func(args mock.Arguments) {
+			*args[0].(*string) = "cup"
+			*args[1].(*int) = 25
+		}).Return(nil).Once()
+
+		item, err := repo.UpdateItemPrice(ctx, "cup", 25)
+		assert.NoError(t, err)
+		assert.Equal(t, 25, item.Price)
+	})
+
+	t.Run("Item not found", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"sword", 25}).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan",
+			mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything,
+		).Return(pgx.ErrNoRows).Once()
+
+		item, err := repo.UpdateItemPrice(ctx, "sword", 25)
+		assert.Nil(t, item)
+		assert.ErrorIs(t, err, model.ErrItemNotFound)
+	})
+}
+
+func TestCatalogRepository_DeactivateItem(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewCatalogRepository(dbMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+
+	t.Run("Successful deactivation", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"cup"}).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan",
+			mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything,
+		).Run(func(args mock.Arguments) {
+			*args[0].(*string) = "cup"
+			*args[3].(*bool) = false
+		}).Return(nil).Once()
+
+		item, err := repo.DeactivateItem(ctx, "cup")
+		assert.NoError(t, err)
+		assert.False(t, item.Active)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"cup"}).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan",
+			mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything,
+		).Return(model.ErrInternalError).Once()
+
+		item, err := repo.DeactivateItem(ctx, "cup")
+		assert.Nil(t, item)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
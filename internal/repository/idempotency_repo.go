@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+)
+
+// Interface for idempotency key repository, needed for testing
+type IdempotencyRepositoryInt interface {
+	Reserve(ctx context.Context, userID, key, requestHash string, ttl, lease time.Duration) (string, *model.IdempotencyRecord, error)
+	Complete(ctx context.Context, userID, key, token string, status int, contentType string, body []byte) error
+	Release(ctx context.Context, userID, key, token string) error
+}
+
+// Idempotency key repository for stored responses of retried requests
+type IdempotencyRepository struct {
+	pool DB
+}
+
+// Constructor for idempotency key repository
+func NewIdempotencyRepository(db DB) *IdempotencyRepository {
+	return &IdempotencyRepository{pool: db}
+}
+
+// Function that reserves key of user for request with requestHash for ttl, expired keys and keys of requests
+// still pending after lease are reused. Returns token of reservation when key is reserved for caller, otherwise
+// record left by earlier request, and error
+func (r IdempotencyRepository) Reserve(
+	ctx context.Context,
+	userID, key, requestHash string,
+	ttl, lease time.Duration,
+) (string, *model.IdempotencyRecord, error) {
+	var token string
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO idempotency_keys (user_id, key, request_hash, expires_at)
+         VALUES ($1, $2, $3, CURRENT_TIMESTAMP + make_interval(secs => $4))
+         ON CONFLICT (user_id, key) DO UPDATE
+         SET request_hash = excluded.request_hash, status_code = NULL, content_type = NULL, response = NULL,
+             token = excluded.token, created_at = CURRENT_TIMESTAMP, expires_at = excluded.expires_at
+         WHERE idempotency_keys.expires_at < CURRENT_TIMESTAMP
+            OR (idempotency_keys.status_code IS NULL
+                AND idempotency_keys.created_at < CURRENT_TIMESTAMP - make_interval(secs => $5))
+         RETURNING token`,
+		userID, key, requestHash, ttl.Seconds(), lease.Seconds(),
+	).Scan(&token)
+	if err == nil {
+		return token, nil, nil
+	}
+	if err != pgx.ErrNoRows {
+		log.Printf("Database error: %v", err)
+		return "", nil, err
+	}
+
+	var record model.IdempotencyRecord
+	err = r.pool.QueryRow(ctx,
+		`SELECT request_hash, COALESCE(status_code, 0), COALESCE(content_type, ''), COALESCE(response, ''::bytea)
+         FROM idempotency_keys
+         WHERE user_id = $1 AND key = $2`,
+		userID, key,
+	).Scan(&record.RequestHash, &record.StatusCode, &record.ContentType, &record.Body)
+	if err != nil {
+		// Key was released by failed request in between, caller has to retry
+		if err == pgx.ErrNoRows {
+			return "", &model.IdempotencyRecord{RequestHash: requestHash}, nil
+		}
+		log.Printf("Database error: %v", err)
+		return "", nil, err
+	}
+	return "", &record, nil
+}
+
+// Function that stores response of request that reserved key of user with token, nothing is stored once
+// the key was taken over by another request after lease, returns error
+func (r IdempotencyRepository) Complete(
+	ctx context.Context,
+	userID, key, token string,
+	status int,
+	contentType string,
+	body []byte,
+) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE idempotency_keys
+         SET status_code = $4, content_type = $5, response = $6
+         WHERE user_id = $1 AND key = $2 AND token = $3 AND status_code IS NULL`,
+		userID, key, token, status, contentType, body,
+	)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Function that frees key of user reserved with token after failed request so that it can be retried,
+// reservation of another request that took over the key is kept, returns error
+func (r IdempotencyRepository) Release(ctx context.Context, userID, key, token string) error {
+	_, err := r.pool.Exec(ctx,
+		"DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2 AND token = $3 AND status_code IS NULL",
+		userID, key, token,
+	)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return err
+	}
+	return nil
+}
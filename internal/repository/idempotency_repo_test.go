@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestIdempotencyRepository_Reserve(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewIdempotencyRepository(dbMock)
+	ctx := context.Background()
+
+	t.Run("New key reserved", func(t *testing.T) {
+		rowMock := new(mocks.PgxRowMock)
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user1", "key1", "hash1", float64(3600), float64(60)}).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).Run(func(args mock.Arguments) {
+			*args[0].(*string) = "token1"
+		}).Return(nil).Once()
+
+		token, record, err := repo.Reserve(ctx, "user1", "key1", "hash1", time.Hour, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, "token1", token)
+		assert.Nil(t, record)
+	})
+
+	t.Run("Existing key returns stored response", func(t *testing.T) {
+		insertRow := new(mocks.PgxRowMock)
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user1", "key1", "hash1", float64(3600), float64(60)}).
+			Return(insertRow).Once()
+		insertRow.On("Scan", mock.Anything).Return(pgx.ErrNoRows).Once()
+
+		selectRow := new(mocks.PgxRowMock)
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user1", "key1"}).Return(selectRow).Once()
+		selectRow.On("Scan", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				*args[0].(*string) = "hash1"
+				*args[1].(*int) = 200
+				*args[2].(*string) = "application/json"
+				*args[3].(*[]byte) = []byte(`{}`)
+			}).Return(nil).Once()
+
+		token, record, err := repo.Reserve(ctx, "user1", "key1", "hash1", time.Hour, time.Minute)
+		assert.NoError(t, err)
+		assert.Empty(t, token)
+		assert.Equal(t, &model.IdempotencyRecord{
+			RequestHash: "hash1", StatusCode: 200, ContentType: "application/json", Body: []byte(`{}`),
+		}, record)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		rowMock := new(mocks.PgxRowMock)
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user1", "key2", "hash1", float64(3600), float64(60)}).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).Return(model.ErrInternalError).Once()
+
+		_, _, err := repo.Reserve(ctx, "user1", "key2", "hash1", time.Hour, time.Minute)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestIdempotencyRepository_CompleteAndRelease(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewIdempotencyRepository(dbMock)
+	ctx := context.Background()
+
+	t.Run("Complete", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user1", "key1", "token1", 200, "application/json", []byte(`{}`)}).
+			Return(pgconn.NewCommandTag("UPDATE 1"), nil).Once()
+
+		assert.NoError(t, repo.Complete(ctx, "user1", "key1", "token1", 200, "application/json", []byte(`{}`)))
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user1", "key1", "token1"}).
+			Return(pgconn.NewCommandTag("DELETE 1"), nil).Once()
+
+		assert.NoError(t, repo.Release(ctx, "user1", "key1", "token1"))
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user1", "key2", "token2"}).
+			Return(pgconn.CommandTag{}, model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, repo.Release(ctx, "user1", "key2", "token2"), model.ErrInternalError)
+	})
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+)
+
+// Interface for invite repository, needed for testing
+type InviteRepositoryInt interface {
+	CreateInvite(ctx context.Context, invite *model.Invite) error
+	UseInviteTx(ctx context.Context, tx pgx.Tx, codeHash, userID string) (bool, error)
+}
+
+// Invite repository for invite code manipulations
+type InviteRepository struct {
+	pool DB
+}
+
+// Constructor for invite repository
+func NewInviteRepository(db DB) *InviteRepository {
+	return &InviteRepository{pool: db}
+}
+
+// Function that writes invite to database and assigns its ID, returns error
+func (r InviteRepository) CreateInvite(ctx context.Context, invite *model.Invite) error {
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO invites (code_hash, created_by, expires_at)
+         VALUES ($1, $2, $3)
+         RETURNING id`,
+		invite.CodeHash, invite.CreatedBy, invite.ExpiresAt,
+	).Scan(&invite.ID)
+	if err != nil {
+		log.Printf("Error creating invite: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Function that marks invite as used by user with userID during transaction if it is still usable,
+// so every invite can be used only once, returns false if invite is unknown, used or expired, and error
+func (r InviteRepository) UseInviteTx(ctx context.Context, tx pgx.Tx, codeHash, userID string) (bool, error) {
+	tag, err := tx.Exec(ctx,
+		`UPDATE invites SET used_by = $2, used_at = CURRENT_TIMESTAMP
+         WHERE code_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP`,
+		codeHash, userID,
+	)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestInviteRepository_CreateInvite(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewInviteRepository(dbMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	t.Run("Successful creation", func(t *testing.T) {
+		invite := &model.Invite{CodeHash: "hash", CreatedBy: "admin", ExpiresAt: expiresAt}
+
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"hash", "admin", expiresAt}).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).
+			Run(func(args mock.Arguments) {
+				*args[0].(*string) = "invite1"
+			}).Return(nil).Once()
+
+		assert.NoError(t, repo.CreateInvite(ctx, invite))
+		assert.Equal(t, "invite1", invite.ID)
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, mock.Anything).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).
+			Return(model.ErrInternalError).Once()
+
+		err := repo.CreateInvite(ctx, &model.Invite{CodeHash: "hash2"})
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestInviteRepository_UseInviteTx(t *testing.T) {
+	repo := NewInviteRepository(new(mocks.DBMock))
+	txMock := new(mocks.TxMock)
+	ctx := context.Background()
+
+	t.Run("Usable invite", func(t *testing.T) {
+		txMock.On("Exec", ctx, mock.Anything, []interface{}{"hash", "user1"}).
+			Return(pgconn.NewCommandTag("UPDATE 1"), nil).Once()
+
+		ok, err := repo.UseInviteTx(ctx, txMock, "hash", "user1")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		txMock.AssertExpectations(t)
+	})
+
+	t.Run("Used or unknown invite", func(t *testing.T) {
+		txMock.On("Exec", ctx, mock.Anything, []interface{}{"used", "user1"}).
+			Return(pgconn.NewCommandTag("UPDATE 0"), nil).Once()
+
+		ok, err := repo.UseInviteTx(ctx, txMock, "used", "user1")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		txMock.On("Exec", ctx, mock.Anything, []interface{}{"hash", "user2"}).
+			Return(pgconn.CommandTag{}, model.ErrInternalError).Once()
+
+		ok, err := repo.UseInviteTx(ctx, txMock, "hash", "user2")
+		assert.ErrorIs(t, err, model.ErrInternalError)
+		assert.False(t, ok)
+	})
+}
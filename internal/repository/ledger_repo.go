@@ -0,0 +1,393 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+)
+
+// Interface for ledger repository, needed for testing
+type LedgerRepositoryInt interface {
+	PostTx(ctx context.Context, tx pgx.Tx, posting *model.Posting) error
+	AdjustBalance(ctx context.Context, userID string, amount int, reason string) (int, error)
+	GetBalanceReports(ctx context.Context) ([]model.BalanceReport, error)
+	ReconcileBalance(ctx context.Context, userID, reason string) (int, error)
+	GetActivity(ctx context.Context, userID string, filter model.ActivityFilter) (*model.ActivityPage, error)
+	StreamStatement(ctx context.Context, userID string, from, to *time.Time, w model.StatementWriter) error
+}
+
+// Ledger repository, the only way coins move between accounts
+type LedgerRepository struct {
+	pool DB
+}
+
+// Constructor for ledger repository
+func NewLedgerRepository(db DB) *LedgerRepository {
+	return &LedgerRepository{pool: db}
+}
+
+// Query that writes posting with all its entries in one statement, balances of users are updated by trigger
+const postingQuery = `WITH posting AS (
+             INSERT INTO ledger_postings (kind, reason) VALUES ($1, $2) RETURNING id
+         )
+         INSERT INTO ledger_entries (posting_id, user_id, system_account, amount)
+         SELECT posting.id, NULLIF(e.user_id, '')::uuid, NULLIF(e.system_account, ''), e.amount
+         FROM posting, unnest($3::text[], $4::text[], $5::int[]) AS e(user_id, system_account, amount)`
+
+// Function that writes posting during transaction, returns ErrUnbalancedPosting if entries do not sum up to zero
+// and error
+func (r LedgerRepository) PostTx(ctx context.Context, tx pgx.Tx, posting *model.Posting) error {
+	return postTx(ctx, tx, posting)
+}
+
+// Function that adds amount of coins to user with userID, or takes them if amount is negative, recording reason,
+// returns new balance, ErrUserNotFound, ErrInsufficientFunds if balance would become negative, and error
+func (r LedgerRepository) AdjustBalance(ctx context.Context, userID string, amount int, reason string) (int, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var balance int
+	err = tx.QueryRow(ctx, "SELECT coins FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&balance)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, model.ErrUserNotFound
+		}
+		log.Printf("Database error: %v", err)
+		return 0, err
+	}
+	if balance+amount < 0 {
+		return 0, model.ErrInsufficientFunds
+	}
+
+	if err := postTx(ctx, tx, model.AdjustmentPosting(userID, amount, reason)); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Transaction commit error: %v", err)
+		return 0, err
+	}
+	return balance + amount, nil
+}
+
+// Query that collects cached balance and ledger total of every user, or only of user $1, together with
+// movements from records kept apart from the ledger: transfers from transactions, purchases and refunds
+// from orders. Grants and adjustments are recorded only in the ledger, so they are taken from there
+const balanceReportQuery = `SELECT u.id, u.username, u.coins,
+                COALESCE(l.total, 0), COALESCE(l.granted, 0), COALESCE(l.adjusted, 0), COALESCE(l.corrected, 0),
+                COALESCE(tr.received, 0), COALESCE(ts.sent, 0), COALESCE(o.spent, 0), COALESCE(o.refunded, 0)
+         FROM users u
+         LEFT JOIN (
+             SELECT e.user_id,
+                    SUM(e.amount) AS total,
+                    SUM(e.amount) FILTER (WHERE p.kind = 'grant') AS granted,
+                    SUM(e.amount) FILTER (WHERE p.kind = 'adjustment') AS adjusted,
+                    SUM(e.amount) FILTER (WHERE p.kind = 'correction') AS corrected
+             FROM ledger_entries e
+             JOIN ledger_postings p ON p.id = e.posting_id
+             WHERE e.user_id IS NOT NULL
+             GROUP BY e.user_id
+         ) l ON l.user_id = u.id
+         LEFT JOIN (
+             SELECT to_user_id, SUM(amount) AS received FROM transactions GROUP BY to_user_id
+         ) tr ON tr.to_user_id = u.id
+         LEFT JOIN (
+             SELECT from_user_id, SUM(amount) AS sent FROM transactions GROUP BY from_user_id
+         ) ts ON ts.from_user_id = u.id
+         LEFT JOIN (
+             SELECT user_id,
+                    SUM(unit_price * quantity) AS spent,
+                    SUM(unit_price * quantity) FILTER (WHERE status = 'refunded') AS refunded
+             FROM orders
+             GROUP BY user_id
+         ) o ON o.user_id = u.id
+         WHERE $1::uuid IS NULL OR u.id = $1
+         ORDER BY u.username`
+
+// Destinations for a row of balanceReportQuery
+func balanceReportDest(b *model.BalanceReport) []any {
+	return []any{&b.UserID, &b.Username, &b.Coins,
+		&b.Ledger, &b.Granted, &b.Adjusted, &b.Corrected, &b.Received, &b.Sent, &b.Spent, &b.Refunded}
+}
+
+// Function that collects balance reports of every user, Expected and differences are computed,
+// returns reports and error
+func (r LedgerRepository) GetBalanceReports(ctx context.Context) ([]model.BalanceReport, error) {
+	rows, err := r.pool.Query(ctx, balanceReportQuery, nil)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := make([]model.BalanceReport, 0)
+	for rows.Next() {
+		var b model.BalanceReport
+		if err := rows.Scan(balanceReportDest(&b)...); err != nil {
+			log.Printf("Database error: %v", err)
+			return nil, err
+		}
+		b.Compute()
+		reports = append(reports, b)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	return reports, nil
+}
+
+// Function that writes correction posting with reason bringing ledger of user with userID to expected balance,
+// cached balance follows it by trigger. User is locked first, so no movement of the user happens while
+// the balance is recomputed, returns written correction and error
+func (r LedgerRepository) ReconcileBalance(ctx context.Context, userID, reason string) (int, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id string
+	if err := tx.QueryRow(ctx, "SELECT id FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&id); err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, model.ErrUserNotFound
+		}
+		log.Printf("Database error: %v", err)
+		return 0, err
+	}
+
+	var b model.BalanceReport
+	if err := tx.QueryRow(ctx, balanceReportQuery, userID).Scan(balanceReportDest(&b)...); err != nil {
+		log.Printf("Database error: %v", err)
+		return 0, err
+	}
+	b.Compute()
+
+	correction := -b.LedgerDifference
+	if correction == 0 {
+		return 0, nil
+	}
+
+	if err := postTx(ctx, tx, model.CorrectionPosting(userID, correction, reason)); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Transaction commit error: %v", err)
+		return 0, err
+	}
+	return correction, nil
+}
+
+// Function that reads one page of ledger entries of user with userID newest first, balance after every entry
+// is the sum of entries of user before the oldest one on the page plus running sum over id range of the page,
+// so it is right for filtered pages too and older history is summed once through index, returns page and error
+func (r LedgerRepository) GetActivity(ctx context.Context, userID string, filter model.ActivityFilter) (*model.ActivityPage, error) {
+	var cursor any
+	if filter.Cursor > 0 {
+		cursor = filter.Cursor
+	}
+
+	// One extra row tells whether there is a next page
+	rows, err := r.pool.Query(ctx,
+		`WITH page AS (
+             SELECT id, posting_id, amount, created_at
+             FROM ledger_entries
+             WHERE user_id = $1
+               AND ($2::bigint IS NULL OR id < $2)
+               AND ($3::timestamp IS NULL OR created_at >= $3)
+               AND ($4::timestamp IS NULL OR created_at < $4)
+             ORDER BY id DESC
+             LIMIT $5
+         ), bounds AS (
+             SELECT MIN(id) AS first_id, MAX(id) AS last_id FROM page
+         ), opening AS (
+             SELECT COALESCE(SUM(e.amount), 0) AS balance
+             FROM ledger_entries e, bounds b
+             WHERE e.user_id = $1 AND e.id < b.first_id
+         ), running AS (
+             SELECT e.id, SUM(e.amount) OVER (ORDER BY e.id) AS balance
+             FROM ledger_entries e, bounds b
+             WHERE e.user_id = $1 AND e.id BETWEEN b.first_id AND b.last_id
+         )
+         SELECT a.id, p.kind, a.amount, o.balance + r.balance, COALESCE(c.username, ''), p.reason, a.created_at
+         FROM page a
+         JOIN running r ON r.id = a.id
+         CROSS JOIN opening o
+         JOIN ledger_postings p ON p.id = a.posting_id
+         LEFT JOIN LATERAL (
+             SELECT u.username
+             FROM ledger_entries x
+             JOIN users u ON u.id = x.user_id
+             WHERE x.posting_id = a.posting_id AND x.user_id <> $1
+             LIMIT 1
+         ) c ON TRUE
+         ORDER BY a.id DESC`,
+		userID, cursor, filter.From, filter.To, filter.Limit+1,
+	)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &model.ActivityPage{Events: make([]model.ActivityEvent, 0, filter.Limit)}
+	for rows.Next() {
+		var e model.ActivityEvent
+		var reason string
+		err := rows.Scan(&e.ID, &e.Kind, &e.Amount, &e.Balance, &e.Counterparty, &reason, &e.Timestamp)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			return nil, err
+		}
+		setPostingReason(&e, reason)
+		page.Events = append(page.Events, e)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+
+	if len(page.Events) > filter.Limit {
+		page.Events = page.Events[:filter.Limit]
+		page.NextCursor = strconv.FormatInt(page.Events[len(page.Events)-1].ID, 10)
+	}
+	return page, nil
+}
+
+// Function that reads movements of user with userID during period together with balance at its start and passes
+// them to w one by one, so statement of any length is never held in memory, both are read from the same snapshot,
+// returns error
+func (r LedgerRepository) StreamStatement(ctx context.Context, userID string, from, to *time.Time, w model.StatementWriter) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	balance := 0
+	if from != nil {
+		err := tx.QueryRow(ctx,
+			"SELECT COALESCE(SUM(amount), 0) FROM ledger_entries WHERE user_id = $1 AND created_at < $2",
+			userID, from,
+		).Scan(&balance)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			return err
+		}
+	}
+	if err := w.Opening(balance); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT e.id, p.kind, e.amount, COALESCE(c.username, ''), p.reason, e.created_at
+         FROM ledger_entries e
+         JOIN ledger_postings p ON p.id = e.posting_id
+         LEFT JOIN LATERAL (
+             SELECT u.username
+             FROM ledger_entries o
+             JOIN users u ON u.id = o.user_id
+             WHERE o.posting_id = e.posting_id AND o.user_id <> $1
+             LIMIT 1
+         ) c ON TRUE
+         WHERE e.user_id = $1
+           AND ($2::timestamp IS NULL OR e.created_at >= $2)
+           AND ($3::timestamp IS NULL OR e.created_at < $3)
+         ORDER BY e.created_at, e.id`,
+		userID, from, to,
+	)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e model.ActivityEvent
+		var reason string
+		if err := rows.Scan(&e.ID, &e.Kind, &e.Amount, &e.Counterparty, &reason, &e.Timestamp); err != nil {
+			log.Printf("Database error: %v", err)
+			return err
+		}
+		setPostingReason(&e, reason)
+		balance += e.Amount
+		e.Balance = balance
+
+		if err := w.Movement(e); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Database error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Fills reason of event, purchase postings carry name of the item bought in place of reason
+func setPostingReason(e *model.ActivityEvent, reason string) {
+	if e.Kind == model.PostingPurchase {
+		e.Item = reason
+	} else {
+		e.Reason = reason
+	}
+}
+
+// Writes posting during transaction, shared by repositories that move coins
+func postTx(ctx context.Context, tx pgx.Tx, posting *model.Posting) error {
+	if !posting.Balanced() {
+		return model.ErrUnbalancedPosting
+	}
+
+	if _, err := tx.Exec(ctx, postingQuery, postingArgs(posting)...); err != nil {
+		if isNegativeBalance(err) {
+			return model.ErrInsufficientFunds
+		}
+		log.Printf("Database error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Reports whether err is violation of constraint that keeps balance of user non-negative
+func isNegativeBalance(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23514" && pgErr.ConstraintName == "users_coins_non_negative"
+}
+
+// Queues posting into batch, shared by repositories that move coins in batches
+func queuePosting(batch *pgx.Batch, posting *model.Posting) error {
+	if !posting.Balanced() {
+		return model.ErrUnbalancedPosting
+	}
+
+	batch.Queue(postingQuery, postingArgs(posting)...)
+	return nil
+}
+
+// Arguments of postingQuery, entries are passed as parallel arrays
+func postingArgs(posting *model.Posting) []any {
+	userIDs := make([]string, len(posting.Entries))
+	accounts := make([]string, len(posting.Entries))
+	amounts := make([]int32, len(posting.Entries))
+	for i, entry := range posting.Entries {
+		userIDs[i] = entry.UserID
+		accounts[i] = entry.SystemAccount
+		amounts[i] = int32(entry.Amount)
+	}
+	return []any{posting.Kind, posting.Reason, userIDs, accounts, amounts}
+}
@@ -0,0 +1,421 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestLedgerRepository_PostTx(t *testing.T) {
+	repo := NewLedgerRepository(new(mocks.DBMock))
+	txMock := new(mocks.TxMock)
+	ctx := context.Background()
+
+	t.Run("Successful posting", func(t *testing.T) {
+		txMock.On("Exec", ctx, postingQuery, []interface{}{
+			model.PostingTransfer, "", []string{"user1", "user2"}, []string{"", ""}, []int32{-100, 100},
+		}).Return(pgconn.CommandTag{}, nil).Once()
+
+		assert.NoError(t, repo.PostTx(ctx, txMock, model.TransferPosting("user1", "user2", 100)))
+		txMock.AssertExpectations(t)
+	})
+
+	t.Run("System account entry", func(t *testing.T) {
+		txMock.On("Exec", ctx, postingQuery, []interface{}{
+			model.PostingPurchase, "hoody", []string{"user1", ""}, []string{"", model.AccountShop}, []int32{-300, 300},
+		}).Return(pgconn.CommandTag{}, nil).Once()
+
+		assert.NoError(t, repo.PostTx(ctx, txMock, model.PurchasePosting("user1", 300, "hoody")))
+		txMock.AssertExpectations(t)
+	})
+
+	t.Run("Balance would become negative", func(t *testing.T) {
+		txMock.On("Exec", ctx, postingQuery, postingArgs(model.PurchasePosting("user1", 300, "hoody"))).
+			Return(pgconn.CommandTag{}, &pgconn.PgError{Code: "23514", ConstraintName: "users_coins_non_negative"}).Once()
+
+		err := repo.PostTx(ctx, txMock, model.PurchasePosting("user1", 300, "hoody"))
+		assert.ErrorIs(t, err, model.ErrInsufficientFunds)
+	})
+
+	t.Run("Unbalanced posting", func(t *testing.T) {
+		posting := &model.Posting{
+			Kind: model.PostingGrant,
+			Entries: []model.LedgerEntry{
+				{UserID: "user1", Amount: 1000},
+				{SystemAccount: model.AccountIssuance, Amount: -999},
+			},
+		}
+
+		assert.ErrorIs(t, repo.PostTx(ctx, txMock, posting), model.ErrUnbalancedPosting)
+	})
+
+	t.Run("Entry without account", func(t *testing.T) {
+		posting := &model.Posting{
+			Kind: model.PostingGrant,
+			Entries: []model.LedgerEntry{
+				{UserID: "user1", Amount: 1000},
+				{Amount: -1000},
+			},
+		}
+
+		assert.ErrorIs(t, repo.PostTx(ctx, txMock, posting), model.ErrUnbalancedPosting)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		txMock.On("Exec", ctx, postingQuery, mock.Anything).
+			Return(pgconn.CommandTag{}, model.ErrInternalError).Once()
+
+		err := repo.PostTx(ctx, txMock, model.GrantPosting("user1", 1000))
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestLedgerRepository_AdjustBalance(t *testing.T) {
+	poolMock := new(mocks.DBMock)
+	repo := NewLedgerRepository(poolMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+
+	beginWithBalance := func(balance int, err error) *mocks.TxMock {
+		txMock := new(mocks.TxMock)
+		poolMock.On("BeginTx", ctx, pgx.TxOptions{}).Return(txMock, nil).Once()
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user1"}).Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).Run(func(args mock.Arguments) {
+			*args[0].(*int) = balance
+		}).Return(err).Once()
+		txMock.On("Rollback", ctx).Return(nil).Once()
+		return txMock
+	}
+
+	t.Run("Successful adjustment", func(t *testing.T) {
+		txMock := beginWithBalance(1000, nil)
+		txMock.On("Exec", ctx, postingQuery, postingArgs(model.AdjustmentPosting("user1", -200, "refund"))).
+			Return(pgconn.CommandTag{}, nil).Once()
+		txMock.On("Commit", ctx).Return(nil).Once()
+
+		balance, err := repo.AdjustBalance(ctx, "user1", -200, "refund")
+		assert.NoError(t, err)
+		assert.Equal(t, 800, balance)
+		txMock.AssertExpectations(t)
+	})
+
+	t.Run("Balance would become negative", func(t *testing.T) {
+		txMock := beginWithBalance(100, nil)
+
+		_, err := repo.AdjustBalance(ctx, "user1", -200, "refund")
+		assert.ErrorIs(t, err, model.ErrInsufficientFunds)
+		txMock.AssertNotCalled(t, "Commit", ctx)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		beginWithBalance(0, pgx.ErrNoRows)
+
+		_, err := repo.AdjustBalance(ctx, "user1", 200, "bonus")
+		assert.ErrorIs(t, err, model.ErrUserNotFound)
+	})
+
+	t.Run("Transaction start error", func(t *testing.T) {
+		poolMock.On("BeginTx", ctx, pgx.TxOptions{}).Return(new(mocks.TxMock), pgx.ErrTxClosed).Once()
+
+		_, err := repo.AdjustBalance(ctx, "user1", 200, "bonus")
+		assert.ErrorIs(t, err, pgx.ErrTxClosed)
+	})
+}
+
+// Scan arguments of balance report row, in order of balanceReportDest
+var balanceReportScan = []interface{}{mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+	mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything}
+
+// Fills balance report row with cached balance, ledger total, granted coins and coins spent on orders
+func scanBalanceReport(coins, ledger, granted, spent int) func(args mock.Arguments) {
+	return func(args mock.Arguments) {
+		*args[0].(*string) = "user1"
+		*args[1].(*string) = "alice"
+		*args[2].(*int) = coins
+		*args[3].(*int) = ledger
+		*args[4].(*int) = granted
+		*args[9].(*int) = spent
+	}
+}
+
+func TestLedgerRepository_GetBalanceReports(t *testing.T) {
+	poolMock := new(mocks.DBMock)
+	repo := NewLedgerRepository(poolMock)
+	ctx := context.Background()
+
+	t.Run("Successful report", func(t *testing.T) {
+		rowsMock := new(mocks.PgxRowsMock)
+		poolMock.On("Query", ctx, balanceReportQuery, []interface{}{nil}).Return(rowsMock, nil).Once()
+
+		rowsMock.On("Next").Return(true).Once()
+		rowsMock.On("Next").Return(false).Once()
+		rowsMock.On("Scan", balanceReportScan...).Run(scanBalanceReport(800, 750, 1000, 200)).Return(nil).Once()
+		rowsMock.On("Err").Return(nil).Once()
+		rowsMock.On("Close").Return().Once()
+
+		reports, err := repo.GetBalanceReports(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.BalanceReport{{
+			UserID: "user1", Username: "alice", Coins: 800, Ledger: 750, Granted: 1000, Spent: 200,
+			Expected: 800, LedgerDifference: -50, CacheDrift: 50,
+		}}, reports)
+		assert.True(t, reports[0].Mismatched())
+		rowsMock.AssertExpectations(t)
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		poolMock.On("Query", ctx, balanceReportQuery, []interface{}{nil}).
+			Return(new(mocks.PgxRowsMock), model.ErrInternalError).Once()
+
+		_, err := repo.GetBalanceReports(ctx)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestLedgerRepository_ReconcileBalance(t *testing.T) {
+	poolMock := new(mocks.DBMock)
+	repo := NewLedgerRepository(poolMock)
+	ctx := context.Background()
+
+	// Expects transaction that locks user and reads report with given balances
+	beginWithReport := func(coins, ledger, granted, spent int) *mocks.TxMock {
+		txMock := new(mocks.TxMock)
+		lockRow := new(mocks.PgxRowMock)
+		reportRow := new(mocks.PgxRowMock)
+		poolMock.On("BeginTx", ctx, pgx.TxOptions{}).Return(txMock, nil).Once()
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user1"}).Return(lockRow).Once()
+		lockRow.On("Scan", mock.Anything).Return(nil).Once()
+		txMock.On("QueryRow", ctx, balanceReportQuery, []interface{}{"user1"}).Return(reportRow).Once()
+		reportRow.On("Scan", balanceReportScan...).Run(scanBalanceReport(coins, ledger, granted, spent)).Return(nil).Once()
+		txMock.On("Rollback", ctx).Return(nil).Once()
+		return txMock
+	}
+
+	t.Run("Successful correction", func(t *testing.T) {
+		txMock := beginWithReport(900, 900, 1000, 0)
+		txMock.On("Exec", ctx, postingQuery, postingArgs(model.CorrectionPosting("user1", 100, "audit"))).
+			Return(pgconn.CommandTag{}, nil).Once()
+		txMock.On("Commit", ctx).Return(nil).Once()
+
+		correction, err := repo.ReconcileBalance(ctx, "user1", "audit")
+		assert.NoError(t, err)
+		assert.Equal(t, 100, correction)
+		txMock.AssertExpectations(t)
+		txMock.AssertNumberOfCalls(t, "Exec", 1)
+	})
+
+	t.Run("Ledger already matches", func(t *testing.T) {
+		txMock := beginWithReport(1000, 800, 1000, 200)
+
+		correction, err := repo.ReconcileBalance(ctx, "user1", "audit")
+		assert.NoError(t, err)
+		assert.Zero(t, correction)
+		txMock.AssertNotCalled(t, "Commit", ctx)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		lockRow := new(mocks.PgxRowMock)
+		poolMock.On("BeginTx", ctx, pgx.TxOptions{}).Return(txMock, nil).Once()
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"ghost"}).Return(lockRow).Once()
+		lockRow.On("Scan", mock.Anything).Return(pgx.ErrNoRows).Once()
+		txMock.On("Rollback", ctx).Return(nil).Once()
+
+		_, err := repo.ReconcileBalance(ctx, "ghost", "audit")
+		assert.ErrorIs(t, err, model.ErrUserNotFound)
+	})
+}
+
+func TestLedgerRepository_GetActivity(t *testing.T) {
+	poolMock := new(mocks.DBMock)
+	repo := NewLedgerRepository(poolMock)
+	ctx := context.Background()
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	type row struct {
+		id           int64
+		kind         string
+		amount       int
+		balance      int
+		counterparty string
+		reason       string
+	}
+
+	// Expects query returning given rows
+	expectRows := func(args []interface{}, events ...row) {
+		rows := new(mocks.PgxRowsMock)
+		poolMock.On("Query", ctx, mock.Anything, args).Return(rows, nil).Once()
+
+		for _, e := range events {
+			rows.On("Next").Return(true).Once()
+			rows.On("Scan", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+				mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				*args[0].(*int64) = e.id
+				*args[1].(*string) = e.kind
+				*args[2].(*int) = e.amount
+				*args[3].(*int) = e.balance
+				*args[4].(*string) = e.counterparty
+				*args[5].(*string) = e.reason
+				*args[6].(*time.Time) = created
+			}).Return(nil).Once()
+		}
+		rows.On("Next").Return(false).Once()
+		rows.On("Err").Return(nil).Once()
+		rows.On("Close").Return().Once()
+	}
+
+	t.Run("Events with running balance", func(t *testing.T) {
+		expectRows([]interface{}{"user1", nil, (*time.Time)(nil), (*time.Time)(nil), 3},
+			row{3, model.PostingPurchase, -300, 690, "", "hoody"},
+			row{2, model.PostingTransfer, -10, 990, "user2", ""},
+			row{1, model.PostingGrant, 1000, 1000, "", ""},
+		)
+
+		page, err := repo.GetActivity(ctx, "user1", model.ActivityFilter{Limit: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, []model.ActivityEvent{
+			{ID: 3, Kind: model.PostingPurchase, Amount: -300, Balance: 690, Item: "hoody", Timestamp: created},
+			{ID: 2, Kind: model.PostingTransfer, Amount: -10, Balance: 990, Counterparty: "user2", Timestamp: created},
+		}, page.Events)
+		assert.Equal(t, "2", page.NextCursor)
+	})
+
+	t.Run("Page after cursor", func(t *testing.T) {
+		from := created.Add(-time.Hour)
+		expectRows([]interface{}{"user1", int64(2), &from, (*time.Time)(nil), 3},
+			row{1, model.PostingGrant, 1000, 1000, "", ""},
+		)
+
+		page, err := repo.GetActivity(ctx, "user1", model.ActivityFilter{Cursor: 2, From: &from, Limit: 2})
+		assert.NoError(t, err)
+		assert.Len(t, page.Events, 1)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		poolMock.On("Query", ctx, mock.Anything, mock.Anything).
+			Return(new(mocks.PgxRowsMock), model.ErrInternalError).Once()
+
+		_, err := repo.GetActivity(ctx, "user1", model.ActivityFilter{Limit: 2})
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+// Statement writer that keeps everything it receives
+type statementRecorder struct {
+	opening int
+	events  []model.ActivityEvent
+}
+
+func (r *statementRecorder) Opening(balance int) error {
+	r.opening = balance
+	return nil
+}
+
+func (r *statementRecorder) Movement(e model.ActivityEvent) error {
+	r.events = append(r.events, e)
+	return nil
+}
+
+func TestLedgerRepository_StreamStatement(t *testing.T) {
+	poolMock := new(mocks.DBMock)
+	repo := NewLedgerRepository(poolMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	created := from.Add(time.Hour)
+	snapshot := pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+
+	begin := func() *mocks.TxMock {
+		txMock := new(mocks.TxMock)
+		poolMock.On("BeginTx", ctx, snapshot).Return(txMock, nil).Once()
+		txMock.On("Rollback", ctx).Return(nil).Once()
+		return txMock
+	}
+
+	// Expects movements query returning entries with given kinds, amounts and reasons
+	expectMovements := func(txMock *mocks.TxMock, args []interface{}, kinds []string, amounts []int, reasons []string) {
+		rows := new(mocks.PgxRowsMock)
+		txMock.On("Query", ctx, mock.Anything, args).Return(rows, nil).Once()
+
+		for i := range kinds {
+			i := i
+			rows.On("Next").Return(true).Once()
+			rows.On("Scan", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+				mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				*args[0].(*int64) = int64(i + 1)
+				*args[1].(*string) = kinds[i]
+				*args[2].(*int) = amounts[i]
+				*args[4].(*string) = reasons[i]
+				*args[5].(*time.Time) = created
+			}).Return(nil).Once()
+		}
+		rows.On("Next").Return(false).Once()
+		rows.On("Err").Return(nil).Once()
+		rows.On("Close").Return().Once()
+	}
+
+	t.Run("Opening balance and running balance", func(t *testing.T) {
+		txMock := begin()
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user1", &from}).Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).Run(func(args mock.Arguments) {
+			*args[0].(*int) = 1000
+		}).Return(nil).Once()
+		expectMovements(txMock, []interface{}{"user1", &from, &to},
+			[]string{model.PostingPurchase, model.PostingAdjustment},
+			[]int{-300, 50},
+			[]string{"hoody", "bonus"},
+		)
+
+		var rec statementRecorder
+		err := repo.StreamStatement(ctx, "user1", &from, &to, &rec)
+		assert.NoError(t, err)
+		assert.Equal(t, 1000, rec.opening)
+		assert.Equal(t, []model.ActivityEvent{
+			{ID: 1, Kind: model.PostingPurchase, Amount: -300, Balance: 700, Item: "hoody", Timestamp: created},
+			{ID: 2, Kind: model.PostingAdjustment, Amount: 50, Balance: 750, Reason: "bonus", Timestamp: created},
+		}, rec.events)
+		txMock.AssertExpectations(t)
+	})
+
+	t.Run("Open period starts from zero", func(t *testing.T) {
+		txMock := begin()
+		expectMovements(txMock, []interface{}{"user1", (*time.Time)(nil), (*time.Time)(nil)},
+			[]string{model.PostingGrant}, []int{1000}, []string{""},
+		)
+
+		var rec statementRecorder
+		err := repo.StreamStatement(ctx, "user1", nil, nil, &rec)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, rec.opening)
+		assert.Equal(t, 1000, rec.events[0].Balance)
+		txMock.AssertNotCalled(t, "QueryRow", ctx, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		txMock := begin()
+		txMock.On("Query", ctx, mock.Anything, mock.Anything).
+			Return(new(mocks.PgxRowsMock), model.ErrInternalError).Once()
+
+		var rec statementRecorder
+		err := repo.StreamStatement(ctx, "user1", nil, &to, &rec)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+		txMock.AssertExpectations(t)
+	})
+
+	t.Run("Transaction start error", func(t *testing.T) {
+		poolMock.On("BeginTx", ctx, snapshot).Return(new(mocks.TxMock), pgx.ErrTxClosed).Once()
+
+		err := repo.StreamStatement(ctx, "user1", nil, nil, &statementRecorder{})
+		assert.ErrorIs(t, err, pgx.ErrTxClosed)
+	})
+}
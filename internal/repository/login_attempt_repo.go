@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Interface for failed login attempts repository, needed for testing
+type LoginAttemptRepositoryInt interface {
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	Lock(ctx context.Context, key string, d time.Duration) error
+	GetLockRemaining(ctx context.Context, keys []string) (time.Duration, error)
+	Reset(ctx context.Context, keys []string) error
+}
+
+// Login attempt repository for failed login attempts manipulations, keys identify either username or client IP,
+// all moments are computed by database so replicas with skewed clocks agree on lockouts
+type LoginAttemptRepository struct {
+	pool DB
+}
+
+// Constructor for login attempt repository
+func NewLoginAttemptRepository(db DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{pool: db}
+}
+
+// Function that counts failed attempt for key, counter starts over when previous failure is older than window,
+// returns number of consecutive failures and error
+func (r LoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	var failures int
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO login_attempts (key, failures, last_failure_at)
+         VALUES ($1, 1, CURRENT_TIMESTAMP)
+         ON CONFLICT (key) DO UPDATE
+         SET failures = CASE
+                 WHEN login_attempts.last_failure_at < CURRENT_TIMESTAMP - make_interval(secs => $2) THEN 1
+                 ELSE login_attempts.failures + 1
+             END,
+             last_failure_at = CURRENT_TIMESTAMP
+         RETURNING failures`,
+		key, window.Seconds(),
+	).Scan(&failures)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return 0, err
+	}
+	return failures, nil
+}
+
+// Function that locks key for duration d, returns error
+func (r LoginAttemptRepository) Lock(ctx context.Context, key string, d time.Duration) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE login_attempts SET locked_until = CURRENT_TIMESTAMP + make_interval(secs => $2)
+         WHERE key = $1`,
+		key, d.Seconds(),
+	)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+	}
+	return err
+}
+
+// Function that finds the longest active lock among keys, returns remaining lock duration or zero
+// if none of the keys is locked, and error
+func (r LoginAttemptRepository) GetLockRemaining(ctx context.Context, keys []string) (time.Duration, error) {
+	var seconds float64
+	err := r.pool.QueryRow(ctx,
+		`SELECT COALESCE(EXTRACT(EPOCH FROM MAX(locked_until) - CURRENT_TIMESTAMP), 0)::float8
+         FROM login_attempts
+         WHERE key = ANY($1) AND locked_until > CURRENT_TIMESTAMP`,
+		keys,
+	).Scan(&seconds)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// Function that forgets failed attempts and locks of keys, returns error
+func (r LoginAttemptRepository) Reset(ctx context.Context, keys []string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM login_attempts WHERE key = ANY($1)`, keys)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestLoginAttemptRepository_RecordFailure(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewLoginAttemptRepository(dbMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+
+	t.Run("Successful record", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user:alice", float64(3600)}).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).
+			Run(func(args mock.Arguments) {
+				*args[0].(*int) = 3
+			}).Return(nil).Once()
+
+		failures, err := repo.RecordFailure(ctx, "user:alice", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, failures)
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, mock.Anything).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).
+			Return(model.ErrInternalError).Once()
+
+		_, err := repo.RecordFailure(ctx, "user:bob", time.Hour)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestLoginAttemptRepository_Lock(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewLoginAttemptRepository(dbMock)
+	ctx := context.Background()
+
+	t.Run("Successful lock", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user:alice", float64(30)}).
+			Return(pgconn.CommandTag{}, nil).Once()
+
+		assert.NoError(t, repo.Lock(ctx, "user:alice", 30*time.Second))
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, mock.Anything).
+			Return(pgconn.CommandTag{}, model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, repo.Lock(ctx, "user:bob", time.Second), model.ErrInternalError)
+	})
+}
+
+func TestLoginAttemptRepository_GetLockRemaining(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewLoginAttemptRepository(dbMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+	keys := []string{"user:alice", "ip:10.0.0.1"}
+
+	t.Run("Locked key", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{keys}).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).
+			Run(func(args mock.Arguments) {
+				*args[0].(*float64) = 1.5
+			}).Return(nil).Once()
+
+		remaining, err := repo.GetLockRemaining(ctx, keys)
+		assert.NoError(t, err)
+		assert.Equal(t, 1500*time.Millisecond, remaining)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{keys}).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).
+			Return(model.ErrInternalError).Once()
+
+		_, err := repo.GetLockRemaining(ctx, keys)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestLoginAttemptRepository_Reset(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewLoginAttemptRepository(dbMock)
+	ctx := context.Background()
+	keys := []string{"user:alice"}
+
+	t.Run("Successful reset", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{keys}).
+			Return(pgconn.CommandTag{}, nil).Once()
+
+		assert.NoError(t, repo.Reset(ctx, keys))
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{keys}).
+			Return(pgconn.CommandTag{}, model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, repo.Reset(ctx, keys), model.ErrInternalError)
+	})
+}
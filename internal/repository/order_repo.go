@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+)
+
+// Interface for order repository, needed for testing
+type OrderRepositoryInt interface {
+	CreateOrderTx(ctx context.Context, tx pgx.Tx, order *model.Order) error
+	GetUserOrders(ctx context.Context, userID string, cursor *model.HistoryCursor, limit int) (*model.OrderPage, error)
+}
+
+// Order repository for purchase records
+type OrderRepository struct {
+	pool DB
+}
+
+// Constructor for order repository
+func NewOrderRepository(db DB) *OrderRepository {
+	return &OrderRepository{pool: db}
+}
+
+// Function that records order during transaction, fills ID, Status and CreatedAt of order, returns error
+func (r OrderRepository) CreateOrderTx(ctx context.Context, tx pgx.Tx, order *model.Order) error {
+	err := tx.QueryRow(ctx,
+		`INSERT INTO orders (user_id, item_name, unit_price, quantity)
+         VALUES ($1, $2, $3, $4)
+         RETURNING id, status, created_at`,
+		order.UserID, order.Item, order.UnitPrice, order.Quantity,
+	).Scan(&order.ID, &order.Status, &order.CreatedAt)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Function that returns page of at most limit orders of user with userID, newest first, starting after cursor
+// when it is not nil, returns page and error
+func (r OrderRepository) GetUserOrders(ctx context.Context, userID string, cursor *model.HistoryCursor, limit int) (*model.OrderPage, error) {
+	var cursorTime, cursorID any
+	if cursor != nil {
+		cursorTime, cursorID = cursor.CreatedAt, cursor.ID
+	}
+
+	// One extra row tells whether there is a next page
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, item_name, unit_price, quantity, status, created_at
+         FROM orders
+         WHERE user_id = $1 AND ($2::timestamp IS NULL OR (created_at, id) < ($2, $3::uuid))
+         ORDER BY created_at DESC, id DESC
+         LIMIT $4`,
+		userID, cursorTime, cursorID, limit+1,
+	)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &model.OrderPage{Orders: make([]model.Order, 0, limit)}
+	for rows.Next() {
+		order := model.Order{UserID: userID}
+		err := rows.Scan(&order.ID, &order.Item, &order.UnitPrice, &order.Quantity, &order.Status, &order.CreatedAt)
+		if err != nil {
+			log.Printf("Database error: %v", err)
+			return nil, err
+		}
+		page.Orders = append(page.Orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+
+	if len(page.Orders) > limit {
+		page.Orders = page.Orders[:limit]
+		last := page.Orders[len(page.Orders)-1]
+		page.NextCursor = model.HistoryCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	return page, nil
+}
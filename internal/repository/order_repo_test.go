@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestOrderRepository_CreateOrderTx(t *testing.T) {
+	repo := NewOrderRepository(new(mocks.DBMock))
+	txMock := new(mocks.TxMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("Successful creation", func(t *testing.T) {
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user1", "hoody", 300, 1}).Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			*args[0].(*string) = "order1"
+			*args[1].(*string) = model.OrderCompleted
+			*args[2].(*time.Time) = now
+		}).Return(nil).Once()
+
+		order := &model.Order{UserID: "user1", Item: "hoody", UnitPrice: 300, Quantity: 1}
+		assert.NoError(t, repo.CreateOrderTx(ctx, txMock, order))
+		assert.Equal(t, "order1", order.ID)
+		assert.Equal(t, model.OrderCompleted, order.Status)
+		assert.Equal(t, now, order.CreatedAt)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user1", "hoody", 300, 1}).Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything, mock.Anything, mock.Anything).Return(model.ErrInternalError).Once()
+
+		order := &model.Order{UserID: "user1", Item: "hoody", UnitPrice: 300, Quantity: 1}
+		assert.ErrorIs(t, repo.CreateOrderTx(ctx, txMock, order), model.ErrInternalError)
+	})
+}
+
+func TestOrderRepository_GetUserOrders(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewOrderRepository(dbMock)
+	ctx := context.Background()
+	now := time.Now()
+
+	scanOrder := func(rowsMock *mocks.PgxRowsMock, id string, createdAt time.Time) {
+		rowsMock.On("Next").Return(true).Once()
+		rowsMock.On("Scan", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				*args[0].(*string) = id
+				*args[1].(*string) = "hoody"
+				*args[2].(*int) = 300
+				*args[3].(*int) = 1
+				*args[4].(*string) = model.OrderCompleted
+				*args[5].(*time.Time) = createdAt
+			}).Return(nil).Once()
+	}
+
+	t.Run("Last page", func(t *testing.T) {
+		rowsMock := new(mocks.PgxRowsMock)
+		dbMock.On("Query", ctx, mock.Anything, []interface{}{"user1", nil, nil, 3}).Return(rowsMock, nil).Once()
+
+		scanOrder(rowsMock, "order1", now)
+		rowsMock.On("Next").Return(false).Once()
+		rowsMock.On("Err").Return(nil).Once()
+		rowsMock.On("Close").Return().Once()
+
+		page, err := repo.GetUserOrders(ctx, "user1", nil, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Order{
+			{ID: "order1", UserID: "user1", Item: "hoody", UnitPrice: 300, Quantity: 1, Status: model.OrderCompleted, CreatedAt: now},
+		}, page.Orders)
+		assert.Empty(t, page.NextCursor)
+		rowsMock.AssertExpectations(t)
+	})
+
+	t.Run("Next page with cursor", func(t *testing.T) {
+		cursor := &model.HistoryCursor{CreatedAt: now, ID: "order9"}
+		rowsMock := new(mocks.PgxRowsMock)
+		dbMock.On("Query", ctx, mock.Anything, []interface{}{"user1", now, "order9", 2}).Return(rowsMock, nil).Once()
+
+		scanOrder(rowsMock, "order8", now.Add(-time.Minute))
+		scanOrder(rowsMock, "order7", now.Add(-2*time.Minute))
+		rowsMock.On("Next").Return(false).Once()
+		rowsMock.On("Err").Return(nil).Once()
+		rowsMock.On("Close").Return().Once()
+
+		page, err := repo.GetUserOrders(ctx, "user1", cursor, 1)
+		assert.NoError(t, err)
+		assert.Len(t, page.Orders, 1)
+		assert.Equal(t, "order8", page.Orders[0].ID)
+		assert.Equal(t, model.HistoryCursor{CreatedAt: now.Add(-time.Minute), ID: "order8"}.Encode(), page.NextCursor)
+		rowsMock.AssertExpectations(t)
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		dbMock.On("Query", ctx, mock.Anything, []interface{}{"user1", nil, nil, 3}).
+			Return(new(mocks.PgxRowsMock), model.ErrInternalError).Once()
+
+		_, err := repo.GetUserOrders(ctx, "user1", nil, 2)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+
+	t.Run("Scan error", func(t *testing.T) {
+		rowsMock := new(mocks.PgxRowsMock)
+		dbMock.On("Query", ctx, mock.Anything, []interface{}{"user1", nil, nil, 3}).Return(rowsMock, nil).Once()
+
+		rowsMock.On("Next").Return(true).Once()
+		rowsMock.On("Scan", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(model.ErrInternalError).Once()
+		rowsMock.On("Close").Return().Once()
+
+		_, err := repo.GetUserOrders(ctx, "user1", nil, 2)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+)
+
+// Interface for password reset repository, needed for testing
+type PasswordResetRepositoryInt interface {
+	CreateReset(ctx context.Context, reset *model.PasswordReset) error
+	ConsumeResetTx(ctx context.Context, tx pgx.Tx, tokenHash string) (*model.PasswordReset, error)
+}
+
+// Password reset repository for password reset token manipulations
+type PasswordResetRepository struct {
+	pool DB
+}
+
+// Constructor for password reset repository
+func NewPasswordResetRepository(db DB) *PasswordResetRepository {
+	return &PasswordResetRepository{pool: db}
+}
+
+// Function that writes password reset token to database and assigns its ID, returns error
+func (r PasswordResetRepository) CreateReset(ctx context.Context, reset *model.PasswordReset) error {
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO password_resets (user_id, token_hash, created_by, expires_at)
+         VALUES ($1, $2, $3, $4)
+         RETURNING id`,
+		reset.UserID, reset.TokenHash, reset.CreatedBy, reset.ExpiresAt,
+	).Scan(&reset.ID)
+	if err != nil {
+		log.Printf("Error creating password reset: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Function that marks password reset token as used during transaction if it is still usable, rolled back
+// transaction leaves the token usable, returns consumed token or nil if token is unknown, used or expired,
+// and error
+func (r PasswordResetRepository) ConsumeResetTx(ctx context.Context, tx pgx.Tx, tokenHash string) (*model.PasswordReset, error) {
+	reset := model.PasswordReset{TokenHash: tokenHash}
+	err := tx.QueryRow(ctx,
+		`UPDATE password_resets SET used_at = CURRENT_TIMESTAMP
+         WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+         RETURNING id, user_id, created_by, expires_at, used_at`,
+		tokenHash,
+	).Scan(&reset.ID, &reset.UserID, &reset.CreatedBy, &reset.ExpiresAt, &reset.UsedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	return &reset, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestPasswordResetRepository_CreateReset(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewPasswordResetRepository(dbMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	t.Run("Successful creation", func(t *testing.T) {
+		reset := &model.PasswordReset{UserID: "user1", TokenHash: "hash", CreatedBy: "admin1", ExpiresAt: expiresAt}
+
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user1", "hash", "admin1", expiresAt}).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).
+			Run(func(args mock.Arguments) {
+				*args[0].(*string) = "reset1"
+			}).Return(nil).Once()
+
+		assert.NoError(t, repo.CreateReset(ctx, reset))
+		assert.Equal(t, "reset1", reset.ID)
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, mock.Anything).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).
+			Return(model.ErrInternalError).Once()
+
+		err := repo.CreateReset(ctx, &model.PasswordReset{TokenHash: "hash2"})
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestPasswordResetRepository_ConsumeResetTx(t *testing.T) {
+	repo := NewPasswordResetRepository(new(mocks.DBMock))
+	txMock := new(mocks.TxMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+
+	t.Run("Successful consumption", func(t *testing.T) {
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"hash"}).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				*args[1].(*string) = "user1"
+			}).Return(nil).Once()
+
+		reset, err := repo.ConsumeResetTx(ctx, txMock, "hash")
+		assert.NoError(t, err)
+		assert.Equal(t, "user1", reset.UserID)
+	})
+
+	t.Run("Token is not usable", func(t *testing.T) {
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"used"}).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(pgx.ErrNoRows).Once()
+
+		reset, err := repo.ConsumeResetTx(ctx, txMock, "used")
+		assert.NoError(t, err)
+		assert.Nil(t, reset)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"hash"}).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(model.ErrInternalError).Once()
+
+		reset, err := repo.ConsumeResetTx(ctx, txMock, "hash")
+		assert.Nil(t, reset)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -12,6 +13,7 @@ type DB interface {
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
 }
 
 // The wrapper over pgxpool.Pool, needed for tests
@@ -33,3 +35,8 @@ func (w *PgxPoolWrapper) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (
 func (w *PgxPoolWrapper) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
 	return w.pool.Query(ctx, sql, args...)
 }
+
+// The wrapper over pgxpool.Pool.Exec
+func (w *PgxPoolWrapper) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return w.pool.Exec(ctx, sql, args...)
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+)
+
+// Interface for refresh token repository, needed for testing
+type RefreshTokenRepositoryInt interface {
+	CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error
+	ConsumeRefreshToken(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+	GetRefreshToken(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeUserTokens(ctx context.Context, userID string, before time.Time) error
+}
+
+// Refresh token repository for refresh token manipulations
+type RefreshTokenRepository struct {
+	pool DB
+}
+
+// Constructor for refresh token repository
+func NewRefreshTokenRepository(db DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{pool: db}
+}
+
+// Function that writes refresh token to database, token without FamilyID starts a new family,
+// assigns ID and FamilyID, returns error
+func (r RefreshTokenRepository) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+         VALUES ($1, COALESCE(NULLIF($2, '')::uuid, gen_random_uuid()), $3, $4)
+         RETURNING id, family_id`,
+		token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt,
+	).Scan(&token.ID, &token.FamilyID)
+	if err != nil {
+		log.Printf("Error creating refresh token: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Function that marks refresh token as used if it is still usable, so every token can be used only once,
+// returns consumed token or nil if token is unknown, used, revoked or expired, and error
+func (r RefreshTokenRepository) ConsumeRefreshToken(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	token := model.RefreshToken{TokenHash: tokenHash}
+	err := r.pool.QueryRow(ctx,
+		`UPDATE refresh_tokens SET used_at = CURRENT_TIMESTAMP
+         WHERE token_hash = $1 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+         RETURNING id, user_id, family_id, expires_at, used_at`,
+		tokenHash,
+	).Scan(&token.ID, &token.UserID, &token.FamilyID, &token.ExpiresAt, &token.UsedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Extracts refresh token by its hash regardless of its state, returns nil if there is no such token, and error
+func (r RefreshTokenRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	token := model.RefreshToken{TokenHash: tokenHash}
+	err := r.pool.QueryRow(ctx,
+		`SELECT id, user_id, family_id, expires_at, used_at, revoked_at
+         FROM refresh_tokens WHERE token_hash = $1`,
+		tokenHash,
+	).Scan(&token.ID, &token.UserID, &token.FamilyID, &token.ExpiresAt, &token.UsedAt, &token.RevokedAt)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Function that revokes every token descended from the same login, returns error
+func (r RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+         WHERE family_id = $1 AND revoked_at IS NULL`,
+		familyID,
+	)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+	}
+	return err
+}
+
+// Function that revokes every refresh token of user with userID issued before given moment, returns error
+func (r RefreshTokenRepository) RevokeUserTokens(ctx context.Context, userID string, before time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+         WHERE user_id = $1 AND created_at < $2 AND revoked_at IS NULL`,
+		userID, before,
+	)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+	}
+	return err
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestRefreshTokenRepository_CreateRefreshToken(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewRefreshTokenRepository(dbMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+	expiresAt := time.Now()
+
+	t.Run("Successful token creation", func(t *testing.T) {
+		token := &model.RefreshToken{UserID: "user1", TokenHash: "hash", ExpiresAt: expiresAt}
+
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user1", "", "hash", expiresAt}).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				*args[0].(*string) = "token1"
+				*args[1].(*string) = "family1"
+			}).Return(nil).Once()
+
+		err := repo.CreateRefreshToken(ctx, token)
+		assert.NoError(t, err)
+		assert.Equal(t, "token1", token.ID)
+		assert.Equal(t, "family1", token.FamilyID)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, mock.Anything).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan", mock.Anything, mock.Anything).
+			Return(model.ErrInternalError).Once()
+
+		err := repo.CreateRefreshToken(ctx, &model.RefreshToken{UserID: "user1"})
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestRefreshTokenRepository_ConsumeRefreshToken(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewRefreshTokenRepository(dbMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+
+	t.Run("Successful consumption", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"hash"}).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				*args[1].(*string) = "user1"
+				*args[2].(*string) = "family1"
+			}).Return(nil).Once()
+
+		token, err := repo.ConsumeRefreshToken(ctx, "hash")
+		assert.NoError(t, err)
+		assert.Equal(t, "user1", token.UserID)
+		assert.Equal(t, "family1", token.FamilyID)
+	})
+
+	t.Run("Token is not usable", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"used"}).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(pgx.ErrNoRows).Once()
+
+		token, err := repo.ConsumeRefreshToken(ctx, "used")
+		assert.NoError(t, err)
+		assert.Nil(t, token)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"hash"}).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(model.ErrInternalError).Once()
+
+		token, err := repo.ConsumeRefreshToken(ctx, "hash")
+		assert.Nil(t, token)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestRefreshTokenRepository_GetRefreshToken(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewRefreshTokenRepository(dbMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+
+	t.Run("Successful retrieval", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"hash"}).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan",
+			mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything,
+		).Run(func(args mock.Arguments) {
+			*args[1].(*string) = "user1"
+		}).Return(nil).Once()
+
+		token, err := repo.GetRefreshToken(ctx, "hash")
+		assert.NoError(t, err)
+		assert.Equal(t, "user1", token.UserID)
+	})
+
+	t.Run("Token not found", func(t *testing.T) {
+		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"unknown"}).
+			Return(rowMock).Once()
+
+		rowMock.On("Scan",
+			mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything,
+		).Return(pgx.ErrNoRows).Once()
+
+		token, err := repo.GetRefreshToken(ctx, "unknown")
+		assert.NoError(t, err)
+		assert.Nil(t, token)
+	})
+}
+
+func TestRefreshTokenRepository_RevokeFamily(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewRefreshTokenRepository(dbMock)
+	commandTag := new(pgconn.CommandTag)
+	ctx := context.Background()
+
+	t.Run("Successful revocation", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"family1"}).
+			Return(*commandTag, nil).Once()
+
+		assert.NoError(t, repo.RevokeFamily(ctx, "family1"))
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"family2"}).
+			Return(*commandTag, model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, repo.RevokeFamily(ctx, "family2"), model.ErrInternalError)
+	})
+}
+
+func TestRefreshTokenRepository_RevokeUserTokens(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewRefreshTokenRepository(dbMock)
+	commandTag := new(pgconn.CommandTag)
+	ctx := context.Background()
+	before := time.Now()
+
+	t.Run("Successful revocation", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user1", before}).
+			Return(*commandTag, nil).Once()
+
+		assert.NoError(t, repo.RevokeUserTokens(ctx, "user1", before))
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user2", before}).
+			Return(*commandTag, model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, repo.RevokeUserTokens(ctx, "user2", before), model.ErrInternalError)
+	})
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Bounds of repeating transactions that failed because of conflict with concurrent transaction
+const (
+	txAttempts    = 5
+	txBaseBackoff = 10 * time.Millisecond
+	txMaxBackoff  = 200 * time.Millisecond
+)
+
+// Runs fn until it succeeds, fails with error other than serialization failure or deadlock, or runs out
+// of attempts, waits with exponential backoff and jitter between attempts, returns last error
+func retryTx(ctx context.Context, fn func() error) error {
+	backoff := txBaseBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !isTxConflict(err) || attempt == txAttempts {
+			return err
+		}
+		log.Printf("Transaction conflict, attempt %d of %d: %v", attempt, txAttempts, err)
+
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, txMaxBackoff)
+	}
+}
+
+// Reports whether err is serialization failure or deadlock, after which transaction may be repeated
+func isTxConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Interface for access token revocation repository, needed for testing
+type RevocationRepositoryInt interface {
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	RevokeUserTokens(ctx context.Context, userID string, before time.Time) error
+	GetRevokedTokens(ctx context.Context) (map[string]time.Time, error)
+	GetUserRevocations(ctx context.Context) (map[string]time.Time, error)
+}
+
+// Revocation repository for access token revocation manipulations
+type RevocationRepository struct {
+	pool DB
+}
+
+// Constructor for revocation repository
+func NewRevocationRepository(db DB) *RevocationRepository {
+	return &RevocationRepository{pool: db}
+}
+
+// Function that revokes single access token by its jti until the token expires, returns error
+func (r RevocationRepository) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO revoked_tokens (jti, expires_at)
+         VALUES ($1, $2)
+         ON CONFLICT (jti) DO NOTHING`,
+		jti, expiresAt,
+	)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+	}
+	return err
+}
+
+// Function that revokes every access token of user with userID issued before given moment, returns error
+func (r RevocationRepository) RevokeUserTokens(ctx context.Context, userID string, before time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO user_token_revocations (user_id, revoked_before)
+         VALUES ($1, $2)
+         ON CONFLICT (user_id) DO UPDATE
+         SET revoked_before = GREATEST(user_token_revocations.revoked_before, excluded.revoked_before)`,
+		userID, before,
+	)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+	}
+	return err
+}
+
+// Function that extracts jti of revoked tokens that are not expired yet, returns map from jti
+// to token expiration and error
+func (r RevocationRepository) GetRevokedTokens(ctx context.Context) (map[string]time.Time, error) {
+	return r.loadMoments(ctx,
+		`SELECT jti, expires_at FROM revoked_tokens WHERE expires_at > CURRENT_TIMESTAMP`)
+}
+
+// Function that extracts per user revocations, returns map from userID to the moment before which
+// tokens are revoked and error
+func (r RevocationRepository) GetUserRevocations(ctx context.Context) (map[string]time.Time, error) {
+	return r.loadMoments(ctx,
+		`SELECT user_id, revoked_before FROM user_token_revocations`)
+}
+
+// Executes query returning pairs of key and moment
+func (r RevocationRepository) loadMoments(ctx context.Context, query string) (map[string]time.Time, error) {
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	moments := make(map[string]time.Time)
+	for rows.Next() {
+		var key string
+		var moment time.Time
+		if err := rows.Scan(&key, &moment); err != nil {
+			log.Printf("Database error: %v", err)
+			return nil, err
+		}
+		moments[key] = moment
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
+	}
+	return moments, nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestRevocationRepository_RevokeToken(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewRevocationRepository(dbMock)
+	commandTag := new(pgconn.CommandTag)
+	ctx := context.Background()
+	expiresAt := time.Now()
+
+	t.Run("Successful revocation", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"jti1", expiresAt}).
+			Return(*commandTag, nil).Once()
+
+		assert.NoError(t, repo.RevokeToken(ctx, "jti1", expiresAt))
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"jti2", expiresAt}).
+			Return(*commandTag, model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, repo.RevokeToken(ctx, "jti2", expiresAt), model.ErrInternalError)
+	})
+}
+
+func TestRevocationRepository_RevokeUserTokens(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewRevocationRepository(dbMock)
+	commandTag := new(pgconn.CommandTag)
+	ctx := context.Background()
+	before := time.Now()
+
+	t.Run("Successful revocation", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user1", before}).
+			Return(*commandTag, nil).Once()
+
+		assert.NoError(t, repo.RevokeUserTokens(ctx, "user1", before))
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user2", before}).
+			Return(*commandTag, model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, repo.RevokeUserTokens(ctx, "user2", before), model.ErrInternalError)
+	})
+}
+
+func TestRevocationRepository_GetRevokedTokens(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewRevocationRepository(dbMock)
+	ctx := context.Background()
+	expiresAt := time.Now()
+
+	t.Run("Successful retrieval", func(t *testing.T) {
+		rowsMock := new(mocks.PgxRowsMock)
+
+		dbMock.On("Query", ctx, mock.Anything, []interface{}(nil)).
+			Return(rowsMock, nil).Once()
+
+		rowsMock.On("Next").Return(true).Once()
+		rowsMock.On("Next").Return(false).Once()
+		rowsMock.On("Scan", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				*args[0].(*string) = "jti1"
+				*args[1].(*time.Time) = expiresAt
+			}).Return(nil).Once()
+		rowsMock.On("Err").Return(nil).Once()
+		rowsMock.On("Close").Return(nil).Once()
+
+		tokens, err := repo.GetRevokedTokens(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]time.Time{"jti1": expiresAt}, tokens)
+	})
+
+	t.Run("Query execution error", func(t *testing.T) {
+		rowsMock := new(mocks.PgxRowsMock)
+
+		dbMock.On("Query", ctx, mock.Anything, []interface{}(nil)).
+			Return(rowsMock, model.ErrInternalError).Once()
+
+		tokens, err := repo.GetRevokedTokens(ctx)
+		assert.Nil(t, tokens)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestRevocationRepository_GetUserRevocations(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	repo := NewRevocationRepository(dbMock)
+	ctx := context.Background()
+
+	t.Run("Row scanning error", func(t *testing.T) {
+		rowsMock := new(mocks.PgxRowsMock)
+
+		dbMock.On("Query", ctx, mock.Anything, []interface{}(nil)).
+			Return(rowsMock, nil).Once()
+
+		rowsMock.On("Next").Return(true).Once()
+		rowsMock.On("Scan", mock.Anything, mock.Anything).
+			Return(model.ErrInternalError).Once()
+		rowsMock.On("Close").Return(nil).Once()
+
+		revocations, err := repo.GetUserRevocations(ctx)
+		assert.Nil(t, revocations)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+
+	t.Run("Interrupted iteration", func(t *testing.T) {
+		rowsMock := new(mocks.PgxRowsMock)
+
+		dbMock.On("Query", ctx, mock.Anything, []interface{}(nil)).
+			Return(rowsMock, nil).Once()
+
+		rowsMock.On("Next").Return(true).Once()
+		rowsMock.On("Next").Return(false).Once()
+		rowsMock.On("Scan", mock.Anything, mock.Anything).Return(nil).Once()
+		rowsMock.On("Err").Return(model.ErrInternalError).Once()
+		rowsMock.On("Close").Return(nil).Once()
+
+		revocations, err := repo.GetUserRevocations(ctx)
+		assert.Nil(t, revocations)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
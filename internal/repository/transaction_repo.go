@@ -2,7 +2,11 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 
@@ -11,8 +15,16 @@ import (
 
 // Interface for transaction repository, needed for testing
 type TransactionRepositoryInt interface {
-	TransferCoins(ctx context.Context, fromUserID, toUserID string, amount int) error
-	GetTransactionHistory(ctx context.Context, userID string) (*model.TransactionHistory, error)
+	TransferCoins(
+		ctx context.Context,
+		fromUserID, toUserID string,
+		amount int,
+		note model.TransferNote,
+		check func(tx pgx.Tx) error,
+	) error
+	GetHistory(ctx context.Context, userID string, filter model.HistoryFilter) (*model.HistoryPage, error)
+	GetCounterpartySummary(ctx context.Context, userID, reason string) ([]model.CounterpartySummary, error)
+	GetSentSinceTx(ctx context.Context, tx pgx.Tx, userID string, since time.Time) (int, int, error)
 }
 
 // Transaction repository, for sendCoin manipulations
@@ -25,8 +37,29 @@ func NewTransactionRepository(db DB) *TransactionRepository {
 	return &TransactionRepository{pool: db}
 }
 
-// Function that transfers coins from one user to another using batch in one transaction, returns error
-func (r TransactionRepository) TransferCoins(ctx context.Context, fromUserID, toUserID string, amount int) error {
+// Function that transfers coins from one user to another using batch in one transaction, the transfer is
+// recorded both in history together with its note and in ledger, transaction is repeated after deadlock
+// or serialization failure. Optional check is called inside transaction once rows of both users are locked,
+// its error cancels transfer. Returns error
+func (r TransactionRepository) TransferCoins(
+	ctx context.Context,
+	fromUserID, toUserID string,
+	amount int,
+	note model.TransferNote,
+	check func(tx pgx.Tx) error,
+) error {
+	return retryTx(ctx, func() error {
+		return r.transferCoins(ctx, fromUserID, toUserID, amount, note, check)
+	})
+}
+
+func (r TransactionRepository) transferCoins(
+	ctx context.Context,
+	fromUserID, toUserID string,
+	amount int,
+	note model.TransferNote,
+	check func(tx pgx.Tx) error,
+) error {
 	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		log.Printf("Transaction error: %v", err)
@@ -34,27 +67,50 @@ func (r TransactionRepository) TransferCoins(ctx context.Context, fromUserID, to
 	}
 	defer tx.Rollback(ctx)
 
-	var balance int
-	err = tx.QueryRow(ctx, "SELECT coins FROM users WHERE id = $1 FOR UPDATE", fromUserID).Scan(&balance)
-	if err != nil {
-		log.Printf("Database error: %v", err)
-		return err
+	// Every transfer locks both rows in the same order, so opposite transfers between the same users
+	// wait for each other instead of deadlocking
+	balances := make(map[string]int, 2)
+	for _, userID := range lockOrder(fromUserID, toUserID) {
+		var coins int
+		err := tx.QueryRow(ctx, "SELECT coins FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&coins)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return model.ErrUserNotFound
+			}
+			log.Printf("Database error: %v", err)
+			return err
+		}
+		balances[userID] = coins
 	}
-	if balance < amount {
-		log.Printf("Database error: %v", err)
+
+	// Transfers of sender wait for each other on the lock, so check sees all of them that were committed
+	if check != nil {
+		if err := check(tx); err != nil {
+			return err
+		}
+	}
+	if balances[fromUserID] < amount {
 		return model.ErrInsufficientFunds
 	}
 
 	batch := &pgx.Batch{}
-	batch.Queue("UPDATE users SET coins = coins - $1 WHERE id = $2", amount, fromUserID)
-	batch.Queue("UPDATE users SET coins = coins + $1 WHERE id = $2", amount, toUserID)
-	batch.Queue("INSERT INTO transactions (from_user_id, to_user_id, amount) VALUES ($1, $2, $3)", fromUserID, toUserID, amount)
+	batch.Queue(
+		"INSERT INTO transactions (from_user_id, to_user_id, amount, message, reason) VALUES ($1, $2, $3, $4, $5)",
+		fromUserID, toUserID, amount, note.Message, note.Reason,
+	)
+	if err := queuePosting(batch, model.TransferPosting(fromUserID, toUserID, amount)); err != nil {
+		return err
+	}
 
 	br := tx.SendBatch(ctx, batch)
 
 	for i := 0; i < batch.Len(); i++ {
 		_, err := br.Exec()
 		if err != nil {
+			br.Close()
+			if isNegativeBalance(err) {
+				return model.ErrInsufficientFunds
+			}
 			log.Printf("Database error: %v", err)
 			return err
 		}
@@ -70,19 +126,52 @@ func (r TransactionRepository) TransferCoins(ctx context.Context, fromUserID, to
 	return nil
 }
 
-// Function that extracts transaction history of a user by userID, returns TransactionHistory structure and error
-func (r TransactionRepository) GetTransactionHistory(ctx context.Context, userID string) (*model.TransactionHistory, error) {
-	history := &model.TransactionHistory{
-		Received: make([]model.ReceivedTransaction, 0),
-		Sent:     make([]model.SentTransaction, 0),
+// Returns distinct user ids in order in which their rows are locked
+func lockOrder(userIDs ...string) []string {
+	ordered := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if !slices.Contains(ordered, id) {
+			ordered = append(ordered, id)
+		}
 	}
+	slices.Sort(ordered)
+	return ordered
+}
 
-	rows, err := r.pool.Query(ctx,
-		`SELECT u.username, t.amount, t.created_at 
+// Query for one side of history, participant column is compared to $1 and joined column gives counterparty,
+// the rest of parameters are filters shared by both sides
+const historySideQuery = `SELECT t.id, '%s' AS direction, u.username, t.amount, t.message, t.reason, t.created_at
          FROM transactions t
-         JOIN users u ON t.from_user_id = u.id
-         WHERE t.to_user_id = $1`,
-		userID,
+         JOIN users u ON u.id = t.%s
+         WHERE t.%s = $1
+           AND ($2 = '' OR u.username = $2)
+           AND ($3 = '' OR t.reason = $3)
+           AND ($4::timestamp IS NULL OR t.created_at >= $4)
+           AND ($5::timestamp IS NULL OR t.created_at < $5)
+           AND ($6::timestamp IS NULL OR (t.created_at, t.id) < ($6, $7::uuid))
+         ORDER BY t.created_at DESC, t.id DESC
+         LIMIT $8`
+
+// Function that reads one page of history of user with userID, newest transfers first, ordering by time and id
+// lets pages continue from cursor without skipping transfers made in the same moment, returns page and error
+func (r TransactionRepository) GetHistory(ctx context.Context, userID string, filter model.HistoryFilter) (*model.HistoryPage, error) {
+	var sides []string
+	if filter.Direction != model.DirectionReceived {
+		sides = append(sides, fmt.Sprintf(historySideQuery, model.DirectionSent, "to_user_id", "from_user_id"))
+	}
+	if filter.Direction != model.DirectionSent {
+		sides = append(sides, fmt.Sprintf(historySideQuery, model.DirectionReceived, "from_user_id", "to_user_id"))
+	}
+	query := "SELECT * FROM ((" + strings.Join(sides, ") UNION ALL (") + ")) h ORDER BY created_at DESC, id DESC LIMIT $8"
+
+	var cursorTime, cursorID any
+	if filter.Cursor != nil {
+		cursorTime, cursorID = filter.Cursor.CreatedAt, filter.Cursor.ID
+	}
+
+	// One extra row tells whether there is a next page
+	rows, err := r.pool.Query(ctx, query,
+		userID, filter.Counterparty, filter.Reason, filter.From, filter.To, cursorTime, cursorID, filter.Limit+1,
 	)
 	if err != nil {
 		log.Printf("Database error: %v", err)
@@ -90,21 +179,50 @@ func (r TransactionRepository) GetTransactionHistory(ctx context.Context, userID
 	}
 	defer rows.Close()
 
+	page := &model.HistoryPage{Entries: make([]model.HistoryEntry, 0, filter.Limit)}
 	for rows.Next() {
-		var t model.ReceivedTransaction
-		if err := rows.Scan(&t.FromUser, &t.Amount, &t.Timestamp); err != nil {
+		var e model.HistoryEntry
+		err := rows.Scan(&e.ID, &e.Direction, &e.Counterparty, &e.Amount, &e.Message, &e.Reason, &e.Timestamp)
+		if err != nil {
 			log.Printf("Database error: %v", err)
 			return nil, err
 		}
-		history.Received = append(history.Received, t)
+		page.Entries = append(page.Entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
 	}
 
-	rows, err = r.pool.Query(ctx,
-		`SELECT u.username, t.amount, t.created_at 
-         FROM transactions t
-         JOIN users u ON t.to_user_id = u.id
-         WHERE t.from_user_id = $1`,
-		userID,
+	if len(page.Entries) > filter.Limit {
+		page.Entries = page.Entries[:filter.Limit]
+		last := page.Entries[len(page.Entries)-1]
+		page.NextCursor = model.HistoryCursor{CreatedAt: last.Timestamp, ID: last.ID}.Encode()
+	}
+	return page, nil
+}
+
+// Function that groups transfers of user with userID by counterparty, when reason is not empty only transfers
+// of this category are counted, counterparties with most recent transfers go first, returns summaries and error
+func (r TransactionRepository) GetCounterpartySummary(ctx context.Context, userID, reason string) ([]model.CounterpartySummary, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT u.username,
+                COALESCE(SUM(h.amount) FILTER (WHERE h.sent), 0),
+                COALESCE(SUM(h.amount) FILTER (WHERE NOT h.sent), 0),
+                COUNT(*), MIN(h.created_at), MAX(h.created_at)
+         FROM (
+             SELECT to_user_id AS counterparty_id, amount, created_at, TRUE AS sent
+             FROM transactions
+             WHERE from_user_id = $1 AND ($2 = '' OR reason = $2)
+             UNION ALL
+             SELECT from_user_id, amount, created_at, FALSE
+             FROM transactions
+             WHERE to_user_id = $1 AND ($2 = '' OR reason = $2)
+         ) h
+         JOIN users u ON u.id = h.counterparty_id
+         GROUP BY u.username
+         ORDER BY MAX(h.created_at) DESC, u.username`,
+		userID, reason,
 	)
 	if err != nil {
 		log.Printf("Database error: %v", err)
@@ -112,14 +230,40 @@ func (r TransactionRepository) GetTransactionHistory(ctx context.Context, userID
 	}
 	defer rows.Close()
 
+	summaries := make([]model.CounterpartySummary, 0)
 	for rows.Next() {
-		var t model.SentTransaction
-		if err := rows.Scan(&t.ToUser, &t.Amount, &t.Timestamp); err != nil {
+		var s model.CounterpartySummary
+		if err := rows.Scan(&s.Counterparty, &s.Sent, &s.Received, &s.Count, &s.FirstAt, &s.LastAt); err != nil {
 			log.Printf("Database error: %v", err)
 			return nil, err
 		}
-		history.Sent = append(history.Sent, t)
+		summaries = append(summaries, s)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Database error: %v", err)
+		return nil, err
 	}
+	return summaries, nil
+}
 
-	return history, nil
+// Function that counts transfers made by user with userID since given moment during transaction, returns
+// number of transfers, their total amount and error
+func (r TransactionRepository) GetSentSinceTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	userID string,
+	since time.Time,
+) (int, int, error) {
+	var count, amount int
+	err := tx.QueryRow(ctx,
+		`SELECT COUNT(*), COALESCE(SUM(amount), 0)
+         FROM transactions
+         WHERE from_user_id = $1 AND created_at >= $2`,
+		userID, since,
+	).Scan(&count, &amount)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return 0, 0, err
+	}
+	return count, amount, nil
 }
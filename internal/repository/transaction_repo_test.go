@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -16,279 +17,331 @@ import (
 
 func TestTransactionRepository_TransferCoins(t *testing.T) {
 	poolMock := new(mocks.DBMock)
-	txMock := new(mocks.TxMock)
-	batchResultsMock := new(mocks.BatchResultsMock)
 	repo := NewTransactionRepository(poolMock)
 	commandTag := new(pgconn.CommandTag)
-	rowMock := new(mocks.PgxRowMock)
 	txOptions := pgx.TxOptions{}
 	ctx := context.Background()
 
-	txMock.On("Rollback", mock.Anything).Return(nil)
-
-	t.Run("Successful transfer", func(t *testing.T) {
+	// Expects rows of users to be locked one by one with given balances
+	beginWithBalances := func(balances ...interface{}) *mocks.TxMock {
+		txMock := new(mocks.TxMock)
+		txMock.On("Rollback", mock.Anything).Return(nil)
 		poolMock.On("BeginTx", ctx, txOptions).Return(txMock, nil).Once()
 
-		txMock.On("QueryRow", ctx, mock.Anything, mock.Anything).
-			Return(rowMock).Once()
-
-		rowMock.On("Scan", mock.Anything).Run(func(args mock.Arguments) {
-			*args[0].(*int) = 1000
-		}).Return(nil).Once()
-
-		txMock.On("SendBatch", ctx, mock.Anything).
-			Return(batchResultsMock).Once()
+		for i := 0; i < len(balances); i += 2 {
+			userID, coins := balances[i].(string), balances[i+1].(int)
+			rowMock := new(mocks.PgxRowMock)
+			txMock.On("QueryRow", ctx, mock.Anything, []interface{}{userID}).Return(rowMock).Once()
+			rowMock.On("Scan", mock.Anything).Run(func(args mock.Arguments) {
+				*args[0].(*int) = coins
+			}).Return(nil).Once()
+		}
+		return txMock
+	}
 
-		batchResultsMock.On("Exec").Return(*commandTag, nil).Times(3)
+	expectBatch := func(txMock *mocks.TxMock, execErr error) {
+		batchResultsMock := new(mocks.BatchResultsMock)
+		txMock.On("SendBatch", ctx, mock.Anything).Return(batchResultsMock).Once()
+		batchResultsMock.On("Exec").Return(*commandTag, nil).Once()
+		batchResultsMock.On("Exec").Return(*commandTag, execErr).Once()
 		batchResultsMock.On("Close").Return(nil).Once()
+	}
 
+	t.Run("Successful transfer", func(t *testing.T) {
+		txMock := beginWithBalances("user1", 1000, "user2", 0)
+		expectBatch(txMock, nil)
 		txMock.On("Commit", ctx).Return(nil).Once()
 
-		err := repo.TransferCoins(ctx, "user1", "user2", 500)
+		err := repo.TransferCoins(ctx, "user1", "user2", 500, model.TransferNote{}, nil)
 		assert.NoError(t, err)
 		txMock.AssertExpectations(t)
 	})
 
+	t.Run("Rows are locked in order of id", func(t *testing.T) {
+		txMock := beginWithBalances("user1", 0, "user2", 1000)
+		expectBatch(txMock, nil)
+		txMock.On("Commit", ctx).Return(nil).Once()
+
+		err := repo.TransferCoins(ctx, "user2", "user1", 500, model.TransferNote{}, nil)
+		assert.NoError(t, err)
+
+		var locked []interface{}
+		for _, call := range txMock.Calls {
+			if call.Method == "QueryRow" {
+				locked = append(locked, call.Arguments.Get(2).([]interface{})[0])
+			}
+		}
+		assert.Equal(t, []interface{}{"user1", "user2"}, locked)
+	})
+
+	t.Run("Check runs after rows are locked", func(t *testing.T) {
+		txMock := beginWithBalances("user1", 1000, "user2", 0)
+
+		var checked pgx.Tx
+		err := repo.TransferCoins(ctx, "user1", "user2", 500, model.TransferNote{}, func(tx pgx.Tx) error {
+			checked = tx
+			assert.Len(t, txMock.Calls, 2)
+			return model.ErrDailyLimit
+		})
+		assert.ErrorIs(t, err, model.ErrDailyLimit)
+		assert.Same(t, txMock, checked)
+		txMock.AssertNotCalled(t, "SendBatch", ctx, mock.Anything)
+		txMock.AssertNotCalled(t, "Commit", ctx)
+	})
+
 	t.Run("Transaction start error", func(t *testing.T) {
 		poolMock.On("BeginTx", ctx, txOptions).
-			Return(txMock, pgx.ErrTxClosed).Once()
+			Return(new(mocks.TxMock), pgx.ErrTxClosed).Once()
 
-		err := repo.TransferCoins(ctx, "user1", "user2", 500)
+		err := repo.TransferCoins(ctx, "user1", "user2", 500, model.TransferNote{}, nil)
 		assert.ErrorIs(t, err, pgx.ErrTxClosed)
 	})
 
 	t.Run("Balance retrieval error", func(t *testing.T) {
-		poolMock.On("BeginTx", ctx, txOptions).Return(txMock, nil).Once()
-
-		txMock.On("QueryRow", ctx, mock.Anything, mock.Anything).
-			Return(rowMock).Once()
-
-		rowMock.On("Scan", mock.Anything).
-			Return(model.ErrInternalError).Once()
+		txMock := beginWithBalances()
+		rowMock := new(mocks.PgxRowMock)
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user1"}).Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).Return(model.ErrInternalError).Once()
 
-		err := repo.TransferCoins(ctx, "user1", "user2", 500)
+		err := repo.TransferCoins(ctx, "user1", "user2", 500, model.TransferNote{}, nil)
 		assert.ErrorIs(t, err, model.ErrInternalError)
 	})
 
-	t.Run("Insufficient funds", func(t *testing.T) {
-		poolMock.On("BeginTx", ctx, txOptions).Return(txMock, nil).Once()
+	t.Run("Unknown recipient", func(t *testing.T) {
+		txMock := beginWithBalances("user1", 1000)
+		rowMock := new(mocks.PgxRowMock)
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user2"}).Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).Return(pgx.ErrNoRows).Once()
 
-		txMock.On("QueryRow", ctx, mock.Anything, mock.Anything).
-			Return(rowMock).Once()
+		err := repo.TransferCoins(ctx, "user1", "user2", 500, model.TransferNote{}, nil)
+		assert.ErrorIs(t, err, model.ErrUserNotFound)
+	})
 
-		rowMock.On("Scan", mock.Anything).Run(func(args mock.Arguments) {
-			*args[0].(*int) = 200
-		}).Return(nil).Once()
+	t.Run("Insufficient funds", func(t *testing.T) {
+		txMock := beginWithBalances("user1", 200, "user2", 0)
 
-		err := repo.TransferCoins(ctx, "user1", "user2", 500)
+		err := repo.TransferCoins(ctx, "user1", "user2", 500, model.TransferNote{}, nil)
 		assert.ErrorIs(t, err, model.ErrInsufficientFunds)
+		txMock.AssertNotCalled(t, "Commit", ctx)
 	})
 
 	t.Run("Request sending error", func(t *testing.T) {
-		poolMock.On("BeginTx", ctx, txOptions).Return(txMock, nil).Once()
-
-		txMock.On("QueryRow", ctx, mock.Anything, mock.Anything).
-			Return(rowMock).Once()
-
-		rowMock.On("Scan", mock.Anything).
-			Run(func(args mock.Arguments) {
-				*args[0].(*int) = 1000
-			}).Return(nil).Once()
-
-		txMock.On("SendBatch", ctx, mock.Anything).Return(batchResultsMock).Once()
-
-		batchResultsMock.On("Exec").Return(*commandTag, nil).Twice()
-		batchResultsMock.On("Exec").Return(*commandTag, pgx.ErrTxClosed).Once()
+		txMock := beginWithBalances("user1", 1000, "user2", 0)
+		expectBatch(txMock, pgx.ErrTxClosed)
 
-		err := repo.TransferCoins(ctx, "user1", "user2", 500)
+		err := repo.TransferCoins(ctx, "user1", "user2", 500, model.TransferNote{}, nil)
 		assert.ErrorIs(t, err, pgx.ErrTxClosed)
 	})
 
 	t.Run("Transaction commit error", func(t *testing.T) {
-		poolMock.On("BeginTx", ctx, txOptions).Return(txMock, nil).Once()
+		txMock := beginWithBalances("user1", 1000, "user2", 0)
+		expectBatch(txMock, nil)
+		txMock.On("Commit", ctx).Return(pgx.ErrTxClosed).Once()
 
-		txMock.On("QueryRow", ctx, mock.Anything, mock.Anything).
-			Return(rowMock).Once()
+		err := repo.TransferCoins(ctx, "user1", "user2", 500, model.TransferNote{}, nil)
+		assert.ErrorIs(t, err, pgx.ErrTxClosed)
+	})
 
-		rowMock.On("Scan", mock.Anything).
-			Run(func(args mock.Arguments) {
-				*args[0].(*int) = 1000
-			}).Return(nil).Once()
+	t.Run("Deadlock is retried", func(t *testing.T) {
+		first := beginWithBalances("user1", 1000, "user2", 0)
+		expectBatch(first, &pgconn.PgError{Code: "40P01"})
 
-		txMock.On("SendBatch", ctx, mock.Anything).Return(batchResultsMock).Once()
+		second := beginWithBalances("user1", 1000, "user2", 0)
+		expectBatch(second, nil)
+		second.On("Commit", ctx).Return(nil).Once()
 
-		batchResultsMock.On("Exec").Return(*commandTag, nil).Times(3)
-		batchResultsMock.On("Close").Return(nil).Once()
+		err := repo.TransferCoins(ctx, "user1", "user2", 500, model.TransferNote{}, nil)
+		assert.NoError(t, err)
+		first.AssertNotCalled(t, "Commit", ctx)
+		second.AssertExpectations(t)
+	})
 
-		txMock.On("Commit", ctx).Return(pgx.ErrTxClosed).Once()
+	t.Run("Retries are bounded", func(t *testing.T) {
+		for i := 0; i < txAttempts; i++ {
+			txMock := beginWithBalances("user1", 1000, "user2", 0)
+			expectBatch(txMock, nil)
+			txMock.On("Commit", ctx).Return(&pgconn.PgError{Code: "40001"}).Once()
+		}
 
-		err := repo.TransferCoins(ctx, "user1", "user2", 500)
-		assert.ErrorIs(t, err, pgx.ErrTxClosed)
+		err := repo.TransferCoins(ctx, "user1", "user2", 500, model.TransferNote{}, nil)
+		assert.True(t, isTxConflict(err))
+		poolMock.AssertExpectations(t)
 	})
 }
 
-func TestTransactionRepository_GetTransactionHistory(t *testing.T) {
+func TestTransactionRepository_GetHistory(t *testing.T) {
 	poolMock := new(mocks.DBMock)
 	repo := NewTransactionRepository(poolMock)
 	ctx := context.Background()
-
-	recTrans := model.ReceivedTransaction{FromUser: "user2", Amount: 100, Timestamp: time.Time{}}
-
-	senTrans := model.SentTransaction{ToUser: "user3", Amount: 200, Timestamp: time.Time{}}
-
-	t.Run("Successful history retrieval", func(t *testing.T) {
-		receivedRows := new(mocks.PgxRowsMock)
-
-		poolMock.On("Query", ctx, mock.Anything, []interface{}{"user1"}).
-			Return(receivedRows, nil).Once()
-
-		receivedRows.On("Scan",
-			mock.AnythingOfType("*string"),
-			mock.AnythingOfType("*int"),
-			mock.AnythingOfType("*time.Time")).
-			Run(func(args mock.Arguments) {
-				*args[0].(*string) = recTrans.FromUser
-				*args[1].(*int) = recTrans.Amount
-				*args[2].(*time.Time) = recTrans.Timestamp
-			}).Return(nil).Twice()
-
-		receivedRows.On("Close").Return(nil).Once()
-		receivedRows.On("Next").Return(true).Twice()
-		receivedRows.On("Next").Return(false).Once()
-
-		sentRows := new(mocks.PgxRowsMock)
-
-		poolMock.On("Query", ctx, mock.Anything, []interface{}{"user1"}).Return(sentRows, nil).Once()
-
-		sentRows.On("Scan",
-			mock.AnythingOfType("*string"),
-			mock.AnythingOfType("*int"),
-			mock.AnythingOfType("*time.Time")).
-			Run(func(args mock.Arguments) {
-				*args[0].(*string) = senTrans.ToUser
-				*args[1].(*int) = senTrans.Amount
-				*args[2].(*time.Time) = senTrans.Timestamp
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// Expects query returning given number of rows, id of i-th row is UUID ending with i
+	expectRows := func(args []interface{}, n int) *mocks.PgxRowsMock {
+		rows := new(mocks.PgxRowsMock)
+		poolMock.On("Query", ctx, mock.Anything, args).Return(rows, nil).Once()
+
+		for i := 0; i < n; i++ {
+			id := fmt.Sprintf("00000000-0000-0000-0000-%012d", i+1)
+			rows.On("Next").Return(true).Once()
+			rows.On("Scan", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+				mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				*args[0].(*string) = id
+				*args[1].(*string) = model.DirectionSent
+				*args[2].(*string) = "user2"
+				*args[3].(*int) = 10
+				*args[6].(*time.Time) = created
 			}).Return(nil).Once()
+		}
+		rows.On("Next").Return(false).Once()
+		rows.On("Err").Return(nil).Once()
+		rows.On("Close").Return().Once()
+		return rows
+	}
 
-		sentRows.On("Close").Return(nil).Once()
-		sentRows.On("Next").Return(true).Once()
-		sentRows.On("Next").Return(false).Once()
+	t.Run("Last page", func(t *testing.T) {
+		expectRows([]interface{}{"user1", "", "", (*time.Time)(nil), (*time.Time)(nil), nil, nil, 3}, 2)
 
-		history, err := repo.GetTransactionHistory(ctx, "user1")
+		page, err := repo.GetHistory(ctx, "user1", model.HistoryFilter{Limit: 2})
 		assert.NoError(t, err)
-		assert.Len(t, history.Received, 2)
-		assert.Len(t, history.Sent, 1)
+		assert.Len(t, page.Entries, 2)
+		assert.Empty(t, page.NextCursor)
 	})
 
-	t.Run("First query execution error", func(t *testing.T) {
-		receivedRows := new(mocks.PgxRowsMock)
-		poolMock.On("Query", ctx, mock.Anything, []interface{}{"user1"}).
-			Return(receivedRows, model.ErrInternalError).Once()
+	t.Run("Page with cursor to the next one", func(t *testing.T) {
+		expectRows([]interface{}{"user1", "", "", (*time.Time)(nil), (*time.Time)(nil), nil, nil, 3}, 3)
 
-		_, err := repo.GetTransactionHistory(ctx, "user1")
-		assert.ErrorIs(t, err, model.ErrInternalError)
-	})
-
-	t.Run("Error reading incoming transactions response", func(t *testing.T) {
-		receivedRows := new(mocks.PgxRowsMock)
+		page, err := repo.GetHistory(ctx, "user1", model.HistoryFilter{Limit: 2})
+		assert.NoError(t, err)
+		assert.Len(t, page.Entries, 2)
 
-		poolMock.On("Query", ctx, mock.Anything, []interface{}{"user1"}).
-			Return(receivedRows, nil).Once()
+		cursor, err := model.ParseHistoryCursor(page.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, "00000000-0000-0000-0000-000000000002", cursor.ID)
+		assert.True(t, created.Equal(cursor.CreatedAt))
+	})
 
-		receivedRows.On("Scan",
-			mock.AnythingOfType("*string"),
-			mock.AnythingOfType("*int"),
-			mock.AnythingOfType("*time.Time")).
-			Run(func(args mock.Arguments) {
-				*args[0].(*string) = recTrans.FromUser
-				*args[1].(*int) = recTrans.Amount
-				*args[2].(*time.Time) = recTrans.Timestamp
-			}).Return(nil).Once()
+	t.Run("Filters passed to query", func(t *testing.T) {
+		from := created.Add(-time.Hour)
+		cursor := &model.HistoryCursor{CreatedAt: created, ID: "tx9"}
+		filter := model.HistoryFilter{
+			Direction:    model.DirectionSent,
+			Counterparty: "user2",
+			Reason:       model.ReasonGift,
+			From:         &from,
+			Cursor:       cursor,
+			Limit:        10,
+		}
+		expectRows([]interface{}{"user1", "user2", model.ReasonGift, &from, (*time.Time)(nil), created, "tx9", 11}, 0)
+
+		page, err := repo.GetHistory(ctx, "user1", filter)
+		assert.NoError(t, err)
+		assert.Empty(t, page.Entries)
 
-		receivedRows.On("Scan",
-			mock.AnythingOfType("*string"),
-			mock.AnythingOfType("*int"),
-			mock.AnythingOfType("*time.Time")).Run(func(args mock.Arguments) {
-			*args[0].(*string) = recTrans.FromUser
-			*args[1].(*int) = recTrans.Amount
-			*args[2].(*time.Time) = recTrans.Timestamp
-		}).Return(model.ErrInternalError).Once()
+		query := poolMock.Calls[len(poolMock.Calls)-1].Arguments.String(1)
+		assert.Contains(t, query, "t.from_user_id = $1")
+		assert.NotContains(t, query, "t.to_user_id = $1")
+	})
 
-		receivedRows.On("Close").Return(nil).Once()
-		receivedRows.On("Next").Return(true).Twice()
-		receivedRows.On("Next").Return(false).Once()
+	t.Run("Query error", func(t *testing.T) {
+		poolMock.On("Query", ctx, mock.Anything, mock.Anything).
+			Return(new(mocks.PgxRowsMock), model.ErrInternalError).Once()
 
-		_, err := repo.GetTransactionHistory(ctx, "user1")
+		_, err := repo.GetHistory(ctx, "user1", model.HistoryFilter{Limit: 2})
 		assert.ErrorIs(t, err, model.ErrInternalError)
 	})
 
-	t.Run("Second query execution error", func(t *testing.T) {
-		receivedRows := new(mocks.PgxRowsMock)
+	t.Run("Scan error", func(t *testing.T) {
+		rows := new(mocks.PgxRowsMock)
+		poolMock.On("Query", ctx, mock.Anything, mock.Anything).Return(rows, nil).Once()
+		rows.On("Next").Return(true).Once()
+		rows.On("Scan", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything, mock.Anything, mock.Anything).Return(model.ErrInternalError).Once()
+		rows.On("Close").Return().Once()
 
-		poolMock.On("Query", ctx, mock.Anything, []interface{}{"user1"}).Return(receivedRows, nil).Once()
+		_, err := repo.GetHistory(ctx, "user1", model.HistoryFilter{Limit: 2})
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
 
-		receivedRows.On("Scan",
-			mock.AnythingOfType("*string"),
-			mock.AnythingOfType("*int"),
-			mock.AnythingOfType("*time.Time")).
+func TestTransactionRepository_GetCounterpartySummary(t *testing.T) {
+	poolMock := new(mocks.DBMock)
+	repo := NewTransactionRepository(poolMock)
+	ctx := context.Background()
+	first := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	last := time.Date(2025, 2, 1, 18, 0, 0, 0, time.UTC)
+
+	t.Run("Grouped by counterparty", func(t *testing.T) {
+		rows := new(mocks.PgxRowsMock)
+		poolMock.On("Query", ctx, mock.Anything, []interface{}{"user1", model.ReasonThanks}).Return(rows, nil).Once()
+		rows.On("Next").Return(true).Once()
+		rows.On("Scan", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 			Run(func(args mock.Arguments) {
-				*args[0].(*string) = recTrans.FromUser
-				*args[1].(*int) = recTrans.Amount
-				*args[2].(*time.Time) = recTrans.Timestamp
+				*args[0].(*string) = "user2"
+				*args[1].(*int) = 30
+				*args[2].(*int) = 5
+				*args[3].(*int) = 4
+				*args[4].(*time.Time) = first
+				*args[5].(*time.Time) = last
 			}).Return(nil).Once()
+		rows.On("Next").Return(false).Once()
+		rows.On("Err").Return(nil).Once()
+		rows.On("Close").Return().Once()
 
-		receivedRows.On("Close").Return(nil).Once()
-		receivedRows.On("Next").Return(true).Once()
-		receivedRows.On("Next").Return(false).Once()
-
-		sentRows := new(mocks.PgxRowsMock)
-
-		poolMock.On("Query", ctx, mock.Anything, []interface{}{"user1"}).
-			Return(sentRows, model.ErrInternalError).Once()
-
-		_, err := repo.GetTransactionHistory(ctx, "user1")
-		assert.ErrorIs(t, err, model.ErrInternalError)
+		summary, err := repo.GetCounterpartySummary(ctx, "user1", model.ReasonThanks)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.CounterpartySummary{
+			{Counterparty: "user2", Sent: 30, Received: 5, Count: 4, FirstAt: first, LastAt: last},
+		}, summary)
+		assert.Contains(t, poolMock.Calls[len(poolMock.Calls)-1].Arguments.String(1), "GROUP BY u.username")
 	})
 
-	t.Run("Error reading outgoing transactions response", func(t *testing.T) {
-		receivedRows := new(mocks.PgxRowsMock)
+	t.Run("No transfers", func(t *testing.T) {
+		rows := new(mocks.PgxRowsMock)
+		poolMock.On("Query", ctx, mock.Anything, []interface{}{"user3", ""}).Return(rows, nil).Once()
+		rows.On("Next").Return(false).Once()
+		rows.On("Err").Return(nil).Once()
+		rows.On("Close").Return().Once()
 
-		poolMock.On("Query", ctx, mock.Anything, []interface{}{"user1"}).
-			Return(receivedRows, nil).Once()
+		summary, err := repo.GetCounterpartySummary(ctx, "user3", "")
+		assert.NoError(t, err)
+		assert.NotNil(t, summary)
+		assert.Empty(t, summary)
+	})
 
-		receivedRows.On("Scan",
-			mock.AnythingOfType("*string"),
-			mock.AnythingOfType("*int"),
-			mock.AnythingOfType("*time.Time")).
-			Run(func(args mock.Arguments) {
-				*args[0].(*string) = recTrans.FromUser
-				*args[1].(*int) = recTrans.Amount
-				*args[2].(*time.Time) = recTrans.Timestamp
-			}).Return(nil).Twice()
+	t.Run("Query error", func(t *testing.T) {
+		poolMock.On("Query", ctx, mock.Anything, []interface{}{"user1", ""}).
+			Return(new(mocks.PgxRowsMock), model.ErrInternalError).Once()
 
-		receivedRows.On("Close").Return(nil).Once()
-		receivedRows.On("Next").Return(true).Twice()
-		receivedRows.On("Next").Return(false).Once()
+		_, err := repo.GetCounterpartySummary(ctx, "user1", "")
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
 
-		sentRows := new(mocks.PgxRowsMock)
+func TestTransactionRepository_GetSentSinceTx(t *testing.T) {
+	txMock := new(mocks.TxMock)
+	repo := NewTransactionRepository(new(mocks.DBMock))
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 
-		poolMock.On("Query", ctx, mock.Anything, []interface{}{"user1"}).
-			Return(sentRows, nil).Once()
+	t.Run("Transfers counted", func(t *testing.T) {
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user1", since}).Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			*args[0].(*int) = 3
+			*args[1].(*int) = 250
+		}).Return(nil).Once()
 
-		sentRows.On("Scan",
-			mock.AnythingOfType("*string"),
-			mock.AnythingOfType("*int"),
-			mock.AnythingOfType("*time.Time")).
-			Run(func(args mock.Arguments) {
-				*args[0].(*string) = senTrans.ToUser
-				*args[1].(*int) = senTrans.Amount
-				*args[2].(*time.Time) = senTrans.Timestamp
-			}).Return(model.ErrInternalError).Once()
+		count, amount, err := repo.GetSentSinceTx(ctx, txMock, "user1", since)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, 250, amount)
+	})
 
-		sentRows.On("Close").Return(nil).Once()
-		sentRows.On("Next").Return(true).Once()
+	t.Run("Database error", func(t *testing.T) {
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user1", since}).Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything, mock.Anything).Return(model.ErrInternalError).Once()
 
-		_, err := repo.GetTransactionHistory(ctx, "user1")
+		_, _, err := repo.GetSentSinceTx(ctx, txMock, "user1", since)
 		assert.ErrorIs(t, err, model.ErrInternalError)
 	})
 }
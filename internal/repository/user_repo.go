@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 
 	"github.com/garaevmir/avitocoinstore/internal/model"
 )
@@ -12,9 +14,14 @@ import (
 // Interface for user repository, needed for testing
 type UserRepositoryInt interface {
 	CreateUser(ctx context.Context, user *model.User) error
+	CreateUserTx(ctx context.Context, tx pgx.Tx, user *model.User) error
 	GetUserByID(ctx context.Context, userID string) (*model.User, error)
 	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
-	UpdateUserCoinsTx(ctx context.Context, tx pgx.Tx, userID string, delta int) error
+	UpdatePassword(ctx context.Context, userID, passwordHash string) error
+	UpdatePasswordTx(ctx context.Context, tx pgx.Tx, userID, passwordHash string) error
+	GetCoinsForUpdateTx(ctx context.Context, tx pgx.Tx, userID string) (int, error)
+	SetStatus(ctx context.Context, userID, status string) error
+	SetRole(ctx context.Context, userID, role string) error
 	BeginTx(ctx context.Context) (pgx.Tx, error)
 }
 
@@ -28,18 +35,48 @@ func NewUserRepository(db DB) *UserRepository {
 	return &UserRepository{pool: db}
 }
 
-// Function that writes user to database and assigns userID, returns error
+// Function that writes user to database together with grant of user.Coins initial coins and assigns userID,
+// returns ErrUserExists if username is taken and error
 func (r UserRepository) CreateUser(ctx context.Context, user *model.User) error {
-	err := r.pool.QueryRow(ctx,
-		`INSERT INTO users (username, password_hash, coins) 
-         VALUES ($1, $2, $3)
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := r.CreateUserTx(ctx, tx, user); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Transaction commit error: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Function that writes user to database together with grant of user.Coins initial coins during transaction
+// and assigns userID, returns ErrUserExists if username is taken and error
+func (r UserRepository) CreateUserTx(ctx context.Context, tx pgx.Tx, user *model.User) error {
+	err := tx.QueryRow(ctx,
+		`INSERT INTO users (username, password_hash)
+         VALUES ($1, $2)
 		 RETURNING id`,
-		user.Username, user.PasswordHash, user.Coins,
+		user.Username, user.PasswordHash,
 	).Scan(&user.ID)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return model.ErrUserExists
+		}
 		log.Printf("Error creating user: %v\n", err)
 		return err
 	}
+
+	if user.Coins > 0 {
+		return postTx(ctx, tx, model.GrantPosting(user.ID, user.Coins))
+	}
 	return nil
 }
 
@@ -48,10 +85,10 @@ func (r UserRepository) CreateUser(ctx context.Context, user *model.User) error
 func (r UserRepository) GetUserByUsername(ctx context.Context, username string) (*model.User, error) {
 	var user model.User
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, username, password_hash, coins 
+		`SELECT id, username, password_hash, coins, role, status
          FROM users WHERE username = $1`,
 		username,
-	).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Coins)
+	).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Coins, &user.Role, &user.Status)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -68,10 +105,10 @@ func (r UserRepository) GetUserByUsername(ctx context.Context, username string)
 func (r UserRepository) GetUserByID(ctx context.Context, userID string) (*model.User, error) {
 	var user model.User
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, username, password_hash, coins 
+		`SELECT id, username, password_hash, coins, role, status
          FROM users WHERE id = $1`,
 		userID,
-	).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Coins)
+	).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Coins, &user.Role, &user.Status)
 	return &user, err
 }
 
@@ -80,11 +117,71 @@ func (r UserRepository) BeginTx(ctx context.Context) (pgx.Tx, error) {
 	return r.pool.BeginTx(ctx, pgx.TxOptions{})
 }
 
-// Function that updates amount of money available for user with userID
-func (r UserRepository) UpdateUserCoinsTx(ctx context.Context, tx pgx.Tx, userID string, delta int) error {
-	_, err := tx.Exec(ctx,
-		"UPDATE users SET coins = coins + $1 WHERE id = $2",
-		delta, userID,
-	)
-	return err
+// Function that replaces password hash of user with userID, returns ErrUserNotFound if there is no such user
+// and error
+func (r UserRepository) UpdatePassword(ctx context.Context, userID, passwordHash string) error {
+	tag, err := r.pool.Exec(ctx, updatePasswordQuery, userID, passwordHash)
+	return updatePassword(tag, err)
+}
+
+// Function that replaces password hash of user with userID during transaction, returns ErrUserNotFound
+// if there is no such user and error
+func (r UserRepository) UpdatePasswordTx(ctx context.Context, tx pgx.Tx, userID, passwordHash string) error {
+	tag, err := tx.Exec(ctx, updatePasswordQuery, userID, passwordHash)
+	return updatePassword(tag, err)
+}
+
+// Query that replaces password hash, shared by UpdatePassword and UpdatePasswordTx
+const updatePasswordQuery = `UPDATE users SET password_hash = $2 WHERE id = $1`
+
+// Checks result of password update
+func updatePassword(tag pgconn.CommandTag, err error) error {
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return model.ErrUserNotFound
+	}
+	return nil
+}
+
+// Function that returns balance of user with userID and locks it until end of transaction, returns balance and error
+func (r UserRepository) GetCoinsForUpdateTx(ctx context.Context, tx pgx.Tx, userID string) (int, error) {
+	var coins int
+	err := tx.QueryRow(ctx, "SELECT coins FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&coins)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, model.ErrUserNotFound
+		}
+		log.Printf("Database error: %v", err)
+		return 0, err
+	}
+	return coins, nil
+}
+
+// Function that changes status of user with userID, returns ErrUserNotFound if there is no such user and error
+func (r UserRepository) SetStatus(ctx context.Context, userID, status string) error {
+	tag, err := r.pool.Exec(ctx, "UPDATE users SET status = $2 WHERE id = $1", userID, status)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return model.ErrUserNotFound
+	}
+	return nil
+}
+
+// Function that changes role of user with userID, returns ErrUserNotFound if there is no such user and error
+func (r UserRepository) SetRole(ctx context.Context, userID, role string) error {
+	tag, err := r.pool.Exec(ctx, "UPDATE users SET role = $2 WHERE id = $1", userID, role)
+	if err != nil {
+		log.Printf("Database error: %v", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return model.ErrUserNotFound
+	}
+	return nil
 }
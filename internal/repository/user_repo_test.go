@@ -6,7 +6,6 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
@@ -18,16 +17,19 @@ func TestUserRepository_CreateUser(t *testing.T) {
 	dbMock := new(mocks.DBMock)
 	userRepo := NewUserRepository(dbMock)
 	rowMock := new(mocks.PgxRowMock)
+	commandTag := new(pgconn.CommandTag)
 	ctx := context.Background()
 
 	t.Run("Successful user creation", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
 		testUser := &model.User{
 			Username:     "test_user",
 			PasswordHash: "hash",
 			Coins:        100,
 		}
 
-		dbMock.On("QueryRow", ctx, mock.Anything, []interface{}{"test_user", "hash", 100}).
+		dbMock.On("BeginTx", ctx, pgx.TxOptions{}).Return(txMock, nil).Once()
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"test_user", "hash"}).
 			Return(rowMock).Once()
 
 		rowMock.On("Scan", mock.Anything).
@@ -35,18 +37,27 @@ func TestUserRepository_CreateUser(t *testing.T) {
 				*args[0].(*string) = "generated-id-123"
 			}).Return(nil).Once()
 
+		txMock.On("Exec", ctx, postingQuery, postingArgs(model.GrantPosting("generated-id-123", 100))).
+			Return(*commandTag, nil).Once()
+		txMock.On("Commit", ctx).Return(nil).Once()
+		txMock.On("Rollback", ctx).Return(nil).Once()
+
 		err := userRepo.CreateUser(ctx, testUser)
 		assert.NoError(t, err)
 		assert.Equal(t, "generated-id-123", testUser.ID)
 		dbMock.AssertExpectations(t)
+		txMock.AssertExpectations(t)
 		rowMock.AssertExpectations(t)
 	})
 
 	t.Run("Error inserting into database", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
 		testUser := &model.User{Username: "error_user"}
 
-		dbMock.On("QueryRow", ctx, mock.Anything, mock.Anything).
+		dbMock.On("BeginTx", ctx, pgx.TxOptions{}).Return(txMock, nil).Once()
+		txMock.On("QueryRow", ctx, mock.Anything, mock.Anything).
 			Return(rowMock).Once()
+		txMock.On("Rollback", ctx).Return(nil).Once()
 
 		rowMock.On("Scan", mock.Anything).
 			Return(pgx.ErrNoRows).Once()
@@ -54,6 +65,67 @@ func TestUserRepository_CreateUser(t *testing.T) {
 		err := userRepo.CreateUser(ctx, testUser)
 		assert.ErrorIs(t, err, pgx.ErrNoRows)
 		assert.Empty(t, testUser.ID)
+		txMock.AssertNotCalled(t, "Commit", ctx)
+	})
+
+	t.Run("Transaction start error", func(t *testing.T) {
+		dbMock.On("BeginTx", ctx, pgx.TxOptions{}).Return(new(mocks.TxMock), pgx.ErrTxClosed).Once()
+
+		err := userRepo.CreateUser(ctx, &model.User{Username: "user"})
+		assert.ErrorIs(t, err, pgx.ErrTxClosed)
+	})
+}
+
+func TestUserRepository_CreateUserTx(t *testing.T) {
+	userRepo := NewUserRepository(new(mocks.DBMock))
+	txMock := new(mocks.TxMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+
+	t.Run("Successful user creation", func(t *testing.T) {
+		testUser := &model.User{Username: "test_user", PasswordHash: "hash", Coins: 1000}
+
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"test_user", "hash"}).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).
+			Run(func(args mock.Arguments) {
+				*args[0].(*string) = "generated-id-123"
+			}).Return(nil).Once()
+		txMock.On("Exec", ctx, postingQuery, postingArgs(model.GrantPosting("generated-id-123", 1000))).
+			Return(pgconn.CommandTag{}, nil).Once()
+
+		err := userRepo.CreateUserTx(ctx, txMock, testUser)
+		assert.NoError(t, err)
+		assert.Equal(t, "generated-id-123", testUser.ID)
+		txMock.AssertExpectations(t)
+	})
+
+	t.Run("Username taken", func(t *testing.T) {
+		testUser := &model.User{Username: "taken_user"}
+
+		txMock.On("QueryRow", ctx, mock.Anything, mock.Anything).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).
+			Return(&pgconn.PgError{Code: "23505"}).Once()
+
+		err := userRepo.CreateUserTx(ctx, txMock, testUser)
+		assert.ErrorIs(t, err, model.ErrUserExists)
+	})
+
+	t.Run("Grant error", func(t *testing.T) {
+		testUser := &model.User{Username: "grant_user", PasswordHash: "hash", Coins: 1000}
+
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"grant_user", "hash"}).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).
+			Run(func(args mock.Arguments) {
+				*args[0].(*string) = "user2"
+			}).Return(nil).Once()
+		txMock.On("Exec", ctx, postingQuery, postingArgs(model.GrantPosting("user2", 1000))).
+			Return(pgconn.CommandTag{}, model.ErrInternalError).Once()
+
+		err := userRepo.CreateUserTx(ctx, txMock, testUser)
+		assert.ErrorIs(t, err, model.ErrInternalError)
 	})
 }
 
@@ -68,6 +140,8 @@ func TestUserRepository_GetUserByUsername(t *testing.T) {
 		Username:     "test_user",
 		PasswordHash: "hash",
 		Coins:        100,
+		Role:         model.RoleEmployee,
+		Status:       model.UserActive,
 	}
 
 	t.Run("Successful user retrieval", func(t *testing.T) {
@@ -79,11 +153,15 @@ func TestUserRepository_GetUserByUsername(t *testing.T) {
 			mock.AnythingOfType("*string"),
 			mock.AnythingOfType("*string"),
 			mock.AnythingOfType("*int"),
+			mock.AnythingOfType("*string"),
+			mock.AnythingOfType("*string"),
 		).Run(func(args mock.Arguments) {
 			*args[0].(*string) = testUser.ID
 			*args[1].(*string) = testUser.Username
 			*args[2].(*string) = testUser.PasswordHash
 			*args[3].(*int) = testUser.Coins
+			*args[4].(*string) = testUser.Role
+			*args[5].(*string) = testUser.Status
 		}).Return(nil).Once()
 
 		user, err := userRepo.GetUserByUsername(ctx, "test_user")
@@ -102,11 +180,15 @@ func TestUserRepository_GetUserByUsername(t *testing.T) {
 			mock.AnythingOfType("*string"),
 			mock.AnythingOfType("*string"),
 			mock.AnythingOfType("*int"),
+			mock.AnythingOfType("*string"),
+			mock.AnythingOfType("*string"),
 		).Run(func(args mock.Arguments) {
 			*args[0].(*string) = testUser.ID
 			*args[1].(*string) = testUser.Username
 			*args[2].(*string) = testUser.PasswordHash
 			*args[3].(*int) = testUser.Coins
+			*args[4].(*string) = testUser.Role
+			*args[5].(*string) = testUser.Status
 		}).Return(pgx.ErrNoRows).Once()
 
 		user, err := userRepo.GetUserByUsername(ctx, "unknown_user")
@@ -125,11 +207,15 @@ func TestUserRepository_GetUserByUsername(t *testing.T) {
 			mock.AnythingOfType("*string"),
 			mock.AnythingOfType("*string"),
 			mock.AnythingOfType("*int"),
+			mock.AnythingOfType("*string"),
+			mock.AnythingOfType("*string"),
 		).Run(func(args mock.Arguments) {
 			*args[0].(*string) = testUser.ID
 			*args[1].(*string) = testUser.Username
 			*args[2].(*string) = testUser.PasswordHash
 			*args[3].(*int) = testUser.Coins
+			*args[4].(*string) = testUser.Role
+			*args[5].(*string) = testUser.Status
 		}).Return(expectedErr).Once()
 
 		user, err := userRepo.GetUserByUsername(ctx, "error_user")
@@ -149,6 +235,8 @@ func TestUserRepository_GetUserByID(t *testing.T) {
 		Username:     "test_user",
 		PasswordHash: "hash",
 		Coins:        100,
+		Role:         model.RoleEmployee,
+		Status:       model.UserActive,
 	}
 
 	t.Run("User found by ID", func(t *testing.T) {
@@ -160,11 +248,15 @@ func TestUserRepository_GetUserByID(t *testing.T) {
 			mock.Anything,
 			mock.Anything,
 			mock.Anything,
+			mock.Anything,
+			mock.Anything,
 		).Run(func(args mock.Arguments) {
 			*args[0].(*string) = testUser.ID
 			*args[1].(*string) = testUser.Username
 			*args[2].(*string) = testUser.PasswordHash
 			*args[3].(*int) = testUser.Coins
+			*args[4].(*string) = testUser.Role
+			*args[5].(*string) = testUser.Status
 		}).Return(nil).Once()
 
 		user, err := userRepo.GetUserByID(ctx, "123")
@@ -183,11 +275,15 @@ func TestUserRepository_GetUserByID(t *testing.T) {
 			mock.Anything,
 			mock.Anything,
 			mock.Anything,
+			mock.Anything,
+			mock.Anything,
 		).Run(func(args mock.Arguments) {
 			*args[0].(*string) = testUser.ID
 			*args[1].(*string) = testUser.Username
 			*args[2].(*string) = testUser.PasswordHash
 			*args[3].(*int) = testUser.Coins
+			*args[4].(*string) = testUser.Role
+			*args[5].(*string) = testUser.Status
 		}).Return(model.ErrInternalError).Once()
 
 		_, err := userRepo.GetUserByID(ctx, "123")
@@ -225,26 +321,118 @@ func TestUserRepository_BeginTx(t *testing.T) {
 	})
 }
 
-func TestUserRepository_UpdateUserCoinsTx(t *testing.T) {
-	poolMock := &pgxpool.Pool{}
-	userRepoMock := NewUserRepository(poolMock)
+func TestUserRepository_UpdatePassword(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	userRepo := NewUserRepository(dbMock)
+	ctx := context.Background()
+
+	t.Run("Successful update", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user1", "hash"}).
+			Return(pgconn.NewCommandTag("UPDATE 1"), nil).Once()
+
+		assert.NoError(t, userRepo.UpdatePassword(ctx, "user1", "hash"))
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"ghost", "hash"}).
+			Return(pgconn.NewCommandTag("UPDATE 0"), nil).Once()
+
+		assert.ErrorIs(t, userRepo.UpdatePassword(ctx, "ghost", "hash"), model.ErrUserNotFound)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user2", "hash"}).
+			Return(pgconn.CommandTag{}, model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, userRepo.UpdatePassword(ctx, "user2", "hash"), model.ErrInternalError)
+	})
+}
+
+func TestUserRepository_SetStatus(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	userRepo := NewUserRepository(dbMock)
+	ctx := context.Background()
+
+	t.Run("Status changed", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user1", model.UserFrozen}).
+			Return(pgconn.NewCommandTag("UPDATE 1"), nil).Once()
+
+		assert.NoError(t, userRepo.SetStatus(ctx, "user1", model.UserFrozen))
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"ghost", model.UserFrozen}).
+			Return(pgconn.NewCommandTag("UPDATE 0"), nil).Once()
+
+		assert.ErrorIs(t, userRepo.SetStatus(ctx, "ghost", model.UserFrozen), model.ErrUserNotFound)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user2", model.UserActive}).
+			Return(pgconn.CommandTag{}, model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, userRepo.SetStatus(ctx, "user2", model.UserActive), model.ErrInternalError)
+	})
+}
+
+func TestUserRepository_SetRole(t *testing.T) {
+	dbMock := new(mocks.DBMock)
+	userRepo := NewUserRepository(dbMock)
+	ctx := context.Background()
+
+	t.Run("Role changed", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"user1", model.RoleAdmin}).
+			Return(pgconn.NewCommandTag("UPDATE 1"), nil).Once()
+
+		assert.NoError(t, userRepo.SetRole(ctx, "user1", model.RoleAdmin))
+		dbMock.AssertExpectations(t)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		dbMock.On("Exec", ctx, mock.Anything, []interface{}{"ghost", model.RoleAdmin}).
+			Return(pgconn.NewCommandTag("UPDATE 0"), nil).Once()
+
+		assert.ErrorIs(t, userRepo.SetRole(ctx, "ghost", model.RoleAdmin), model.ErrUserNotFound)
+	})
+}
+
+func TestUserRepository_UpdatePasswordTx(t *testing.T) {
+	userRepo := NewUserRepository(new(mocks.DBMock))
 	txMock := new(mocks.TxMock)
-	commandTag := new(pgconn.CommandTag)
 	ctx := context.Background()
 
-	t.Run("Success coins update", func(t *testing.T) {
-		txMock.On("Exec", ctx, mock.Anything, []interface{}{50, "user1"}).
-			Return(*commandTag, nil).Once()
+	txMock.On("Exec", ctx, mock.Anything, []interface{}{"user1", "hash"}).
+		Return(pgconn.NewCommandTag("UPDATE 1"), nil).Once()
 
-		err := userRepoMock.UpdateUserCoinsTx(ctx, txMock, "user1", 50)
+	assert.NoError(t, userRepo.UpdatePasswordTx(ctx, txMock, "user1", "hash"))
+	txMock.AssertExpectations(t)
+}
+
+func TestUserRepository_GetCoinsForUpdateTx(t *testing.T) {
+	repo := NewUserRepository(new(mocks.DBMock))
+	txMock := new(mocks.TxMock)
+	rowMock := new(mocks.PgxRowMock)
+	ctx := context.Background()
+
+	t.Run("Balance locked", func(t *testing.T) {
+		txMock.On("QueryRow", ctx, "SELECT coins FROM users WHERE id = $1 FOR UPDATE", []interface{}{"user1"}).
+			Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).Run(func(args mock.Arguments) {
+			*args[0].(*int) = 700
+		}).Return(nil).Once()
+
+		coins, err := repo.GetCoinsForUpdateTx(ctx, txMock, "user1")
 		assert.NoError(t, err)
+		assert.Equal(t, 700, coins)
 	})
 
-	t.Run("Database error on update", func(t *testing.T) {
-		txMock.On("Exec", ctx, mock.Anything, []interface{}{50, "user1"}).
-			Return(*commandTag, model.ErrInternalError).Once()
+	t.Run("Unknown user", func(t *testing.T) {
+		txMock.On("QueryRow", ctx, mock.Anything, []interface{}{"user2"}).Return(rowMock).Once()
+		rowMock.On("Scan", mock.Anything).Return(pgx.ErrNoRows).Once()
 
-		err := userRepoMock.UpdateUserCoinsTx(ctx, txMock, "user1", 50)
-		assert.Error(t, err)
+		_, err := repo.GetCoinsForUpdateTx(ctx, txMock, "user2")
+		assert.ErrorIs(t, err, model.ErrUserNotFound)
 	})
 }
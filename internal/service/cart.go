@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+)
+
+// Structure for carts that users fill over time and check out at once
+type CartService struct {
+	cartRepo    repository.CartRepositoryInt
+	catalogRepo repository.CatalogRepositoryInt
+	shop        *ShopService
+}
+
+// Constructor for cart service, checkout buys through shop
+func NewCartService(cRepo repository.CartRepositoryInt, catRepo repository.CatalogRepositoryInt, shop *ShopService) *CartService {
+	return &CartService{cartRepo: cRepo, catalogRepo: catRepo, shop: shop}
+}
+
+// Function that sets quantity of item in cart of user with userID at current catalog price, returns error
+func (s *CartService) SetItem(ctx context.Context, userID, itemName string, quantity int) error {
+	if quantity <= 0 {
+		return model.ErrInvalidRequest
+	}
+
+	item, err := s.catalogRepo.GetItemByName(ctx, itemName)
+	if err != nil {
+		log.Printf("Error getting item: %v", err)
+		return err
+	}
+	if item == nil || !item.Active {
+		return model.ErrItemNotFound
+	}
+
+	return s.cartRepo.SetItem(ctx, userID, item.Name, quantity, item.Price)
+}
+
+// Function that removes item from cart of user with userID, returns error
+func (s *CartService) RemoveItem(ctx context.Context, userID, itemName string) error {
+	removed, err := s.cartRepo.RemoveItem(ctx, userID, itemName)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return model.ErrItemNotFound
+	}
+	return nil
+}
+
+// Function that returns cart of user with userID with its total and error
+func (s *CartService) GetCart(ctx context.Context, userID string) (*model.CartResponse, error) {
+	items, err := s.cartRepo.GetCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart := &model.CartResponse{Items: items}
+	for _, item := range items {
+		cart.Total += item.UnitPrice * item.Quantity
+	}
+	return cart, nil
+}
+
+// Function that buys cart of user with userID and removes bought lines in one transaction. When catalog price of
+// any line differs from price it was added at, nothing is bought and ErrPriceChanged is returned with changed lines
+func (s *CartService) Checkout(ctx context.Context, userID string) ([]model.Order, []model.PriceChange, error) {
+	// Lines are locked in the purchase transaction, so they are bought exactly as they are compared and removed
+	var cart []model.CartItem
+	load := func(tx pgx.Tx) ([]model.OrderLine, error) {
+		var err error
+		cart, err = s.cartRepo.GetCartForUpdateTx(ctx, tx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(cart) == 0 {
+			return nil, model.ErrCartEmpty
+		}
+
+		lines := make([]model.OrderLine, 0, len(cart))
+		for _, line := range cart {
+			lines = append(lines, model.OrderLine{Item: line.Item, Quantity: line.Quantity})
+		}
+		return lines, nil
+	}
+
+	var changes []model.PriceChange
+	orders, err := s.shop.purchase(ctx, userID, load, func(tx pgx.Tx, items []*model.Item) error {
+		bought := make([]string, 0, len(cart))
+		for i, line := range cart {
+			if items[i].Price != line.UnitPrice {
+				changes = append(changes, model.PriceChange{Item: line.Item, OldPrice: line.UnitPrice, NewPrice: items[i].Price})
+			}
+			bought = append(bought, line.Item)
+		}
+		if len(changes) > 0 {
+			return model.ErrPriceChanged
+		}
+		// Lines added while checkout was running stay in cart
+		return s.cartRepo.RemoveItemsTx(ctx, tx, userID, bought)
+	})
+	if err != nil {
+		return nil, changes, err
+	}
+	return orders, nil, nil
+}
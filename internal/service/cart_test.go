@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestCartService_SetItem(t *testing.T) {
+	cartRepo := new(mocks.CartRepositoryMock)
+	catRepo := new(mocks.CatalogRepositoryMock)
+	cartSvc := NewCartService(cartRepo, catRepo, nil)
+	ctx := context.Background()
+
+	catRepo.On("GetItemByName", ctx, "hoody").
+		Return(&model.Item{Name: "hoody", Price: 300, Active: true}, nil)
+	catRepo.On("GetItemByName", ctx, "retired_item").
+		Return(&model.Item{Name: "retired_item", Price: 10, Active: false}, nil)
+
+	t.Run("Line stored at current price", func(t *testing.T) {
+		cartRepo.On("SetItem", ctx, "user1", "hoody", 2, 300).Return(nil).Once()
+
+		assert.NoError(t, cartSvc.SetItem(ctx, "user1", "hoody", 2))
+		cartRepo.AssertExpectations(t)
+	})
+
+	t.Run("Inactive item", func(t *testing.T) {
+		assert.ErrorIs(t, cartSvc.SetItem(ctx, "user1", "retired_item", 1), model.ErrItemNotFound)
+	})
+
+	t.Run("Invalid quantity", func(t *testing.T) {
+		assert.ErrorIs(t, cartSvc.SetItem(ctx, "user1", "hoody", 0), model.ErrInvalidRequest)
+	})
+}
+
+func TestCartService_RemoveItem(t *testing.T) {
+	cartRepo := new(mocks.CartRepositoryMock)
+	cartSvc := NewCartService(cartRepo, new(mocks.CatalogRepositoryMock), nil)
+	ctx := context.Background()
+
+	t.Run("Line removed", func(t *testing.T) {
+		cartRepo.On("RemoveItem", ctx, "user1", "hoody").Return(true, nil).Once()
+
+		assert.NoError(t, cartSvc.RemoveItem(ctx, "user1", "hoody"))
+	})
+
+	t.Run("Line not in cart", func(t *testing.T) {
+		cartRepo.On("RemoveItem", ctx, "user1", "pen").Return(false, nil).Once()
+
+		assert.ErrorIs(t, cartSvc.RemoveItem(ctx, "user1", "pen"), model.ErrItemNotFound)
+	})
+}
+
+func TestCartService_GetCart(t *testing.T) {
+	cartRepo := new(mocks.CartRepositoryMock)
+	cartSvc := NewCartService(cartRepo, new(mocks.CatalogRepositoryMock), nil)
+	ctx := context.Background()
+
+	cartRepo.On("GetCart", ctx, "user1").Return([]model.CartItem{
+		{Item: "hoody", Quantity: 2, UnitPrice: 300},
+		{Item: "pen", Quantity: 3, UnitPrice: 10},
+	}, nil).Once()
+
+	cart, err := cartSvc.GetCart(ctx, "user1")
+	assert.NoError(t, err)
+	assert.Len(t, cart.Items, 2)
+	assert.Equal(t, 630, cart.Total)
+}
+
+func TestCartService_Checkout(t *testing.T) {
+	userRepo := new(mocks.UserRepositoryMock)
+	invRepo := new(mocks.InventoryRepositoryMock)
+	catRepo := new(mocks.CatalogRepositoryMock)
+	ledgerRepo := new(mocks.LedgerRepositoryMock)
+	orderRepo := new(mocks.OrderRepositoryMock)
+	cartRepo := new(mocks.CartRepositoryMock)
+	shopSvc := NewShopService(userRepo, new(mocks.TransactionRepositoryMock), invRepo, catRepo, ledgerRepo, orderRepo)
+	cartSvc := NewCartService(cartRepo, catRepo, shopSvc)
+	ctx := context.Background()
+
+	catRepo.On("GetItemForShareTx", ctx, mock.Anything, "hoody").
+		Return(&model.Item{Name: "hoody", Price: 350, Active: true}, nil)
+	catRepo.On("GetItemForShareTx", ctx, mock.Anything, "pen").
+		Return(&model.Item{Name: "pen", Price: 10, Active: true}, nil)
+
+	t.Run("Successful checkout removes bought lines", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		txMock.On("Rollback", ctx).Return(nil).Once()
+		txMock.On("Commit", ctx).Return(nil).Once()
+
+		cartRepo.On("GetCartForUpdateTx", ctx, txMock, "user1").Return([]model.CartItem{
+			{Item: "hoody", Quantity: 1, UnitPrice: 350},
+			{Item: "pen", Quantity: 2, UnitPrice: 10},
+		}, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", ctx, mock.Anything, "user1").Return(1000, nil).Once()
+		userRepo.On("BeginTx", ctx).Return(txMock, nil).Once()
+		ledgerRepo.On("PostTx", ctx, txMock, model.PurchasePosting("user1", 350, "hoody")).Return(nil).Once()
+		ledgerRepo.On("PostTx", ctx, txMock, model.PurchasePosting("user1", 20, "pen")).Return(nil).Once()
+		invRepo.On("AddToInventoryTx", ctx, txMock, "user1", "hoody", 1).Return(nil).Once()
+		invRepo.On("AddToInventoryTx", ctx, txMock, "user1", "pen", 2).Return(nil).Once()
+		orderRepo.On("CreateOrderTx", ctx, txMock, mock.AnythingOfType("*model.Order")).Return(nil).Twice()
+		cartRepo.On("RemoveItemsTx", ctx, txMock, "user1", []string{"hoody", "pen"}).Return(nil).Once()
+
+		orders, changes, err := cartSvc.Checkout(ctx, "user1")
+		assert.NoError(t, err)
+		assert.Nil(t, changes)
+		assert.Len(t, orders, 2)
+		cartRepo.AssertExpectations(t)
+		txMock.AssertExpectations(t)
+	})
+
+	t.Run("Price changed since line was added", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		txMock.On("Rollback", ctx).Return(nil).Once()
+
+		cartRepo.On("GetCartForUpdateTx", ctx, txMock, "user1").Return([]model.CartItem{
+			{Item: "hoody", Quantity: 1, UnitPrice: 300},
+			{Item: "pen", Quantity: 2, UnitPrice: 10},
+		}, nil).Once()
+		userRepo.On("BeginTx", ctx).Return(txMock, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", ctx, txMock, "user1").Return(1000, nil).Once()
+
+		_, changes, err := cartSvc.Checkout(ctx, "user1")
+		assert.ErrorIs(t, err, model.ErrPriceChanged)
+		assert.Equal(t, []model.PriceChange{{Item: "hoody", OldPrice: 300, NewPrice: 350}}, changes)
+		txMock.AssertNotCalled(t, "Commit", ctx)
+	})
+
+	t.Run("Empty cart", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		txMock.On("Rollback", ctx).Return(nil).Once()
+
+		userRepo.On("BeginTx", ctx).Return(txMock, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", ctx, txMock, "user1").Return(1000, nil).Once()
+		cartRepo.On("GetCartForUpdateTx", ctx, txMock, "user1").Return([]model.CartItem{}, nil).Once()
+
+		_, _, err := cartSvc.Checkout(ctx, "user1")
+		assert.ErrorIs(t, err, model.ErrCartEmpty)
+		txMock.AssertNotCalled(t, "Commit", ctx)
+	})
+
+	t.Run("Clearing cart fails", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		txMock.On("Rollback", ctx).Return(nil).Once()
+
+		cartRepo.On("GetCartForUpdateTx", ctx, txMock, "user1").Return([]model.CartItem{{Item: "pen", Quantity: 1, UnitPrice: 10}}, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", ctx, mock.Anything, "user1").Return(1000, nil).Once()
+		userRepo.On("BeginTx", ctx).Return(txMock, nil).Once()
+		ledgerRepo.On("PostTx", ctx, txMock, model.PurchasePosting("user1", 10, "pen")).Return(nil).Once()
+		invRepo.On("AddToInventoryTx", ctx, txMock, "user1", "pen", 1).Return(nil).Once()
+		orderRepo.On("CreateOrderTx", ctx, txMock, mock.AnythingOfType("*model.Order")).Return(nil).Once()
+		cartRepo.On("RemoveItemsTx", ctx, txMock, "user1", []string{"pen"}).Return(model.ErrInternalError).Once()
+
+		_, _, err := cartSvc.Checkout(ctx, "user1")
+		assert.ErrorIs(t, err, model.ErrInternalError)
+		txMock.AssertNotCalled(t, "Commit", ctx)
+	})
+}
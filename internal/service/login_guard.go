@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+)
+
+// Limits of failed logins, after UserAttempts failures for username or IPAttempts failures from single IP
+// further logins are locked for BaseDelay, every next failure doubles the lock up to MaxDelay,
+// failures older than Window are forgotten
+type LockoutPolicy struct {
+	UserAttempts int
+	IPAttempts   int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	Window       time.Duration
+}
+
+// Structure that tracks failed logins per username and per IP and locks them out
+type LoginGuard struct {
+	attemptRepo repository.LoginAttemptRepositoryInt
+	policy      LockoutPolicy
+}
+
+// Constructor for the login guard
+func NewLoginGuard(aRepo repository.LoginAttemptRepositoryInt, policy LockoutPolicy) *LoginGuard {
+	return &LoginGuard{attemptRepo: aRepo, policy: policy}
+}
+
+// Function that checks whether login of username from ip is locked, returns time left until lock ends
+// or zero and error
+func (g *LoginGuard) Check(ctx context.Context, username, ip string) (time.Duration, error) {
+	return g.attemptRepo.GetLockRemaining(ctx, []string{userKey(username), ipKey(ip)})
+}
+
+// Function that records failed login of username from ip and locks them when limits are exceeded, returns error
+func (g *LoginGuard) Failure(ctx context.Context, username, ip string) error {
+	if err := g.failure(ctx, userKey(username), g.policy.UserAttempts); err != nil {
+		return err
+	}
+	return g.failure(ctx, ipKey(ip), g.policy.IPAttempts)
+}
+
+// Function that forgets failed logins of username after successful login, failures from IP are kept
+// so a single known password does not reset guessing of others, returns error
+func (g *LoginGuard) Success(ctx context.Context, username string) error {
+	return g.attemptRepo.Reset(ctx, []string{userKey(username)})
+}
+
+// Function that lifts lock of username, returns error
+func (g *LoginGuard) Unlock(ctx context.Context, username string) error {
+	return g.attemptRepo.Reset(ctx, []string{userKey(username)})
+}
+
+// Counts failure for key and locks it if the failure exceeds limit
+func (g *LoginGuard) failure(ctx context.Context, key string, limit int) error {
+	failures, err := g.attemptRepo.RecordFailure(ctx, key, g.policy.Window)
+	if err != nil {
+		return err
+	}
+
+	delay := g.lockDelay(failures, limit)
+	if delay == 0 {
+		return nil
+	}
+
+	log.Printf("Login locked for %s after %d failures, retry in %v", key, failures, delay)
+	return g.attemptRepo.Lock(ctx, key, delay)
+}
+
+// Computes lock duration for given number of failures, zero while failures are under limit
+func (g *LoginGuard) lockDelay(failures, limit int) time.Duration {
+	if limit <= 0 || failures < limit {
+		return 0
+	}
+
+	delay := g.policy.BaseDelay
+	for i := limit; i < failures && delay < g.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > g.policy.MaxDelay {
+		delay = g.policy.MaxDelay
+	}
+	return delay
+}
+
+func userKey(username string) string {
+	return "user:" + username
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
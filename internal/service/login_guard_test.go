@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+var testPolicy = LockoutPolicy{
+	UserAttempts: 3,
+	IPAttempts:   10,
+	BaseDelay:    time.Second,
+	MaxDelay:     10 * time.Second,
+	Window:       time.Hour,
+}
+
+func TestLoginGuard_Failure(t *testing.T) {
+	attemptRepo := new(mocks.LoginAttemptRepositoryMock)
+	guard := NewLoginGuard(attemptRepo, testPolicy)
+	ctx := context.Background()
+
+	t.Run("Under limit", func(t *testing.T) {
+		attemptRepo.On("RecordFailure", ctx, "user:alice", time.Hour).Return(2, nil).Once()
+		attemptRepo.On("RecordFailure", ctx, "ip:10.0.0.1", time.Hour).Return(2, nil).Once()
+
+		assert.NoError(t, guard.Failure(ctx, "alice", "10.0.0.1"))
+		attemptRepo.AssertNotCalled(t, "Lock", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Limit reached locks username", func(t *testing.T) {
+		attemptRepo.On("RecordFailure", ctx, "user:alice", time.Hour).Return(3, nil).Once()
+		attemptRepo.On("Lock", ctx, "user:alice", time.Second).Return(nil).Once()
+		attemptRepo.On("RecordFailure", ctx, "ip:10.0.0.1", time.Hour).Return(3, nil).Once()
+
+		assert.NoError(t, guard.Failure(ctx, "alice", "10.0.0.1"))
+		attemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("Limit reached locks IP", func(t *testing.T) {
+		attemptRepo.On("RecordFailure", ctx, "user:bob", time.Hour).Return(1, nil).Once()
+		attemptRepo.On("RecordFailure", ctx, "ip:10.0.0.1", time.Hour).Return(12, nil).Once()
+		attemptRepo.On("Lock", ctx, "ip:10.0.0.1", 4*time.Second).Return(nil).Once()
+
+		assert.NoError(t, guard.Failure(ctx, "bob", "10.0.0.1"))
+		attemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		attemptRepo.On("RecordFailure", ctx, "user:carol", time.Hour).Return(0, model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, guard.Failure(ctx, "carol", "10.0.0.1"), model.ErrInternalError)
+	})
+}
+
+func TestLoginGuard_lockDelay(t *testing.T) {
+	guard := NewLoginGuard(nil, testPolicy)
+
+	assert.Equal(t, time.Duration(0), guard.lockDelay(2, 3))
+	assert.Equal(t, time.Second, guard.lockDelay(3, 3))
+	assert.Equal(t, 2*time.Second, guard.lockDelay(4, 3))
+	assert.Equal(t, 8*time.Second, guard.lockDelay(6, 3))
+	assert.Equal(t, 10*time.Second, guard.lockDelay(7, 3))
+	assert.Equal(t, 10*time.Second, guard.lockDelay(1000, 3))
+	assert.Equal(t, time.Duration(0), guard.lockDelay(1000, 0))
+}
+
+func TestLoginGuard_CheckAndReset(t *testing.T) {
+	attemptRepo := new(mocks.LoginAttemptRepositoryMock)
+	guard := NewLoginGuard(attemptRepo, testPolicy)
+	ctx := context.Background()
+
+	attemptRepo.On("GetLockRemaining", ctx, []string{"user:alice", "ip:10.0.0.1"}).
+		Return(5*time.Second, nil).Once()
+	remaining, err := guard.Check(ctx, "alice", "10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, remaining)
+
+	attemptRepo.On("Reset", ctx, []string{"user:alice"}).Return(nil).Twice()
+	assert.NoError(t, guard.Success(ctx, "alice"))
+	assert.NoError(t, guard.Unlock(ctx, "alice"))
+	attemptRepo.AssertExpectations(t)
+}
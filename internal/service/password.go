@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+)
+
+// Rules every password chosen by user has to follow, checked on registration, change and reset
+type PasswordPolicy struct {
+	MinLength int
+}
+
+// Function that checks password of user with username against the policy, returns error describing
+// the violated rule
+func (p PasswordPolicy) Validate(username, password string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return model.ErrPasswordTooShort
+	}
+	if strings.EqualFold(password, username) {
+		return model.ErrPasswordIsUsername
+	}
+	return nil
+}
+
+// Structure responsible for password changes and administrator initiated resets
+type PasswordService struct {
+	userRepo    repository.UserRepositoryInt
+	resetRepo   repository.PasswordResetRepositoryInt
+	revocations *RevocationService
+	tokens      *TokenService
+	policy      PasswordPolicy
+	resetTTL    time.Duration
+}
+
+// Constructor for the password service
+func NewPasswordService(
+	uRepo repository.UserRepositoryInt,
+	rRepo repository.PasswordResetRepositoryInt,
+	revocations *RevocationService,
+	tokens *TokenService,
+	policy PasswordPolicy,
+	resetTTL time.Duration,
+) *PasswordService {
+	return &PasswordService{
+		userRepo:    uRepo,
+		resetRepo:   rRepo,
+		revocations: revocations,
+		tokens:      tokens,
+		policy:      policy,
+		resetTTL:    resetTTL,
+	}
+}
+
+// Function that changes password of user with userID if oldPassword matches, every other session of the user
+// is revoked, returns new pair of tokens for the current session and error
+func (s *PasswordService) ChangePassword(ctx context.Context, userID, oldPassword, newPassword string) (*model.AuthResponse, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(oldPassword)); err != nil {
+		return nil, model.ErrInvalidCredentials
+	}
+
+	if err := s.policy.Validate(user.Username, newPassword); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := hashPassword(newPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, userID, passwordHash); err != nil {
+		return nil, err
+	}
+
+	if err := s.revokeSessions(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.tokens.Issue(ctx, user)
+}
+
+// Function that issues one-time password reset token for user with userID on behalf of administrator
+// with createdBy, returns token, its expiration and error
+func (s *PasswordService) CreateResetToken(ctx context.Context, userID, createdBy string) (*model.PasswordResetResponse, error) {
+	token, err := randomString(32)
+	if err != nil {
+		log.Printf("Reset token generation error: %v", err)
+		return nil, err
+	}
+
+	reset := &model.PasswordReset{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		CreatedBy: createdBy,
+		ExpiresAt: time.Now().Add(s.resetTTL),
+	}
+	if err := s.resetRepo.CreateReset(ctx, reset); err != nil {
+		return nil, err
+	}
+
+	return &model.PasswordResetResponse{Token: token, ExpiresAt: reset.ExpiresAt}, nil
+}
+
+// Function that sets new password using one-time reset token, the token stays usable if new password
+// is rejected by the policy, every session of the user is revoked, returns error
+func (s *PasswordService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	tx, err := s.userRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	reset, err := s.resetRepo.ConsumeResetTx(ctx, tx, hashToken(token))
+	if err != nil {
+		return err
+	}
+	if reset == nil {
+		return model.ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, reset.UserID)
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		return err
+	}
+
+	if err := s.policy.Validate(user.Username, newPassword); err != nil {
+		return err
+	}
+
+	passwordHash, err := hashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePasswordTx(ctx, tx, reset.UserID, passwordHash); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Transaction commit error: %v", err)
+		return err
+	}
+
+	return s.revokeSessions(ctx, reset.UserID)
+}
+
+// Revokes every token of user issued so far
+func (s *PasswordService) revokeSessions(ctx context.Context, userID string) error {
+	return s.revocations.RevokeUserTokens(ctx, userID, time.Now())
+}
+
+// Hashes password for storage
+func hashPassword(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("Password hashing error: %v", err)
+		return "", err
+	}
+	return string(hashedPassword), nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+type passwordServiceMocks struct {
+	userRepo       *mocks.UserRepositoryMock
+	resetRepo      *mocks.PasswordResetRepositoryMock
+	refreshRepo    *mocks.RefreshTokenRepositoryMock
+	revocationRepo *mocks.RevocationRepositoryMock
+}
+
+func newTestPasswordService(t *testing.T) (*PasswordService, *RevocationService, passwordServiceMocks) {
+	m := passwordServiceMocks{
+		userRepo:       new(mocks.UserRepositoryMock),
+		resetRepo:      new(mocks.PasswordResetRepositoryMock),
+		refreshRepo:    new(mocks.RefreshTokenRepositoryMock),
+		revocationRepo: new(mocks.RevocationRepositoryMock),
+	}
+	revocations := NewRevocationService(m.revocationRepo, m.refreshRepo, time.Minute)
+	tokens := NewTokenService(m.userRepo, m.refreshRepo, newTestKeys(t), time.Minute, time.Hour)
+	passwords := NewPasswordService(m.userRepo, m.resetRepo, revocations, tokens, PasswordPolicy{MinLength: 8}, time.Hour)
+	return passwords, revocations, m
+}
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 8}
+
+	assert.NoError(t, policy.Validate("alice", "long enough"))
+	assert.ErrorIs(t, policy.Validate("alice", "short"), model.ErrPasswordTooShort)
+	assert.ErrorIs(t, policy.Validate("alice", "пароль"), model.ErrPasswordTooShort)
+	assert.ErrorIs(t, policy.Validate("alice_smith", "Alice_Smith"), model.ErrPasswordIsUsername)
+}
+
+func TestPasswordService_ChangePassword(t *testing.T) {
+	passwords, revocations, m := newTestPasswordService(t)
+	ctx := context.Background()
+
+	hashedPass, _ := bcrypt.GenerateFromPassword([]byte("old_password"), bcrypt.DefaultCost)
+	user := &model.User{ID: "user1", Username: "alice", PasswordHash: string(hashedPass), Role: model.RoleEmployee}
+
+	t.Run("Successful change", func(t *testing.T) {
+		m.userRepo.On("GetUserByID", ctx, "user1").Return(user, nil).Once()
+		m.userRepo.On("UpdatePassword", ctx, "user1", mock.MatchedBy(func(hash string) bool {
+			return bcrypt.CompareHashAndPassword([]byte(hash), []byte("new_password")) == nil
+		})).Return(nil).Once()
+		m.revocationRepo.On("RevokeUserTokens", ctx, "user1", mock.Anything).Return(nil).Once()
+		m.refreshRepo.On("RevokeUserTokens", ctx, "user1", mock.Anything).Return(nil).Once()
+		m.refreshRepo.On("CreateRefreshToken", ctx, mock.Anything).Return(nil).Once()
+
+		tokens, err := passwords.ChangePassword(ctx, "user1", "old_password", "new_password")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokens.RefreshToken)
+
+		claims := jwt.MapClaims{}
+		_, _, err = jwt.NewParser().ParseUnverified(tokens.Token, claims)
+		assert.NoError(t, err)
+		iat, _ := claims.GetIssuedAt()
+		assert.True(t, revocations.IsRevoked("old", "user1", iat.Add(-time.Second)))
+		assert.False(t, revocations.IsRevoked(claims["jti"].(string), "user1", iat.Time))
+		m.userRepo.AssertExpectations(t)
+	})
+
+	t.Run("Wrong old password", func(t *testing.T) {
+		m.userRepo.On("GetUserByID", ctx, "user1").Return(user, nil).Once()
+
+		_, err := passwords.ChangePassword(ctx, "user1", "wrong_password", "new_password")
+		assert.ErrorIs(t, err, model.ErrInvalidCredentials)
+	})
+
+	t.Run("New password violates policy", func(t *testing.T) {
+		m.userRepo.On("GetUserByID", ctx, "user1").Return(user, nil).Once()
+
+		_, err := passwords.ChangePassword(ctx, "user1", "old_password", "short")
+		assert.ErrorIs(t, err, model.ErrPasswordTooShort)
+	})
+}
+
+func TestPasswordService_CreateResetToken(t *testing.T) {
+	passwords, _, m := newTestPasswordService(t)
+	ctx := context.Background()
+
+	var stored *model.PasswordReset
+	m.resetRepo.On("CreateReset", ctx, mock.Anything).
+		Run(func(args mock.Arguments) {
+			stored = args[1].(*model.PasswordReset)
+		}).Return(nil).Once()
+
+	reset, err := passwords.CreateResetToken(ctx, "user1", "admin1")
+	assert.NoError(t, err)
+	assert.Equal(t, hashToken(reset.Token), stored.TokenHash)
+	assert.Equal(t, "user1", stored.UserID)
+	assert.Equal(t, "admin1", stored.CreatedBy)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), reset.ExpiresAt, 2*time.Second)
+}
+
+func TestPasswordService_ResetPassword(t *testing.T) {
+	passwords, _, m := newTestPasswordService(t)
+	ctx := context.Background()
+	user := &model.User{ID: "user1", Username: "alice"}
+
+	t.Run("Successful reset", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		m.userRepo.On("BeginTx", ctx).Return(txMock, nil).Once()
+		m.resetRepo.On("ConsumeResetTx", ctx, txMock, hashToken("token")).
+			Return(&model.PasswordReset{UserID: "user1"}, nil).Once()
+		m.userRepo.On("GetUserByID", ctx, "user1").Return(user, nil).Once()
+		m.userRepo.On("UpdatePasswordTx", ctx, txMock, "user1", mock.Anything).Return(nil).Once()
+		txMock.On("Commit", ctx).Return(nil).Once()
+		txMock.On("Rollback", ctx).Return(nil).Once()
+		m.revocationRepo.On("RevokeUserTokens", ctx, "user1", mock.Anything).Return(nil).Once()
+		m.refreshRepo.On("RevokeUserTokens", ctx, "user1", mock.Anything).Return(nil).Once()
+
+		assert.NoError(t, passwords.ResetPassword(ctx, "token", "new_password"))
+		txMock.AssertExpectations(t)
+		m.revocationRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown or used token", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		m.userRepo.On("BeginTx", ctx).Return(txMock, nil).Once()
+		m.resetRepo.On("ConsumeResetTx", ctx, txMock, hashToken("used")).Return(nil, nil).Once()
+		txMock.On("Rollback", ctx).Return(nil).Once()
+
+		assert.ErrorIs(t, passwords.ResetPassword(ctx, "used", "new_password"), model.ErrInvalidResetToken)
+	})
+
+	t.Run("Rejected password keeps token usable", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		m.userRepo.On("BeginTx", ctx).Return(txMock, nil).Once()
+		m.resetRepo.On("ConsumeResetTx", ctx, txMock, hashToken("token")).
+			Return(&model.PasswordReset{UserID: "user1"}, nil).Once()
+		m.userRepo.On("GetUserByID", ctx, "user1").Return(user, nil).Once()
+		txMock.On("Rollback", ctx).Return(nil).Once()
+
+		assert.ErrorIs(t, passwords.ResetPassword(ctx, "token", "alice"), model.ErrPasswordTooShort)
+		txMock.AssertNotCalled(t, "Commit", ctx)
+	})
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+)
+
+// Structure for auditing cached balances of users against their coin movements
+type ReconcileService struct {
+	ledgerRepo repository.LedgerRepositoryInt
+}
+
+// Constructor for reconciliation service
+func NewReconcileService(lRepo repository.LedgerRepositoryInt) *ReconcileService {
+	return &ReconcileService{ledgerRepo: lRepo}
+}
+
+// Function that recomputes expected balance of every user from grants, transfers, orders and adjustments
+// and compares ledger with it and cached balance with ledger, with mismatchesOnly only users whose ledger
+// mismatches or whose cached balance drifted are returned, returns reports and error
+func (s *ReconcileService) Report(ctx context.Context, mismatchesOnly bool) ([]model.BalanceReport, error) {
+	balances, err := s.ledgerRepo.GetBalanceReports(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]model.BalanceReport, 0, len(balances))
+	for _, b := range balances {
+		if mismatchesOnly && !b.Mismatched() && !b.Drifted() {
+			continue
+		}
+		reports = append(reports, b)
+	}
+	return reports, nil
+}
+
+// Function that writes correction posting with reason for every user whose ledger differs from expected balance,
+// cached balance is changed by the same amount, drift of cached balance is left as is, returns reports
+// of corrected users and error
+func (s *ReconcileService) Fix(ctx context.Context, reason string) ([]model.BalanceReport, error) {
+	mismatches, err := s.Report(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	fixed := make([]model.BalanceReport, 0, len(mismatches))
+	for _, m := range mismatches {
+		if !m.Mismatched() {
+			continue
+		}
+		correction, err := s.ledgerRepo.ReconcileBalance(ctx, m.UserID, reason)
+		if err != nil {
+			if err == model.ErrUserNotFound {
+				continue
+			}
+			return fixed, err
+		}
+		if correction == 0 {
+			continue
+		}
+		m.Corrected += correction
+		m.Ledger += correction
+		m.Coins += correction
+		m.Compute()
+		fixed = append(fixed, m)
+	}
+	return fixed, nil
+}
+
+var reportHeader = []string{
+	"user_id", "username", "coins", "ledger", "expected", "ledger_difference", "cache_drift",
+	"granted", "received", "sent", "spent", "refunded", "adjusted", "corrected",
+}
+
+// Function that writes reports to w as CSV with header, returns error
+func WriteReportsCSV(w io.Writer, reports []model.BalanceReport) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(reportHeader); err != nil {
+		return err
+	}
+	for _, r := range reports {
+		record := []string{r.UserID, r.Username}
+		for _, v := range []int{
+			r.Coins, r.Ledger, r.Expected, r.LedgerDifference, r.CacheDrift,
+			r.Granted, r.Received, r.Sent, r.Spent, r.Refunded, r.Adjusted, r.Corrected,
+		} {
+			record = append(record, strconv.Itoa(v))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Function that writes reports to w as JSON array, returns error
+func WriteReportsJSON(w io.Writer, reports []model.BalanceReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(reports)
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+// Alice is consistent, ledger and cached balance of bob show 50 coins nothing explains
+func balances() []model.BalanceReport {
+	reports := []model.BalanceReport{
+		{UserID: "user1", Username: "alice", Coins: 700, Ledger: 700, Granted: 1000, Sent: 100, Spent: 200},
+		{UserID: "user2", Username: "bob", Coins: 1150, Ledger: 1150, Granted: 1000, Received: 100},
+		{UserID: "user3", Username: "carol", Coins: 990, Ledger: 1000, Granted: 1000},
+	}
+	for i := range reports {
+		reports[i].Compute()
+	}
+	return reports
+}
+
+func TestReconcileService_Report(t *testing.T) {
+	ledgerRepo := new(mocks.LedgerRepositoryMock)
+	reconciler := NewReconcileService(ledgerRepo)
+	ctx := context.Background()
+
+	t.Run("All users", func(t *testing.T) {
+		ledgerRepo.On("GetBalanceReports", ctx).Return(balances(), nil).Once()
+
+		reports, err := reconciler.Report(ctx, false)
+		assert.NoError(t, err)
+		assert.Len(t, reports, 3)
+		assert.Equal(t, 700, reports[0].Expected)
+		assert.Zero(t, reports[0].LedgerDifference)
+		assert.Equal(t, 1100, reports[1].Expected)
+		assert.Equal(t, 50, reports[1].LedgerDifference)
+		assert.True(t, reports[1].Mismatched())
+	})
+
+	t.Run("Mismatches and drift only", func(t *testing.T) {
+		ledgerRepo.On("GetBalanceReports", ctx).Return(balances(), nil).Once()
+
+		reports, err := reconciler.Report(ctx, true)
+		assert.NoError(t, err)
+		assert.Len(t, reports, 2)
+		assert.Equal(t, "bob", reports[0].Username)
+		assert.Equal(t, "carol", reports[1].Username)
+	})
+
+	t.Run("Cached balance drifted from ledger", func(t *testing.T) {
+		ledgerRepo.On("GetBalanceReports", ctx).Return(balances(), nil).Once()
+
+		reports, err := reconciler.Report(ctx, true)
+		assert.NoError(t, err)
+		assert.Equal(t, -10, reports[1].CacheDrift)
+		assert.Zero(t, reports[1].LedgerDifference)
+		assert.True(t, reports[1].Drifted())
+		assert.False(t, reports[1].Mismatched())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		ledgerRepo.On("GetBalanceReports", ctx).Return([]model.BalanceReport(nil), model.ErrInternalError).Once()
+
+		_, err := reconciler.Report(ctx, false)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestReconcileService_Fix(t *testing.T) {
+	ledgerRepo := new(mocks.LedgerRepositoryMock)
+	reconciler := NewReconcileService(ledgerRepo)
+	ctx := context.Background()
+
+	t.Run("Successful fix", func(t *testing.T) {
+		ledgerRepo.On("GetBalanceReports", ctx).Return(balances(), nil).Once()
+		ledgerRepo.On("ReconcileBalance", ctx, "user2", "audit").Return(-50, nil).Once()
+
+		fixed, err := reconciler.Fix(ctx, "audit")
+		assert.NoError(t, err)
+		assert.Len(t, fixed, 1)
+		assert.Equal(t, -50, fixed[0].Corrected)
+		assert.Equal(t, 1100, fixed[0].Coins)
+		assert.Zero(t, fixed[0].CacheDrift)
+		assert.Zero(t, fixed[0].LedgerDifference)
+		ledgerRepo.AssertExpectations(t)
+		ledgerRepo.AssertNotCalled(t, "ReconcileBalance", ctx, "user3", "audit")
+	})
+
+	t.Run("Balance fixed concurrently", func(t *testing.T) {
+		ledgerRepo.On("GetBalanceReports", ctx).Return(balances(), nil).Once()
+		ledgerRepo.On("ReconcileBalance", ctx, "user2", "audit").Return(0, nil).Once()
+
+		fixed, err := reconciler.Fix(ctx, "audit")
+		assert.NoError(t, err)
+		assert.Empty(t, fixed)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		ledgerRepo.On("GetBalanceReports", ctx).Return(balances(), nil).Once()
+		ledgerRepo.On("ReconcileBalance", ctx, "user2", "audit").Return(0, model.ErrInternalError).Once()
+
+		_, err := reconciler.Fix(ctx, "audit")
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestWriteReportsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteReportsCSV(&buf, []model.BalanceReport{
+		{UserID: "user2", Username: "bob", Coins: 1150, Ledger: 1100, Expected: 1100, CacheDrift: 50,
+			Granted: 1000, Received: 100},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"user_id,username,coins,ledger,expected,ledger_difference,cache_drift,"+
+			"granted,received,sent,spent,refunded,adjusted,corrected\n"+
+			"user2,bob,1150,1100,1100,0,50,1000,100,0,0,0,0,0\n",
+		buf.String())
+}
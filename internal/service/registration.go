@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+)
+
+// Amount of coins every new user starts with
+const InitialCoins = 1000
+
+// Example passwords that are never accepted for bootstrapped admin
+var placeholderPasswords = []string{"admin", "password", "changeme", "change-me", "secret"}
+
+// Structure responsible for creating users and invite codes
+type RegistrationService struct {
+	userRepo   repository.UserRepositoryInt
+	inviteRepo repository.InviteRepositoryInt
+	policy     PasswordPolicy
+	allowList  map[string]struct{}
+	inviteTTL  time.Duration
+}
+
+// Constructor for the registration service, users from allowList can register without invite code
+func NewRegistrationService(
+	uRepo repository.UserRepositoryInt,
+	iRepo repository.InviteRepositoryInt,
+	policy PasswordPolicy,
+	allowList []string,
+	inviteTTL time.Duration,
+) *RegistrationService {
+	allowed := make(map[string]struct{}, len(allowList))
+	for _, username := range allowList {
+		allowed[username] = struct{}{}
+	}
+
+	return &RegistrationService{
+		userRepo:   uRepo,
+		inviteRepo: iRepo,
+		policy:     policy,
+		allowList:  allowed,
+		inviteTTL:  inviteTTL,
+	}
+}
+
+// Function that registers user if username is in allow-list or inviteCode is usable and password follows
+// the policy, invite is used up in the same transaction the user is created in, returns created user and error
+func (s *RegistrationService) Register(ctx context.Context, username, password, inviteCode string) (*model.User, error) {
+	if username == "" || password == "" {
+		return nil, model.ErrInvalidCredentials
+	}
+	if err := s.policy.Validate(username, password); err != nil {
+		return nil, err
+	}
+
+	if _, ok := s.allowList[username]; ok {
+		return s.AutoRegister(ctx, username, password)
+	}
+	if inviteCode == "" {
+		return nil, model.ErrRegistrationClosed
+	}
+
+	user, err := newUser(username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.userRepo.BeginTx(ctx)
+	if err != nil {
+		log.Printf("Transaction error: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.userRepo.CreateUserTx(ctx, tx, user); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.inviteRepo.UseInviteTx(ctx, tx, hashToken(inviteCode), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, model.ErrInvalidInvite
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Printf("Transaction commit error: %v", err)
+		return nil, err
+	}
+	return user, nil
+}
+
+// Function that registers user without checking allow-list or invite codes, used by registration of
+// allowed users and by compatibility mode of login, which accepts any password as the original assignment does,
+// returns created user and error
+func (s *RegistrationService) AutoRegister(ctx context.Context, username, password string) (*model.User, error) {
+	user, err := newUser(username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Function that makes user with username an admin, the user is created with password first if there is none,
+// used to get the first admin on fresh database, placeholder password is refused with ErrPlaceholderPassword,
+// returns error
+func (s *RegistrationService) BootstrapAdmin(ctx context.Context, username, password string) error {
+	if isPlaceholderPassword(password) {
+		return model.ErrPlaceholderPassword
+	}
+
+	user, err := s.userRepo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		if err := s.policy.Validate(username, password); err != nil {
+			return err
+		}
+		if user, err = s.AutoRegister(ctx, username, password); err != nil {
+			return err
+		}
+	} else if user.Role == model.RoleAdmin {
+		return nil
+	}
+
+	return s.userRepo.SetRole(ctx, user.ID, model.RoleAdmin)
+}
+
+// Function that tells whether password is one of example passwords or is derived from them
+func isPlaceholderPassword(password string) bool {
+	password = strings.ToLower(password)
+	for _, placeholder := range placeholderPasswords {
+		if password == placeholder || strings.HasPrefix(password, placeholder+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// Function that creates single use invite code on behalf of user with createdBy, invite expires at expiresAt
+// or after default lifetime if it is zero, returns invite code, its expiration and error
+func (s *RegistrationService) CreateInvite(ctx context.Context, createdBy string, expiresAt time.Time) (*model.InviteResponse, error) {
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(s.inviteTTL)
+	}
+
+	code, err := randomString(16)
+	if err != nil {
+		log.Printf("Invite code generation error: %v", err)
+		return nil, err
+	}
+
+	err = s.inviteRepo.CreateInvite(ctx, &model.Invite{
+		CodeHash:  hashToken(code),
+		CreatedBy: createdBy,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.InviteResponse{Code: code, ExpiresAt: expiresAt}, nil
+}
+
+// Builds new user with hashed password and initial coins
+func newUser(username, password string) (*model.User, error) {
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.User{
+		Username:     username,
+		PasswordHash: passwordHash,
+		Coins:        InitialCoins,
+		Role:         model.RoleEmployee,
+	}, nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestRegistrationService_Register(t *testing.T) {
+	userRepo := new(mocks.UserRepositoryMock)
+	inviteRepo := new(mocks.InviteRepositoryMock)
+	txMock := new(mocks.TxMock)
+	registration := NewRegistrationService(userRepo, inviteRepo, PasswordPolicy{MinLength: 8}, []string{"allowed"}, time.Hour)
+	ctx := context.Background()
+
+	t.Run("Allowed username", func(t *testing.T) {
+		userRepo.On("CreateUser", ctx, mock.MatchedBy(func(u *model.User) bool {
+			return u.Username == "allowed" && u.Coins == InitialCoins &&
+				bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte("password1")) == nil
+		})).Return(nil).Once()
+
+		user, err := registration.Register(ctx, "allowed", "password1", "")
+		assert.NoError(t, err)
+		assert.Equal(t, "allowed", user.Username)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("No invite code", func(t *testing.T) {
+		_, err := registration.Register(ctx, "stranger", "password1", "")
+		assert.ErrorIs(t, err, model.ErrRegistrationClosed)
+	})
+
+	t.Run("Empty credentials", func(t *testing.T) {
+		_, err := registration.Register(ctx, "", "", "code")
+		assert.ErrorIs(t, err, model.ErrInvalidCredentials)
+	})
+
+	t.Run("Password violates policy", func(t *testing.T) {
+		_, err := registration.Register(ctx, "allowed", "short", "")
+		assert.ErrorIs(t, err, model.ErrPasswordTooShort)
+
+		_, err = registration.Register(ctx, "longusername", "LongUserName", "code")
+		assert.ErrorIs(t, err, model.ErrPasswordIsUsername)
+	})
+
+	t.Run("Valid invite code", func(t *testing.T) {
+		userRepo.On("BeginTx", ctx).Return(txMock, nil).Once()
+		userRepo.On("CreateUserTx", ctx, txMock, mock.Anything).
+			Run(func(args mock.Arguments) {
+				args[2].(*model.User).ID = "user1"
+			}).Return(nil).Once()
+		inviteRepo.On("UseInviteTx", ctx, txMock, hashToken("code"), "user1").Return(true, nil).Once()
+		txMock.On("Commit", ctx).Return(nil).Once()
+		txMock.On("Rollback", ctx).Return(nil).Once()
+
+		user, err := registration.Register(ctx, "invited", "password1", "code")
+		assert.NoError(t, err)
+		assert.Equal(t, "user1", user.ID)
+		inviteRepo.AssertExpectations(t)
+		txMock.AssertExpectations(t)
+	})
+
+	t.Run("Used invite code", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		userRepo.On("BeginTx", ctx).Return(txMock, nil).Once()
+		userRepo.On("CreateUserTx", ctx, txMock, mock.Anything).Return(nil).Once()
+		inviteRepo.On("UseInviteTx", ctx, txMock, hashToken("used"), mock.Anything).Return(false, nil).Once()
+		txMock.On("Rollback", ctx).Return(nil).Once()
+
+		_, err := registration.Register(ctx, "invited2", "password1", "used")
+		assert.ErrorIs(t, err, model.ErrInvalidInvite)
+		txMock.AssertNotCalled(t, "Commit", ctx)
+	})
+
+	t.Run("Username taken", func(t *testing.T) {
+		userRepo.On("BeginTx", ctx).Return(txMock, nil).Once()
+		userRepo.On("CreateUserTx", ctx, txMock, mock.Anything).Return(model.ErrUserExists).Once()
+		txMock.On("Rollback", ctx).Return(nil).Once()
+
+		_, err := registration.Register(ctx, "taken", "password1", "code")
+		assert.ErrorIs(t, err, model.ErrUserExists)
+	})
+}
+
+func TestRegistrationService_BootstrapAdmin(t *testing.T) {
+	userRepo := new(mocks.UserRepositoryMock)
+	registration := NewRegistrationService(userRepo, nil, PasswordPolicy{MinLength: 8}, nil, time.Hour)
+	ctx := context.Background()
+
+	t.Run("Admin created on fresh database", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", ctx, "root").Return((*model.User)(nil), nil).Once()
+		userRepo.On("CreateUser", ctx, mock.MatchedBy(func(u *model.User) bool {
+			return u.Username == "root" && bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte("password1")) == nil
+		})).Run(func(args mock.Arguments) {
+			args[1].(*model.User).ID = "user1"
+		}).Return(nil).Once()
+		userRepo.On("SetRole", ctx, "user1", model.RoleAdmin).Return(nil).Once()
+
+		assert.NoError(t, registration.BootstrapAdmin(ctx, "root", "password1"))
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("Existing user promoted", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", ctx, "alice").
+			Return(&model.User{ID: "user2", Username: "alice", Role: model.RoleEmployee}, nil).Once()
+		userRepo.On("SetRole", ctx, "user2", model.RoleAdmin).Return(nil).Once()
+
+		assert.NoError(t, registration.BootstrapAdmin(ctx, "alice", ""))
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("Already admin", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", ctx, "bob").
+			Return(&model.User{ID: "user3", Username: "bob", Role: model.RoleAdmin}, nil).Once()
+
+		assert.NoError(t, registration.BootstrapAdmin(ctx, "bob", ""))
+	})
+
+	t.Run("Weak password", func(t *testing.T) {
+		userRepo.On("GetUserByUsername", ctx, "carol").Return((*model.User)(nil), nil).Once()
+
+		assert.ErrorIs(t, registration.BootstrapAdmin(ctx, "carol", "short"), model.ErrPasswordTooShort)
+	})
+
+	t.Run("Placeholder password", func(t *testing.T) {
+		for _, password := range []string{"change-me-admin", "Password", "changeme"} {
+			assert.ErrorIs(t, registration.BootstrapAdmin(ctx, "dave", password), model.ErrPlaceholderPassword)
+		}
+		userRepo.AssertNotCalled(t, "GetUserByUsername", ctx, "dave")
+	})
+}
+
+func TestRegistrationService_CreateInvite(t *testing.T) {
+	inviteRepo := new(mocks.InviteRepositoryMock)
+	registration := NewRegistrationService(new(mocks.UserRepositoryMock), inviteRepo, PasswordPolicy{}, nil, time.Hour)
+	ctx := context.Background()
+
+	t.Run("Default lifetime", func(t *testing.T) {
+		var stored *model.Invite
+		inviteRepo.On("CreateInvite", ctx, mock.Anything).
+			Run(func(args mock.Arguments) {
+				stored = args[1].(*model.Invite)
+			}).Return(nil).Once()
+
+		invite, err := registration.CreateInvite(ctx, "admin1", time.Time{})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, invite.Code)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), invite.ExpiresAt, 2*time.Second)
+		assert.Equal(t, hashToken(invite.Code), stored.CodeHash)
+		assert.Equal(t, "admin1", stored.CreatedBy)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		inviteRepo.On("CreateInvite", ctx, mock.Anything).Return(model.ErrInternalError).Once()
+
+		_, err := registration.CreateInvite(ctx, "admin1", time.Now().Add(time.Minute))
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+)
+
+// Structure that keeps revoked access tokens, database is the source of truth
+// and in-process cache is refreshed periodically so every replica sees revocations
+type RevocationService struct {
+	revocationRepo repository.RevocationRepositoryInt
+	refreshRepo    repository.RefreshTokenRepositoryInt
+	accessTTL      time.Duration
+
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+	users  map[string]time.Time
+}
+
+// Constructor for the revocation service, accessTTL bounds lifetime of a revoked token record
+func NewRevocationService(
+	vRepo repository.RevocationRepositoryInt,
+	rRepo repository.RefreshTokenRepositoryInt,
+	accessTTL time.Duration,
+) *RevocationService {
+	return &RevocationService{
+		revocationRepo: vRepo,
+		refreshRepo:    rRepo,
+		accessTTL:      accessTTL,
+		tokens:         make(map[string]time.Time),
+		users:          make(map[string]time.Time),
+	}
+}
+
+// Function that reloads cache from database, returns error
+func (s *RevocationService) Load(ctx context.Context) error {
+	tokens, err := s.revocationRepo.GetRevokedTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	users, err := s.revocationRepo.GetUserRevocations(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.tokens = tokens
+	s.users = users
+	s.mu.Unlock()
+	return nil
+}
+
+// Function that reloads cache every interval until ctx is done
+func (s *RevocationService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Load(ctx); err != nil {
+				log.Printf("Revocation cache refresh error: %v", err)
+			}
+		}
+	}
+}
+
+// Function that checks whether token with jti issued at issuedAt for user with userID is revoked
+func (s *RevocationService) IsRevoked(jti, userID string, issuedAt time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.tokens[jti]; ok {
+		return true
+	}
+
+	before, ok := s.users[userID]
+	return ok && issuedAt.Before(before)
+}
+
+// Function that revokes single access token by its jti, returns error
+func (s *RevocationService) RevokeToken(ctx context.Context, jti string) error {
+	expiresAt := time.Now().Add(s.accessTTL)
+	if err := s.revocationRepo.RevokeToken(ctx, jti, expiresAt); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.tokens[jti] = expiresAt
+	s.mu.Unlock()
+	return nil
+}
+
+// Function that revokes every access and refresh token of user with userID issued before given moment,
+// the moment is truncated to seconds as issue time of access tokens is, so tokens issued later
+// in the same second stay valid, returns error
+func (s *RevocationService) RevokeUserTokens(ctx context.Context, userID string, before time.Time) error {
+	before = before.Truncate(time.Second)
+
+	if err := s.revocationRepo.RevokeUserTokens(ctx, userID, before); err != nil {
+		return err
+	}
+
+	if err := s.refreshRepo.RevokeUserTokens(ctx, userID, before); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if current, ok := s.users[userID]; !ok || before.After(current) {
+		s.users[userID] = before
+	}
+	s.mu.Unlock()
+	return nil
+}
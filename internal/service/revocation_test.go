@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestRevocationService_Load(t *testing.T) {
+	revocationRepo := new(mocks.RevocationRepositoryMock)
+	revocationSvc := NewRevocationService(revocationRepo, new(mocks.RefreshTokenRepositoryMock), time.Minute)
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("Successful load", func(t *testing.T) {
+		revocationRepo.On("GetRevokedTokens", ctx).
+			Return(map[string]time.Time{"jti1": now.Add(time.Minute)}, nil).Once()
+		revocationRepo.On("GetUserRevocations", ctx).
+			Return(map[string]time.Time{"user1": now}, nil).Once()
+
+		assert.NoError(t, revocationSvc.Load(ctx))
+
+		assert.True(t, revocationSvc.IsRevoked("jti1", "user2", now))
+		assert.True(t, revocationSvc.IsRevoked("jti2", "user1", now.Add(-time.Second)))
+		assert.False(t, revocationSvc.IsRevoked("jti2", "user1", now.Add(time.Second)))
+		assert.False(t, revocationSvc.IsRevoked("jti2", "user2", now))
+	})
+
+	t.Run("Database error keeps previous cache", func(t *testing.T) {
+		revocationRepo.On("GetRevokedTokens", ctx).
+			Return(map[string]time.Time(nil), model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, revocationSvc.Load(ctx), model.ErrInternalError)
+		assert.True(t, revocationSvc.IsRevoked("jti1", "user2", now))
+	})
+
+	t.Run("User revocations error", func(t *testing.T) {
+		revocationRepo.On("GetRevokedTokens", ctx).
+			Return(map[string]time.Time{}, nil).Once()
+		revocationRepo.On("GetUserRevocations", ctx).
+			Return(map[string]time.Time(nil), model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, revocationSvc.Load(ctx), model.ErrInternalError)
+	})
+}
+
+func TestRevocationService_RevokeToken(t *testing.T) {
+	revocationRepo := new(mocks.RevocationRepositoryMock)
+	revocationSvc := NewRevocationService(revocationRepo, new(mocks.RefreshTokenRepositoryMock), time.Minute)
+	ctx := context.Background()
+
+	t.Run("Successful revocation", func(t *testing.T) {
+		revocationRepo.On("RevokeToken", ctx, "jti1", mock.AnythingOfType("time.Time")).
+			Return(nil).Once()
+
+		assert.NoError(t, revocationSvc.RevokeToken(ctx, "jti1"))
+		assert.True(t, revocationSvc.IsRevoked("jti1", "user1", time.Now()))
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		revocationRepo.On("RevokeToken", ctx, "jti2", mock.AnythingOfType("time.Time")).
+			Return(model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, revocationSvc.RevokeToken(ctx, "jti2"), model.ErrInternalError)
+		assert.False(t, revocationSvc.IsRevoked("jti2", "user1", time.Now()))
+	})
+}
+
+func TestRevocationService_RevokeUserTokens(t *testing.T) {
+	revocationRepo := new(mocks.RevocationRepositoryMock)
+	refreshRepo := new(mocks.RefreshTokenRepositoryMock)
+	revocationSvc := NewRevocationService(revocationRepo, refreshRepo, time.Minute)
+	ctx := context.Background()
+	before := time.Now().Truncate(time.Second)
+
+	t.Run("Successful revocation", func(t *testing.T) {
+		revocationRepo.On("RevokeUserTokens", ctx, "user1", before).Return(nil).Once()
+		refreshRepo.On("RevokeUserTokens", ctx, "user1", before).Return(nil).Once()
+
+		assert.NoError(t, revocationSvc.RevokeUserTokens(ctx, "user1", before))
+		assert.True(t, revocationSvc.IsRevoked("jti", "user1", before.Add(-time.Second)))
+		assert.False(t, revocationSvc.IsRevoked("jti", "user1", before.Add(time.Second)))
+	})
+
+	t.Run("Earlier revocation does not shrink cutoff", func(t *testing.T) {
+		earlier := before.Add(-time.Hour)
+		revocationRepo.On("RevokeUserTokens", ctx, "user1", earlier).Return(nil).Once()
+		refreshRepo.On("RevokeUserTokens", ctx, "user1", earlier).Return(nil).Once()
+
+		assert.NoError(t, revocationSvc.RevokeUserTokens(ctx, "user1", earlier))
+		assert.True(t, revocationSvc.IsRevoked("jti", "user1", before.Add(-time.Second)))
+	})
+
+	t.Run("Moment truncated to seconds", func(t *testing.T) {
+		later := before.Add(time.Minute)
+		revocationRepo.On("RevokeUserTokens", ctx, "user4", later).Return(nil).Once()
+		refreshRepo.On("RevokeUserTokens", ctx, "user4", later).Return(nil).Once()
+
+		assert.NoError(t, revocationSvc.RevokeUserTokens(ctx, "user4", later.Add(700*time.Millisecond)))
+		assert.True(t, revocationSvc.IsRevoked("jti", "user4", later.Add(-time.Second)))
+		assert.False(t, revocationSvc.IsRevoked("jti", "user4", later))
+	})
+
+	t.Run("Refresh tokens revocation error", func(t *testing.T) {
+		revocationRepo.On("RevokeUserTokens", ctx, "user2", before).Return(nil).Once()
+		refreshRepo.On("RevokeUserTokens", ctx, "user2", before).Return(model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, revocationSvc.RevokeUserTokens(ctx, "user2", before), model.ErrInternalError)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		revocationRepo.On("RevokeUserTokens", ctx, "user3", before).Return(model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, revocationSvc.RevokeUserTokens(ctx, "user3", before), model.ErrInternalError)
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"log"
 
+	"github.com/jackc/pgx/v5"
+
 	"github.com/garaevmir/avitocoinstore/internal/model"
 	"github.com/garaevmir/avitocoinstore/internal/repository"
 )
@@ -13,6 +15,9 @@ type ShopService struct {
 	userRepo        repository.UserRepositoryInt
 	transactionRepo repository.TransactionRepositoryInt
 	inventoryRepo   repository.InventoryRepositoryInt
+	catalogRepo     repository.CatalogRepositoryInt
+	ledgerRepo      repository.LedgerRepositoryInt
+	orderRepo       repository.OrderRepositoryInt
 }
 
 // Constructor for the shop
@@ -20,52 +25,140 @@ func NewShopService(
 	uRepo repository.UserRepositoryInt,
 	tRepo repository.TransactionRepositoryInt,
 	iRepo repository.InventoryRepositoryInt,
+	cRepo repository.CatalogRepositoryInt,
+	lRepo repository.LedgerRepositoryInt,
+	oRepo repository.OrderRepositoryInt,
 ) *ShopService {
 	return &ShopService{
 		userRepo:        uRepo,
 		transactionRepo: tRepo,
 		inventoryRepo:   iRepo,
+		catalogRepo:     cRepo,
+		ledgerRepo:      lRepo,
+		orderRepo:       oRepo,
 	}
 }
 
-// Function that buys item itemName for user with userID during transaction, returns error
+// Function that buys one unit of item itemName for user with userID during transaction, returns error
 func (s *ShopService) BuyItem(ctx context.Context, userID string, itemName string) error {
-	item, exists := model.Items[itemName]
-	if !exists {
-		return model.ErrItemNotFound
+	_, err := s.BuyItems(ctx, userID, []model.OrderLine{{Item: itemName, Quantity: 1}})
+	return err
+}
+
+// Function that buys every line for user with userID in one transaction, either all lines are bought
+// or none, total price is checked against balance, returns order for every line and error
+func (s *ShopService) BuyItems(ctx context.Context, userID string, lines []model.OrderLine) ([]model.Order, error) {
+	if err := validateLines(lines); err != nil {
+		return nil, err
 	}
+	return s.purchase(ctx, userID, func(tx pgx.Tx) ([]model.OrderLine, error) {
+		return lines, nil
+	}, nil)
+}
 
-	user, err := s.userRepo.GetUserByID(ctx, userID)
-	if err != nil {
-		log.Printf("Error getting user: %v", err)
-		return err
+// Validates lines of order
+func validateLines(lines []model.OrderLine) error {
+	if len(lines) == 0 {
+		return model.ErrInvalidRequest
+	}
+	for _, line := range lines {
+		if line.Quantity <= 0 {
+			return model.ErrInvalidRequest
+		}
 	}
+	return nil
+}
 
-	if user.Coins < item.Price {
-		return model.ErrInsufficientFunds
+// Returns catalog item for every line, items are read during transaction and can not change price or be retired
+// until it ends, so lines are charged at the prices they are sold at
+func (s *ShopService) lockItems(ctx context.Context, tx pgx.Tx, lines []model.OrderLine) ([]*model.Item, error) {
+	items := make([]*model.Item, 0, len(lines))
+	for _, line := range lines {
+		item, err := s.catalogRepo.GetItemForShareTx(ctx, tx, line.Item)
+		if err != nil {
+			log.Printf("Error getting item: %v", err)
+			return nil, err
+		}
+		if item == nil || !item.Active {
+			return nil, model.ErrItemNotFound
+		}
+		items = append(items, item)
 	}
+	return items, nil
+}
 
+// Buys lines returned by load in one transaction at current catalog prices, load runs once balance is locked,
+// inTx if not nil runs in the same transaction once items are read and before user is charged
+func (s *ShopService) purchase(
+	ctx context.Context,
+	userID string,
+	load func(tx pgx.Tx) ([]model.OrderLine, error),
+	inTx func(tx pgx.Tx, items []*model.Item) error,
+) ([]model.Order, error) {
 	tx, err := s.userRepo.BeginTx(ctx)
 	if err != nil {
 		log.Printf("Transaction error: %v", err)
-		return err
+		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
-	if err := s.userRepo.UpdateUserCoinsTx(ctx, tx, userID, -item.Price); err != nil {
-		log.Printf("Updating user coins error: %v", err)
-		return err
+	// Balance row stays locked until commit, so concurrent purchases of the same user are checked one by one
+	coins, err := s.userRepo.GetCoinsForUpdateTx(ctx, tx, userID)
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		return nil, err
 	}
 
-	if err := s.inventoryRepo.AddToInventoryTx(ctx, tx, userID, itemName, 1); err != nil {
-		log.Printf("Adding item to invetory error: %v", err)
-		return err
+	lines, err := load(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.lockItems(ctx, tx, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	if inTx != nil {
+		if err := inTx(tx, items); err != nil {
+			return nil, err
+		}
+	}
+
+	// Remaining balance is checked line by line so that price times quantity can not overflow
+	remaining := coins
+	for i, line := range lines {
+		if line.Quantity > remaining/items[i].Price {
+			return nil, model.ErrInsufficientFunds
+		}
+		remaining -= items[i].Price * line.Quantity
+	}
+
+	orders := make([]model.Order, 0, len(lines))
+	for i, line := range lines {
+		item := items[i]
+		if err := s.ledgerRepo.PostTx(ctx, tx, model.PurchasePosting(userID, item.Price*line.Quantity, item.Name)); err != nil {
+			log.Printf("Posting purchase error: %v", err)
+			return nil, err
+		}
+
+		if err := s.inventoryRepo.AddToInventoryTx(ctx, tx, userID, item.Name, line.Quantity); err != nil {
+			log.Printf("Adding item to invetory error: %v", err)
+			return nil, err
+		}
+
+		order := &model.Order{UserID: userID, Item: item.Name, UnitPrice: item.Price, Quantity: line.Quantity}
+		if err := s.orderRepo.CreateOrderTx(ctx, tx, order); err != nil {
+			log.Printf("Recording order error: %v", err)
+			return nil, err
+		}
+		orders = append(orders, *order)
 	}
 
 	if err := tx.Commit(ctx); err != nil {
 		log.Printf("Transaction commit error: %v", err)
-		return err
+		return nil, err
 	}
 
-	return nil
+	return orders, nil
 }
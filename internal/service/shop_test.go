@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
@@ -15,36 +18,72 @@ func TestShopService_BuyItem(t *testing.T) {
 	userRepo := new(mocks.UserRepositoryMock)
 	txRepo := new(mocks.TransactionRepositoryMock)
 	invRepo := new(mocks.InventoryRepositoryMock)
+	catRepo := new(mocks.CatalogRepositoryMock)
+	ledgerRepo := new(mocks.LedgerRepositoryMock)
+	orderRepo := new(mocks.OrderRepositoryMock)
 	txMock := new(mocks.TxMock)
 
 	txMock.On("Rollback", mock.Anything).Return(nil)
 
-	shopSvc := NewShopService(userRepo, txRepo, invRepo)
+	catRepo.On("GetItemForShareTx", mock.Anything, mock.Anything, "hoody").
+		Return(&model.Item{Name: "hoody", Price: 300, Active: true}, nil)
+	catRepo.On("GetItemForShareTx", mock.Anything, mock.Anything, "unknown_item").
+		Return(nil, nil)
+	catRepo.On("GetItemForShareTx", mock.Anything, mock.Anything, "retired_item").
+		Return(&model.Item{Name: "retired_item", Price: 10, Active: false}, nil)
+	catRepo.On("GetItemForShareTx", mock.Anything, mock.Anything, "broken_item").
+		Return(nil, model.ErrInternalError)
+
+	shopSvc := NewShopService(userRepo, txRepo, invRepo, catRepo, ledgerRepo, orderRepo)
 
 	t.Run("Successful purchase", func(t *testing.T) {
-		userRepo.On("GetUserByID", mock.Anything, "user1").
-			Return(&model.User{ID: "user1", Coins: 500}, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, mock.Anything, "user1").Return(500, nil).Once()
 		userRepo.On("BeginTx", mock.Anything).
 			Return(txMock, nil).Once()
-		userRepo.On("UpdateUserCoinsTx", mock.Anything, txMock, "user1", -300).
+		ledgerRepo.On("PostTx", mock.Anything, txMock, model.PurchasePosting("user1", 300, "hoody")).
 			Return(nil).Once()
 		invRepo.On("AddToInventoryTx", mock.Anything, txMock, "user1", "hoody", 1).
 			Return(nil).Once()
+		orderRepo.On("CreateOrderTx", mock.Anything, txMock,
+			&model.Order{UserID: "user1", Item: "hoody", UnitPrice: 300, Quantity: 1}).
+			Return(nil).Once()
 		txMock.On("Commit", mock.Anything).
 			Return(nil).Once()
 
 		err := shopSvc.BuyItem(context.Background(), "user1", "hoody")
 		assert.NoError(t, err)
+		orderRepo.AssertExpectations(t)
 	})
 
 	t.Run("Item not found", func(t *testing.T) {
+		userRepo.On("BeginTx", mock.Anything).Return(txMock, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, txMock, "user1").Return(500, nil).Once()
+
 		err := shopSvc.BuyItem(context.Background(), "user1", "unknown_item")
 		assert.ErrorIs(t, err, model.ErrItemNotFound)
 	})
 
+	t.Run("Inactive item", func(t *testing.T) {
+		userRepo.On("BeginTx", mock.Anything).Return(txMock, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, txMock, "user1").Return(500, nil).Once()
+
+		err := shopSvc.BuyItem(context.Background(), "user1", "retired_item")
+		assert.ErrorIs(t, err, model.ErrItemNotFound)
+	})
+
+	t.Run("Database error during item lookup", func(t *testing.T) {
+		userRepo.On("BeginTx", mock.Anything).Return(txMock, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, txMock, "user1").Return(500, nil).Once()
+
+		err := shopSvc.BuyItem(context.Background(), "user1", "broken_item")
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+
 	t.Run("Database error during balance check", func(t *testing.T) {
-		userRepo.On("GetUserByID", mock.Anything, "user3").
-			Return((*model.User)(nil), model.ErrInternalError).Once()
+		userRepo.On("BeginTx", mock.Anything).
+			Return(txMock, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, txMock, "user3").
+			Return(0, model.ErrInternalError).Once()
 
 		err := shopSvc.BuyItem(context.Background(), "user3", "hoody")
 		assert.ErrorIs(t, err, model.ErrInternalError)
@@ -52,8 +91,9 @@ func TestShopService_BuyItem(t *testing.T) {
 	})
 
 	t.Run("Insufficient funds", func(t *testing.T) {
-		userRepo.On("GetUserByID", mock.Anything, "user2").
-			Return(&model.User{ID: "user2", Coins: 100}, nil).Once()
+		userRepo.On("BeginTx", mock.Anything).
+			Return(txMock, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, txMock, "user2").Return(100, nil).Once()
 
 		err := shopSvc.BuyItem(context.Background(), "user2", "hoody")
 		assert.ErrorIs(t, err, model.ErrInsufficientFunds)
@@ -61,8 +101,6 @@ func TestShopService_BuyItem(t *testing.T) {
 	})
 
 	t.Run("Begin transaction error", func(t *testing.T) {
-		userRepo.On("GetUserByID", mock.Anything, "user1").
-			Return(&model.User{ID: "user1", Coins: 500}, nil).Once()
 		userRepo.On("BeginTx", mock.Anything).
 			Return(txMock, model.ErrInternalError).Once()
 
@@ -70,12 +108,11 @@ func TestShopService_BuyItem(t *testing.T) {
 		assert.ErrorIs(t, err, model.ErrInternalError)
 	})
 
-	t.Run("Update user coins error", func(t *testing.T) {
-		userRepo.On("GetUserByID", mock.Anything, "user1").
-			Return(&model.User{ID: "user1", Coins: 500}, nil).Once()
+	t.Run("Posting purchase error", func(t *testing.T) {
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, mock.Anything, "user1").Return(500, nil).Once()
 		userRepo.On("BeginTx", mock.Anything).
 			Return(txMock, nil).Once()
-		userRepo.On("UpdateUserCoinsTx", mock.Anything, txMock, "user1", -300).
+		ledgerRepo.On("PostTx", mock.Anything, txMock, model.PurchasePosting("user1", 300, "hoody")).
 			Return(model.ErrInternalError).Once()
 
 		err := shopSvc.BuyItem(context.Background(), "user1", "hoody")
@@ -83,11 +120,10 @@ func TestShopService_BuyItem(t *testing.T) {
 	})
 
 	t.Run("Adding to inventory error", func(t *testing.T) {
-		userRepo.On("GetUserByID", mock.Anything, "user1").
-			Return(&model.User{ID: "user1", Coins: 500}, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, mock.Anything, "user1").Return(500, nil).Once()
 		userRepo.On("BeginTx", mock.Anything).
 			Return(txMock, nil).Once()
-		userRepo.On("UpdateUserCoinsTx", mock.Anything, txMock, "user1", -300).
+		ledgerRepo.On("PostTx", mock.Anything, txMock, model.PurchasePosting("user1", 300, "hoody")).
 			Return(nil).Once()
 		invRepo.On("AddToInventoryTx", mock.Anything, txMock, "user1", "hoody", 1).
 			Return(model.ErrInternalError).Once()
@@ -96,15 +132,32 @@ func TestShopService_BuyItem(t *testing.T) {
 		assert.ErrorIs(t, err, model.ErrInternalError)
 	})
 
+	t.Run("Recording order error", func(t *testing.T) {
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, mock.Anything, "user1").Return(500, nil).Once()
+		userRepo.On("BeginTx", mock.Anything).
+			Return(txMock, nil).Once()
+		ledgerRepo.On("PostTx", mock.Anything, txMock, model.PurchasePosting("user1", 300, "hoody")).
+			Return(nil).Once()
+		invRepo.On("AddToInventoryTx", mock.Anything, txMock, "user1", "hoody", 1).
+			Return(nil).Once()
+		orderRepo.On("CreateOrderTx", mock.Anything, txMock, mock.AnythingOfType("*model.Order")).
+			Return(model.ErrInternalError).Once()
+
+		err := shopSvc.BuyItem(context.Background(), "user1", "hoody")
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+
 	t.Run("Transaction commit error", func(t *testing.T) {
-		userRepo.On("GetUserByID", mock.Anything, "user1").
-			Return(&model.User{ID: "user1", Coins: 500}, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, mock.Anything, "user1").Return(500, nil).Once()
 		userRepo.On("BeginTx", mock.Anything).
 			Return(txMock, nil).Once()
-		userRepo.On("UpdateUserCoinsTx", mock.Anything, txMock, "user1", -300).
+		ledgerRepo.On("PostTx", mock.Anything, txMock, model.PurchasePosting("user1", 300, "hoody")).
 			Return(nil).Once()
 		invRepo.On("AddToInventoryTx", mock.Anything, txMock, "user1", "hoody", 1).
 			Return(nil).Once()
+		orderRepo.On("CreateOrderTx", mock.Anything, txMock,
+			&model.Order{UserID: "user1", Item: "hoody", UnitPrice: 300, Quantity: 1}).
+			Return(nil).Once()
 		txMock.On("Commit", mock.Anything).
 			Return(model.ErrInternalError).Once()
 
@@ -112,3 +165,218 @@ func TestShopService_BuyItem(t *testing.T) {
 		assert.ErrorIs(t, err, model.ErrInternalError)
 	})
 }
+
+func TestShopService_BuyItems(t *testing.T) {
+	userRepo := new(mocks.UserRepositoryMock)
+	invRepo := new(mocks.InventoryRepositoryMock)
+	catRepo := new(mocks.CatalogRepositoryMock)
+	ledgerRepo := new(mocks.LedgerRepositoryMock)
+	orderRepo := new(mocks.OrderRepositoryMock)
+	ctx := context.Background()
+
+	catRepo.On("GetItemForShareTx", mock.Anything, mock.Anything, "hoody").
+		Return(&model.Item{Name: "hoody", Price: 300, Active: true}, nil)
+	catRepo.On("GetItemForShareTx", mock.Anything, mock.Anything, "pen").
+		Return(&model.Item{Name: "pen", Price: 10, Active: true}, nil)
+	catRepo.On("GetItemForShareTx", mock.Anything, mock.Anything, "unknown_item").
+		Return(nil, nil)
+
+	shopSvc := NewShopService(userRepo, new(mocks.TransactionRepositoryMock), invRepo, catRepo, ledgerRepo, orderRepo)
+
+	t.Run("Successful order of several items", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		txMock.On("Rollback", mock.Anything).Return(nil).Once()
+		txMock.On("Commit", mock.Anything).Return(nil).Once()
+
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, mock.Anything, "user1").Return(1000, nil).Once()
+		userRepo.On("BeginTx", mock.Anything).Return(txMock, nil).Once()
+		ledgerRepo.On("PostTx", mock.Anything, txMock, model.PurchasePosting("user1", 600, "hoody")).
+			Return(nil).Once()
+		ledgerRepo.On("PostTx", mock.Anything, txMock, model.PurchasePosting("user1", 50, "pen")).
+			Return(nil).Once()
+		invRepo.On("AddToInventoryTx", mock.Anything, txMock, "user1", "hoody", 2).Return(nil).Once()
+		invRepo.On("AddToInventoryTx", mock.Anything, txMock, "user1", "pen", 5).Return(nil).Once()
+		orderRepo.On("CreateOrderTx", mock.Anything, txMock, mock.AnythingOfType("*model.Order")).
+			Return(nil).Twice()
+
+		orders, err := shopSvc.BuyItems(ctx, "user1", []model.OrderLine{
+			{Item: "hoody", Quantity: 2},
+			{Item: "pen", Quantity: 5},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Order{
+			{UserID: "user1", Item: "hoody", UnitPrice: 300, Quantity: 2},
+			{UserID: "user1", Item: "pen", UnitPrice: 10, Quantity: 5},
+		}, orders)
+		ledgerRepo.AssertExpectations(t)
+		invRepo.AssertExpectations(t)
+		txMock.AssertExpectations(t)
+	})
+
+	t.Run("Total exceeds balance", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		txMock.On("Rollback", mock.Anything).Return(nil).Once()
+
+		userRepo.On("BeginTx", mock.Anything).Return(txMock, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, txMock, "user1").Return(600, nil).Once()
+
+		_, err := shopSvc.BuyItems(ctx, "user1", []model.OrderLine{
+			{Item: "hoody", Quantity: 2},
+			{Item: "pen", Quantity: 1},
+		})
+		assert.ErrorIs(t, err, model.ErrInsufficientFunds)
+		txMock.AssertNotCalled(t, "Commit", mock.Anything)
+	})
+
+	t.Run("Huge quantity", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		txMock.On("Rollback", mock.Anything).Return(nil).Once()
+
+		userRepo.On("BeginTx", mock.Anything).Return(txMock, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, txMock, "user1").Return(1000, nil).Once()
+
+		_, err := shopSvc.BuyItems(ctx, "user1", []model.OrderLine{{Item: "pen", Quantity: 1 << 62}})
+		assert.ErrorIs(t, err, model.ErrInsufficientFunds)
+	})
+
+	t.Run("Unknown item in one of lines", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		txMock.On("Rollback", mock.Anything).Return(nil).Once()
+
+		userRepo.On("BeginTx", mock.Anything).Return(txMock, nil).Once()
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, txMock, "user1").Return(1000, nil).Once()
+
+		_, err := shopSvc.BuyItems(ctx, "user1", []model.OrderLine{
+			{Item: "hoody", Quantity: 1},
+			{Item: "unknown_item", Quantity: 1},
+		})
+		assert.ErrorIs(t, err, model.ErrItemNotFound)
+	})
+
+	t.Run("Invalid quantity", func(t *testing.T) {
+		_, err := shopSvc.BuyItems(ctx, "user1", []model.OrderLine{{Item: "hoody", Quantity: 0}})
+		assert.ErrorIs(t, err, model.ErrInvalidRequest)
+	})
+
+	t.Run("Empty order", func(t *testing.T) {
+		_, err := shopSvc.BuyItems(ctx, "user1", nil)
+		assert.ErrorIs(t, err, model.ErrInvalidRequest)
+	})
+
+	t.Run("Failure of second line rolls back order", func(t *testing.T) {
+		txMock := new(mocks.TxMock)
+		txMock.On("Rollback", mock.Anything).Return(nil).Once()
+
+		userRepo.On("GetCoinsForUpdateTx", mock.Anything, mock.Anything, "user1").Return(1000, nil).Once()
+		userRepo.On("BeginTx", mock.Anything).Return(txMock, nil).Once()
+		ledgerRepo.On("PostTx", mock.Anything, txMock, model.PurchasePosting("user1", 300, "hoody")).
+			Return(nil).Once()
+		ledgerRepo.On("PostTx", mock.Anything, txMock, model.PurchasePosting("user1", 10, "pen")).
+			Return(model.ErrInternalError).Once()
+		invRepo.On("AddToInventoryTx", mock.Anything, txMock, "user1", "hoody", 1).Return(nil).Once()
+		orderRepo.On("CreateOrderTx", mock.Anything, txMock, mock.AnythingOfType("*model.Order")).
+			Return(nil).Once()
+
+		_, err := shopSvc.BuyItems(ctx, "user1", []model.OrderLine{
+			{Item: "hoody", Quantity: 1},
+			{Item: "pen", Quantity: 1},
+		})
+		assert.ErrorIs(t, err, model.ErrInternalError)
+		txMock.AssertNotCalled(t, "Commit", mock.Anything)
+		txMock.AssertExpectations(t)
+	})
+}
+
+// Wallet of single user that locks balance like SELECT ... FOR UPDATE until end of transaction
+type walletStub struct {
+	*mocks.UserRepositoryMock
+	lock  sync.Mutex
+	coins int
+}
+
+// Transaction over walletStub, postings are applied to balance on commit
+type walletTx struct {
+	*mocks.TxMock
+	wallet  *walletStub
+	locked  bool
+	pending int
+}
+
+func (w *walletStub) BeginTx(ctx context.Context) (pgx.Tx, error) {
+	return &walletTx{TxMock: new(mocks.TxMock), wallet: w}, nil
+}
+
+func (w *walletStub) GetCoinsForUpdateTx(ctx context.Context, tx pgx.Tx, userID string) (int, error) {
+	w.lock.Lock()
+	tx.(*walletTx).locked = true
+	return w.coins, nil
+}
+
+func (tx *walletTx) Commit(ctx context.Context) error {
+	tx.wallet.coins += tx.pending
+	if tx.wallet.coins < 0 {
+		return model.ErrInsufficientFunds
+	}
+	return tx.Rollback(ctx)
+}
+
+func (tx *walletTx) Rollback(ctx context.Context) error {
+	if tx.locked {
+		tx.locked = false
+		tx.wallet.lock.Unlock()
+	}
+	return nil
+}
+
+type walletLedgerStub struct {
+	*mocks.LedgerRepositoryMock
+}
+
+func (walletLedgerStub) PostTx(ctx context.Context, tx pgx.Tx, posting *model.Posting) error {
+	for _, entry := range posting.Entries {
+		if entry.UserID != "" {
+			tx.(*walletTx).pending += entry.Amount
+		}
+	}
+	return nil
+}
+
+func TestShopService_BuyItem_Concurrent(t *testing.T) {
+	wallet := &walletStub{UserRepositoryMock: new(mocks.UserRepositoryMock), coins: 1000}
+	invRepo := new(mocks.InventoryRepositoryMock)
+	catRepo := new(mocks.CatalogRepositoryMock)
+	orderRepo := new(mocks.OrderRepositoryMock)
+
+	catRepo.On("GetItemForShareTx", mock.Anything, mock.Anything, "hoody").
+		Return(&model.Item{Name: "hoody", Price: 300, Active: true}, nil)
+	invRepo.On("AddToInventoryTx", mock.Anything, mock.Anything, "user1", "hoody", 1).Return(nil)
+	orderRepo.On("CreateOrderTx", mock.Anything, mock.Anything, mock.AnythingOfType("*model.Order")).Return(nil)
+
+	shopSvc := NewShopService(wallet, new(mocks.TransactionRepositoryMock), invRepo, catRepo,
+		walletLedgerStub{new(mocks.LedgerRepositoryMock)}, orderRepo)
+
+	const buyers = 50
+	var (
+		wg           sync.WaitGroup
+		bought, poor atomic.Int32
+	)
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			switch err := shopSvc.BuyItem(context.Background(), "user1", "hoody"); err {
+			case nil:
+				bought.Add(1)
+			case model.ErrInsufficientFunds:
+				poor.Add(1)
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(3), bought.Load())
+	assert.Equal(t, int32(buyers-3), poor.Load())
+	assert.Equal(t, 100, wallet.coins)
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+)
+
+// Columns of CSV statement, opening and closing rows use only timestamp, kind and balance
+var statementHeader = []string{
+	"timestamp", "entry_id", "kind", "amount", "balance", "counterparty", "item", "reason",
+}
+
+// Kinds of the first and the last rows of CSV statement
+const (
+	statementOpening = "opening"
+	statementClosing = "closing"
+)
+
+// Structure that writes statement to w as CSV row by row, Close has to be called after the last movement
+type StatementCSVWriter struct {
+	cw      *csv.Writer
+	header  model.StatementHeader
+	balance int
+}
+
+// Constructor for CSV statement writer
+func NewStatementCSVWriter(w io.Writer, header model.StatementHeader) *StatementCSVWriter {
+	return &StatementCSVWriter{cw: csv.NewWriter(w), header: header}
+}
+
+// Function that writes column names and opening row, returns error
+func (s *StatementCSVWriter) Opening(balance int) error {
+	s.balance = balance
+	if err := s.cw.Write(statementHeader); err != nil {
+		return err
+	}
+	return s.cw.Write([]string{formatStatementTime(s.header.From), "", statementOpening, "", strconv.Itoa(balance), "", "", ""})
+}
+
+// Function that writes one movement, returns error
+func (s *StatementCSVWriter) Movement(e model.ActivityEvent) error {
+	s.balance = e.Balance
+	return s.cw.Write([]string{
+		formatStatementTime(&e.Timestamp),
+		strconv.FormatInt(e.ID, 10),
+		e.Kind,
+		strconv.Itoa(e.Amount),
+		strconv.Itoa(e.Balance),
+		csvText(e.Counterparty),
+		csvText(e.Item),
+		csvText(e.Reason),
+	})
+}
+
+// Function that writes closing row and flushes what is buffered, returns error
+func (s *StatementCSVWriter) Close() error {
+	if err := s.cw.Write([]string{formatStatementTime(s.header.To), "", statementClosing, "", strconv.Itoa(s.balance), "", "", ""}); err != nil {
+		return err
+	}
+	s.cw.Flush()
+	return s.cw.Error()
+}
+
+// Structure that writes statement to w as single JSON object with movements array, the object is written
+// piece by piece, Close has to be called after the last movement
+type StatementJSONWriter struct {
+	w       io.Writer
+	header  model.StatementHeader
+	balance int
+	started bool
+}
+
+// Constructor for JSON statement writer
+func NewStatementJSONWriter(w io.Writer, header model.StatementHeader) *StatementJSONWriter {
+	return &StatementJSONWriter{w: w, header: header}
+}
+
+// Function that writes header fields and opens movements array, returns error
+func (s *StatementJSONWriter) Opening(balance int) error {
+	s.balance = balance
+	s.header.OpeningBalance = balance
+	head, err := json.Marshal(s.header)
+	if err != nil {
+		return err
+	}
+	// Header object is left open, so movements and closing balance are appended to it
+	if _, err := s.w.Write(head[:len(head)-1]); err != nil {
+		return err
+	}
+	_, err = io.WriteString(s.w, `,"movements":[`)
+	return err
+}
+
+// Function that appends one movement to movements array, returns error
+func (s *StatementJSONWriter) Movement(e model.ActivityEvent) error {
+	s.balance = e.Balance
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if s.started {
+		if _, err := io.WriteString(s.w, ","); err != nil {
+			return err
+		}
+	}
+	s.started = true
+	_, err = s.w.Write(data)
+	return err
+}
+
+// Function that closes movements array and writes closing balance, returns error
+func (s *StatementJSONWriter) Close() error {
+	_, err := io.WriteString(s.w, `],"closingBalance":`+strconv.Itoa(s.balance)+"}\n")
+	return err
+}
+
+// Function that prefixes text starting with =, +, -, @, tab or carriage return with apostrophe, so spreadsheet
+// opening statement shows user provided text as is instead of evaluating it as formula
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// Function that formats bound of period or time of movement for CSV, open bound is left empty
+func formatStatementTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
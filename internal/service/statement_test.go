@@ -0,0 +1,122 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+)
+
+func TestStatementCSVWriter(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	created := from.Add(time.Hour)
+
+	t.Run("Opening, movements and closing rows", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewStatementCSVWriter(&buf, model.StatementHeader{Username: "alice", From: &from})
+
+		assert.NoError(t, w.Opening(1000))
+		assert.NoError(t, w.Movement(model.ActivityEvent{
+			ID: 7, Kind: model.PostingTransfer, Amount: -10, Balance: 990, Counterparty: "bob", Timestamp: created,
+		}))
+		assert.NoError(t, w.Movement(model.ActivityEvent{
+			ID: 8, Kind: model.PostingPurchase, Amount: -80, Balance: 910, Item: "t-shirt", Timestamp: created,
+		}))
+		assert.NoError(t, w.Close())
+
+		assert.Equal(t,
+			"timestamp,entry_id,kind,amount,balance,counterparty,item,reason\n"+
+				"2025-03-01T00:00:00Z,,opening,,1000,,,\n"+
+				"2025-03-01T01:00:00Z,7,transfer,-10,990,bob,,\n"+
+				"2025-03-01T01:00:00Z,8,purchase,-80,910,,t-shirt,\n"+
+				",,closing,,910,,,\n",
+			buf.String())
+	})
+
+	t.Run("Formulas in text are escaped", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewStatementCSVWriter(&buf, model.StatementHeader{Username: "alice"})
+
+		assert.NoError(t, w.Opening(0))
+		assert.NoError(t, w.Movement(model.ActivityEvent{
+			ID: 1, Kind: model.PostingTransfer, Amount: -5, Balance: -5, Counterparty: "=HYPERLINK(\"x\")", Timestamp: created,
+		}))
+		assert.NoError(t, w.Movement(model.ActivityEvent{
+			ID: 2, Kind: model.PostingPurchase, Amount: -1, Balance: -6, Item: "+cmd", Timestamp: created,
+		}))
+		assert.NoError(t, w.Movement(model.ActivityEvent{
+			ID: 3, Kind: model.PostingAdjustment, Amount: 1, Balance: -5, Reason: "@SUM(A1)", Timestamp: created,
+		}))
+		assert.NoError(t, w.Movement(model.ActivityEvent{
+			ID: 4, Kind: model.PostingAdjustment, Amount: 1, Balance: -4, Reason: "-1+1", Timestamp: created,
+		}))
+		assert.NoError(t, w.Movement(model.ActivityEvent{
+			ID: 5, Kind: model.PostingAdjustment, Amount: 1, Balance: -3, Reason: "\tx", Timestamp: created,
+		}))
+		assert.NoError(t, w.Movement(model.ActivityEvent{
+			ID: 6, Kind: model.PostingAdjustment, Amount: 1, Balance: -2, Reason: "\rx", Timestamp: created,
+		}))
+		assert.NoError(t, w.Close())
+
+		assert.Equal(t,
+			"timestamp,entry_id,kind,amount,balance,counterparty,item,reason\n"+
+				",,opening,,0,,,\n"+
+				"2025-03-01T01:00:00Z,1,transfer,-5,-5,\"'=HYPERLINK(\"\"x\"\")\",,\n"+
+				"2025-03-01T01:00:00Z,2,purchase,-1,-6,,'+cmd,\n"+
+				"2025-03-01T01:00:00Z,3,adjustment,1,-5,,,'@SUM(A1)\n"+
+				"2025-03-01T01:00:00Z,4,adjustment,1,-4,,,'-1+1\n"+
+				"2025-03-01T01:00:00Z,5,adjustment,1,-3,,,'\tx\n"+
+				"2025-03-01T01:00:00Z,6,adjustment,1,-2,,,\"'\rx\"\n"+
+				",,closing,,-2,,,\n",
+			buf.String())
+	})
+
+	t.Run("No movements", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewStatementCSVWriter(&buf, model.StatementHeader{Username: "alice"})
+
+		assert.NoError(t, w.Opening(0))
+		assert.NoError(t, w.Close())
+
+		assert.Equal(t,
+			"timestamp,entry_id,kind,amount,balance,counterparty,item,reason\n"+
+				",,opening,,0,,,\n"+
+				",,closing,,0,,,\n",
+			buf.String())
+	})
+}
+
+func TestStatementJSONWriter(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	created := from.Add(time.Hour)
+
+	var buf bytes.Buffer
+	w := NewStatementJSONWriter(&buf, model.StatementHeader{Username: "alice", From: &from})
+
+	assert.NoError(t, w.Opening(1000))
+	assert.NoError(t, w.Movement(model.ActivityEvent{
+		ID: 7, Kind: model.PostingTransfer, Amount: -10, Balance: 990, Counterparty: "bob", Timestamp: created,
+	}))
+	assert.NoError(t, w.Movement(model.ActivityEvent{
+		ID: 8, Kind: model.PostingGrant, Amount: 100, Balance: 1090, Timestamp: created,
+	}))
+	assert.NoError(t, w.Close())
+
+	var statement struct {
+		model.StatementHeader
+		Movements      []model.ActivityEvent `json:"movements"`
+		ClosingBalance int                   `json:"closingBalance"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &statement))
+	assert.Equal(t, "alice", statement.Username)
+	assert.Equal(t, from, *statement.From)
+	assert.Nil(t, statement.To)
+	assert.Equal(t, 1000, statement.OpeningBalance)
+	assert.Len(t, statement.Movements, 2)
+	assert.Equal(t, "bob", statement.Movements[0].Counterparty)
+	assert.Equal(t, 1090, statement.ClosingBalance)
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/garaevmir/avitocoinstore/internal/keystore"
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+)
+
+// Structure responsible for issuing, rotating and revoking user tokens
+type TokenService struct {
+	userRepo    repository.UserRepositoryInt
+	refreshRepo repository.RefreshTokenRepositoryInt
+	keys        *keystore.KeyStore
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+}
+
+// Constructor for the token service
+func NewTokenService(
+	uRepo repository.UserRepositoryInt,
+	rRepo repository.RefreshTokenRepositoryInt,
+	keys *keystore.KeyStore,
+	accessTTL time.Duration,
+	refreshTTL time.Duration,
+) *TokenService {
+	return &TokenService{
+		userRepo:    uRepo,
+		refreshRepo: rRepo,
+		keys:        keys,
+		accessTTL:   accessTTL,
+		refreshTTL:  refreshTTL,
+	}
+}
+
+// Function that issues access token and a refresh token starting a new family for user, returns tokens and error
+func (s *TokenService) Issue(ctx context.Context, user *model.User) (*model.AuthResponse, error) {
+	return s.issue(ctx, user, "")
+}
+
+// Function that exchanges refresh token for a new pair of tokens, every refresh token can be used only once,
+// returns tokens and error
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string) (*model.AuthResponse, error) {
+	hash := hashToken(refreshToken)
+
+	token, err := s.refreshRepo.ConsumeRefreshToken(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if token == nil {
+		stale, err := s.refreshRepo.GetRefreshToken(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		// Second use of a rotated token means it has leaked, so the whole family is revoked
+		if stale != nil && stale.UsedAt != nil && stale.RevokedAt == nil {
+			log.Printf("Refresh token reuse detected for user %s", stale.UserID)
+			if err := s.refreshRepo.RevokeFamily(ctx, stale.FamilyID); err != nil {
+				return nil, err
+			}
+		}
+		return nil, model.ErrInvalidRefresh
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		return nil, err
+	}
+
+	return s.issue(ctx, user, token.FamilyID)
+}
+
+// Function that revokes family of the refresh token owned by user with userID, returns error
+func (s *TokenService) Revoke(ctx context.Context, userID, refreshToken string) error {
+	token, err := s.refreshRepo.GetRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		return err
+	}
+
+	if token == nil || token.UserID != userID {
+		return model.ErrInvalidRefresh
+	}
+
+	return s.refreshRepo.RevokeFamily(ctx, token.FamilyID)
+}
+
+// Issues pair of tokens, refresh token joins family with familyID or starts a new one if it is empty
+func (s *TokenService) issue(ctx context.Context, user *model.User, familyID string) (*model.AuthResponse, error) {
+	now := time.Now()
+
+	jti, err := randomString(16)
+	if err != nil {
+		log.Printf("Token id generation error: %v", err)
+		return nil, err
+	}
+
+	accessToken, err := s.keys.Sign(jwt.MapClaims{
+		"jti":     jti,
+		"user_id": user.ID,
+		"role":    user.Role,
+		"iat":     now.Unix(),
+		"exp":     now.Add(s.accessTTL).Unix(),
+	})
+	if err != nil {
+		log.Printf("Token signing error: %v", err)
+		return nil, err
+	}
+
+	refreshToken, err := randomString(32)
+	if err != nil {
+		log.Printf("Refresh token generation error: %v", err)
+		return nil, err
+	}
+
+	err = s.refreshRepo.CreateRefreshToken(ctx, &model.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(s.refreshTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AuthResponse{Token: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// Generates random opaque string from n random bytes, used for token ids and refresh tokens
+func randomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Hashes refresh token for storage, so leaked database does not leak usable tokens
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
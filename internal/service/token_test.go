@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/keystore"
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func newTestKeys(t *testing.T) *keystore.KeyStore {
+	key, err := keystore.GenerateKey()
+	assert.NoError(t, err)
+	return keystore.NewFromKeys(key)
+}
+
+func TestTokenService_Issue(t *testing.T) {
+	userRepo := new(mocks.UserRepositoryMock)
+	refreshRepo := new(mocks.RefreshTokenRepositoryMock)
+	keys := newTestKeys(t)
+	tokenSvc := NewTokenService(userRepo, refreshRepo, keys, time.Minute, time.Hour)
+	user := &model.User{ID: "user1", Role: model.RoleAdmin}
+
+	t.Run("Successful issue", func(t *testing.T) {
+		var stored *model.RefreshToken
+		refreshRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				stored = args[1].(*model.RefreshToken)
+			}).Return(nil).Once()
+
+		tokens, err := tokenSvc.Issue(context.Background(), user)
+		assert.NoError(t, err)
+
+		claims := jwt.MapClaims{}
+		token, err := jwt.ParseWithClaims(tokens.Token, claims, keys.Keyfunc)
+		assert.NoError(t, err)
+		assert.Equal(t, "user1", claims["user_id"])
+		assert.Equal(t, model.RoleAdmin, claims["role"])
+		assert.NotEmpty(t, claims["jti"])
+		assert.Equal(t, jwt.SigningMethodEdDSA.Alg(), token.Method.Alg())
+
+		exp, err := claims.GetExpirationTime()
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), exp.Time, 2*time.Second)
+
+		assert.Equal(t, "user1", stored.UserID)
+		assert.Empty(t, stored.FamilyID)
+		assert.Equal(t, hashToken(tokens.RefreshToken), stored.TokenHash)
+		assert.NotEqual(t, tokens.RefreshToken, stored.TokenHash)
+	})
+
+	t.Run("Storing refresh token error", func(t *testing.T) {
+		refreshRepo.On("CreateRefreshToken", mock.Anything, mock.Anything).
+			Return(model.ErrInternalError).Once()
+
+		tokens, err := tokenSvc.Issue(context.Background(), user)
+		assert.Nil(t, tokens)
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestTokenService_Refresh(t *testing.T) {
+	userRepo := new(mocks.UserRepositoryMock)
+	refreshRepo := new(mocks.RefreshTokenRepositoryMock)
+	tokenSvc := NewTokenService(userRepo, refreshRepo, newTestKeys(t), time.Minute, time.Hour)
+	ctx := context.Background()
+	usedAt := time.Now()
+
+	t.Run("Successful rotation", func(t *testing.T) {
+		refreshRepo.On("ConsumeRefreshToken", ctx, hashToken("old")).
+			Return(&model.RefreshToken{UserID: "user1", FamilyID: "family1"}, nil).Once()
+		userRepo.On("GetUserByID", ctx, "user1").
+			Return(&model.User{ID: "user1"}, nil).Once()
+		refreshRepo.On("CreateRefreshToken", ctx, mock.MatchedBy(func(rt *model.RefreshToken) bool {
+			return rt.FamilyID == "family1"
+		})).Return(nil).Once()
+
+		tokens, err := tokenSvc.Refresh(ctx, "old")
+		assert.NoError(t, err)
+		assert.NotEqual(t, "old", tokens.RefreshToken)
+		refreshRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reused token revokes family", func(t *testing.T) {
+		refreshRepo.On("ConsumeRefreshToken", ctx, hashToken("reused")).
+			Return(nil, nil).Once()
+		refreshRepo.On("GetRefreshToken", ctx, hashToken("reused")).
+			Return(&model.RefreshToken{UserID: "user1", FamilyID: "family2", UsedAt: &usedAt}, nil).Once()
+		refreshRepo.On("RevokeFamily", ctx, "family2").
+			Return(nil).Once()
+
+		tokens, err := tokenSvc.Refresh(ctx, "reused")
+		assert.Nil(t, tokens)
+		assert.ErrorIs(t, err, model.ErrInvalidRefresh)
+		refreshRepo.AssertExpectations(t)
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		refreshRepo.On("ConsumeRefreshToken", ctx, hashToken("expired")).
+			Return(nil, nil).Once()
+		refreshRepo.On("GetRefreshToken", ctx, hashToken("expired")).
+			Return(&model.RefreshToken{UserID: "user1", FamilyID: "family3"}, nil).Once()
+
+		tokens, err := tokenSvc.Refresh(ctx, "expired")
+		assert.Nil(t, tokens)
+		assert.ErrorIs(t, err, model.ErrInvalidRefresh)
+	})
+
+	t.Run("Consume error", func(t *testing.T) {
+		refreshRepo.On("ConsumeRefreshToken", ctx, hashToken("broken")).
+			Return(nil, model.ErrInternalError).Once()
+
+		_, err := tokenSvc.Refresh(ctx, "broken")
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+
+	t.Run("Getting user error", func(t *testing.T) {
+		refreshRepo.On("ConsumeRefreshToken", ctx, hashToken("orphan")).
+			Return(&model.RefreshToken{UserID: "user2", FamilyID: "family4"}, nil).Once()
+		userRepo.On("GetUserByID", ctx, "user2").
+			Return(nil, model.ErrInternalError).Once()
+
+		_, err := tokenSvc.Refresh(ctx, "orphan")
+		assert.ErrorIs(t, err, model.ErrInternalError)
+	})
+}
+
+func TestTokenService_Revoke(t *testing.T) {
+	refreshRepo := new(mocks.RefreshTokenRepositoryMock)
+	tokenSvc := NewTokenService(new(mocks.UserRepositoryMock), refreshRepo, newTestKeys(t), time.Minute, time.Hour)
+	ctx := context.Background()
+
+	t.Run("Successful revoke", func(t *testing.T) {
+		refreshRepo.On("GetRefreshToken", ctx, hashToken("token")).
+			Return(&model.RefreshToken{UserID: "user1", FamilyID: "family1"}, nil).Once()
+		refreshRepo.On("RevokeFamily", ctx, "family1").
+			Return(nil).Once()
+
+		assert.NoError(t, tokenSvc.Revoke(ctx, "user1", "token"))
+		refreshRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		refreshRepo.On("GetRefreshToken", ctx, hashToken("unknown")).
+			Return(nil, nil).Once()
+
+		assert.ErrorIs(t, tokenSvc.Revoke(ctx, "user1", "unknown"), model.ErrInvalidRefresh)
+	})
+
+	t.Run("Token of another user", func(t *testing.T) {
+		refreshRepo.On("GetRefreshToken", ctx, hashToken("foreign")).
+			Return(&model.RefreshToken{UserID: "user2", FamilyID: "family2"}, nil).Once()
+
+		assert.ErrorIs(t, tokenSvc.Revoke(ctx, "user1", "foreign"), model.ErrInvalidRefresh)
+	})
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+)
+
+// Structure for transfers of coins between users, every transfer has to pass all policies
+type TransferService struct {
+	userRepo        repository.UserRepositoryInt
+	transactionRepo repository.TransactionRepositoryInt
+	policies        []TransferPolicy
+}
+
+// Constructor for transfer service, policies are checked in given order
+func NewTransferService(
+	uRepo repository.UserRepositoryInt,
+	tRepo repository.TransactionRepositoryInt,
+	policies ...TransferPolicy,
+) *TransferService {
+	return &TransferService{userRepo: uRepo, transactionRepo: tRepo, policies: policies}
+}
+
+// Function that sends amount coins from user with fromUserID to user with toUsername with optional note,
+// policies and balance are checked inside transfer transaction under lock of both users, returns error
+func (s *TransferService) SendCoins(
+	ctx context.Context,
+	fromUserID, toUsername string,
+	amount int,
+	note model.TransferNote,
+) error {
+	if amount <= 0 {
+		return model.ErrNegAmount
+	}
+
+	note, err := sanitizeNote(note)
+	if err != nil {
+		return err
+	}
+
+	if toUsername == "" {
+		return model.ErrUserNotFound
+	}
+
+	toUser, err := s.userRepo.GetUserByUsername(ctx, toUsername)
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		return err
+	}
+	if toUser == nil {
+		return model.ErrUserNotFound
+	}
+
+	// Policies run under lock of sender, so concurrent transfers can not pass limits together
+	return s.transactionRepo.TransferCoins(ctx, fromUserID, toUser.ID, amount, note, func(tx pgx.Tx) error {
+		transfer := &Transfer{FromUserID: fromUserID, To: toUser, Amount: amount, Note: note, Tx: tx}
+		for _, policy := range s.policies {
+			if err := policy.Check(ctx, transfer); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Function that strips control and formatting characters from message, collapses whitespace
+// and checks length of message and reason, returns cleaned note and error
+func sanitizeNote(note model.TransferNote) (model.TransferNote, error) {
+	if note.Reason != "" && !model.ValidTransferReason(note.Reason) {
+		return note, model.ErrInvalidReason
+	}
+
+	message := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsSpace(r):
+			return ' '
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r), r == utf8.RuneError:
+			return -1
+		}
+		return r
+	}, note.Message)
+	note.Message = strings.Join(strings.Fields(message), " ")
+
+	if utf8.RuneCountInString(note.Message) > model.MaxTransferMessageLength {
+		return note, model.ErrMessageTooLong
+	}
+	return note, nil
+}
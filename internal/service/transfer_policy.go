@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/internal/repository"
+)
+
+// Structure that describes transfer being validated, recipient is already looked up. Tx is transaction
+// of transfer that holds locks of both users, policies read earlier transfers through it
+type Transfer struct {
+	FromUserID string
+	To         *model.User
+	Amount     int
+	Note       model.TransferNote
+	Tx         pgx.Tx
+}
+
+// Interface for rules that transfer has to satisfy, they are checked inside transfer transaction after
+// both users are locked, policy returns error describing violated rule or nil
+type TransferPolicy interface {
+	Check(ctx context.Context, t *Transfer) error
+}
+
+// Policy that forbids sending coins to yourself
+type SelfTransferPolicy struct{}
+
+// Function that rejects transfer to sender with ErrSelfTransfer
+func (SelfTransferPolicy) Check(ctx context.Context, t *Transfer) error {
+	if t.To.ID == t.FromUserID {
+		return model.ErrSelfTransfer
+	}
+	return nil
+}
+
+// Policy that forbids sending coins to frozen and deactivated accounts
+type ActiveRecipientPolicy struct{}
+
+// Function that rejects transfer to inactive account with ErrRecipientInactive
+func (ActiveRecipientPolicy) Check(ctx context.Context, t *Transfer) error {
+	if t.To.Status != model.UserActive {
+		return model.ErrRecipientInactive
+	}
+	return nil
+}
+
+// Policy that limits amount of single transfer by Max coins
+type MaxAmountPolicy struct {
+	Max int
+}
+
+// Function that rejects transfer above limit with ErrTransferLimit
+func (p MaxAmountPolicy) Check(ctx context.Context, t *Transfer) error {
+	if t.Amount > p.Max {
+		return model.ErrTransferLimit
+	}
+	return nil
+}
+
+// Policy that allows sending coins only to listed usernames
+type RecipientAllowListPolicy struct {
+	usernames map[string]bool
+}
+
+// Constructor for recipient allow-list policy
+func NewRecipientAllowListPolicy(usernames []string) RecipientAllowListPolicy {
+	p := RecipientAllowListPolicy{usernames: make(map[string]bool, len(usernames))}
+	for _, username := range usernames {
+		p.usernames[username] = true
+	}
+	return p
+}
+
+// Function that rejects transfer to unlisted user with ErrRecipientNotAllowed
+func (p RecipientAllowListPolicy) Check(ctx context.Context, t *Transfer) error {
+	if !p.usernames[t.To.Username] {
+		return model.ErrRecipientNotAllowed
+	}
+	return nil
+}
+
+// Policy that limits total amount user sends during a calendar day in UTC
+type DailyLimitPolicy struct {
+	transactionRepo repository.TransactionRepositoryInt
+	limit           int
+}
+
+// Constructor for daily limit policy
+func NewDailyLimitPolicy(tRepo repository.TransactionRepositoryInt, limit int) *DailyLimitPolicy {
+	return &DailyLimitPolicy{transactionRepo: tRepo, limit: limit}
+}
+
+// Function that rejects transfer exceeding what is left of daily limit with ErrDailyLimit
+func (p *DailyLimitPolicy) Check(ctx context.Context, t *Transfer) error {
+	dayStart := time.Now().UTC().Truncate(24 * time.Hour)
+	_, sent, err := p.transactionRepo.GetSentSinceTx(ctx, t.Tx, t.FromUserID, dayStart)
+	if err != nil {
+		return err
+	}
+	if sent+t.Amount > p.limit {
+		return model.ErrDailyLimit
+	}
+	return nil
+}
+
+// Policy that limits number of transfers user makes during sliding window
+type VelocityPolicy struct {
+	transactionRepo repository.TransactionRepositoryInt
+	maxTransfers    int
+	window          time.Duration
+}
+
+// Constructor for velocity policy, at most maxTransfers transfers are allowed during window
+func NewVelocityPolicy(tRepo repository.TransactionRepositoryInt, maxTransfers int, window time.Duration) *VelocityPolicy {
+	return &VelocityPolicy{transactionRepo: tRepo, maxTransfers: maxTransfers, window: window}
+}
+
+// Function that rejects transfer once user made too many of them recently with ErrTooManyTransfers
+func (p *VelocityPolicy) Check(ctx context.Context, t *Transfer) error {
+	count, _, err := p.transactionRepo.GetSentSinceTx(ctx, t.Tx, t.FromUserID, time.Now().UTC().Add(-p.window))
+	if err != nil {
+		return err
+	}
+	if count >= p.maxTransfers {
+		return model.ErrTooManyTransfers
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestRecipientAllowListPolicy(t *testing.T) {
+	policy := NewRecipientAllowListPolicy([]string{"charity"})
+	ctx := context.Background()
+
+	t.Run("Listed recipient", func(t *testing.T) {
+		transfer := &Transfer{FromUserID: "user1", To: &model.User{ID: "user2", Username: "charity"}, Amount: 10}
+		assert.NoError(t, policy.Check(ctx, transfer))
+	})
+
+	t.Run("Unlisted recipient", func(t *testing.T) {
+		transfer := &Transfer{FromUserID: "user1", To: &model.User{ID: "user3", Username: "bob"}, Amount: 10}
+		assert.ErrorIs(t, policy.Check(ctx, transfer), model.ErrRecipientNotAllowed)
+	})
+}
+
+func TestDailyLimitPolicy(t *testing.T) {
+	txRepo := new(mocks.TransactionRepositoryMock)
+	policy := NewDailyLimitPolicy(txRepo, 1000)
+	ctx := context.Background()
+	tx := new(mocks.TxMock)
+	transfer := &Transfer{FromUserID: "user1", To: &model.User{ID: "user2"}, Amount: 300, Tx: tx}
+
+	dayStart := mock.MatchedBy(func(since time.Time) bool {
+		return since.Equal(time.Now().UTC().Truncate(24 * time.Hour))
+	})
+
+	t.Run("Within limit", func(t *testing.T) {
+		txRepo.On("GetSentSinceTx", ctx, tx, "user1", dayStart).Return(2, 700, nil).Once()
+
+		assert.NoError(t, policy.Check(ctx, transfer))
+	})
+
+	t.Run("Limit exceeded", func(t *testing.T) {
+		txRepo.On("GetSentSinceTx", ctx, tx, "user1", dayStart).Return(3, 701, nil).Once()
+
+		assert.ErrorIs(t, policy.Check(ctx, transfer), model.ErrDailyLimit)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		txRepo.On("GetSentSinceTx", ctx, tx, "user1", dayStart).Return(0, 0, model.ErrInternalError).Once()
+
+		assert.ErrorIs(t, policy.Check(ctx, transfer), model.ErrInternalError)
+	})
+}
+
+func TestVelocityPolicy(t *testing.T) {
+	txRepo := new(mocks.TransactionRepositoryMock)
+	policy := NewVelocityPolicy(txRepo, 5, time.Minute)
+	ctx := context.Background()
+	tx := new(mocks.TxMock)
+	transfer := &Transfer{FromUserID: "user1", To: &model.User{ID: "user2"}, Amount: 1, Tx: tx}
+
+	windowStart := mock.MatchedBy(func(since time.Time) bool {
+		age := time.Since(since)
+		return since.Location() == time.UTC && age >= time.Minute && age < time.Minute+time.Second
+	})
+
+	t.Run("Few recent transfers", func(t *testing.T) {
+		txRepo.On("GetSentSinceTx", ctx, tx, "user1", windowStart).Return(4, 4, nil).Once()
+
+		assert.NoError(t, policy.Check(ctx, transfer))
+	})
+
+	t.Run("Too many recent transfers", func(t *testing.T) {
+		txRepo.On("GetSentSinceTx", ctx, tx, "user1", windowStart).Return(5, 5, nil).Once()
+
+		assert.ErrorIs(t, policy.Check(ctx, transfer), model.ErrTooManyTransfers)
+	})
+}
+
+func TestTransferService_Policies(t *testing.T) {
+	userRepo := new(mocks.UserRepositoryMock)
+	txRepo := new(mocks.TransactionRepositoryMock)
+	ctx := context.Background()
+
+	userRepo.On("GetUserByUsername", ctx, "user2").
+		Return(&model.User{ID: "user2", Username: "user2", Status: model.UserActive}, nil)
+
+	t.Run("Failing policy stops transfer", func(t *testing.T) {
+		transferSvc := NewTransferService(userRepo, txRepo, MaxAmountPolicy{Max: 1000},
+			NewRecipientAllowListPolicy([]string{"charity"}))
+
+		assert.ErrorIs(t, transferSvc.SendCoins(ctx, "user1", "user2", 10, model.TransferNote{}), model.ErrRecipientNotAllowed)
+		txRepo.AssertNotCalled(t, "TransferCoins", ctx, "user1", "user2", 10, model.TransferNote{})
+	})
+
+	t.Run("Policies checked in order", func(t *testing.T) {
+		transferSvc := NewTransferService(userRepo, txRepo, MaxAmountPolicy{Max: 5},
+			NewVelocityPolicy(txRepo, 1, time.Minute))
+
+		assert.ErrorIs(t, transferSvc.SendCoins(ctx, "user1", "user2", 10, model.TransferNote{}), model.ErrTransferLimit)
+		txRepo.AssertNotCalled(t, "GetSentSinceTx", ctx, mock.Anything, "user1", mock.Anything)
+	})
+}
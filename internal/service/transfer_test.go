@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/garaevmir/avitocoinstore/internal/model"
+	"github.com/garaevmir/avitocoinstore/tests/mocks"
+)
+
+func TestTransferService_SendCoins(t *testing.T) {
+	userRepo := new(mocks.UserRepositoryMock)
+	txRepo := new(mocks.TransactionRepositoryMock)
+	transferSvc := NewTransferService(userRepo, txRepo,
+		SelfTransferPolicy{}, ActiveRecipientPolicy{}, MaxAmountPolicy{Max: 500})
+	ctx := context.Background()
+
+	userRepo.On("GetUserByUsername", ctx, "user1").
+		Return(&model.User{ID: "user1", Status: model.UserActive}, nil)
+	userRepo.On("GetUserByUsername", ctx, "user2").
+		Return(&model.User{ID: "user2", Status: model.UserActive}, nil)
+	userRepo.On("GetUserByUsername", ctx, "frozen").
+		Return(&model.User{ID: "user3", Status: model.UserFrozen}, nil)
+	userRepo.On("GetUserByUsername", ctx, "deactivated").
+		Return(&model.User{ID: "user4", Status: model.UserDeactivated}, nil)
+	userRepo.On("GetUserByUsername", ctx, "ghost").
+		Return((*model.User)(nil), nil)
+
+	t.Run("Successful transfer", func(t *testing.T) {
+		txRepo.On("TransferCoins", ctx, "user1", "user2", 500, model.TransferNote{}).Return(nil).Once()
+
+		assert.NoError(t, transferSvc.SendCoins(ctx, "user1", "user2", 500, model.TransferNote{}))
+		txRepo.AssertExpectations(t)
+	})
+
+	t.Run("Self transfer", func(t *testing.T) {
+		assert.ErrorIs(t, transferSvc.SendCoins(ctx, "user1", "user1", 100, model.TransferNote{}), model.ErrSelfTransfer)
+	})
+
+	t.Run("Frozen recipient", func(t *testing.T) {
+		assert.ErrorIs(t, transferSvc.SendCoins(ctx, "user1", "frozen", 100, model.TransferNote{}), model.ErrRecipientInactive)
+	})
+
+	t.Run("Deactivated recipient", func(t *testing.T) {
+		assert.ErrorIs(t, transferSvc.SendCoins(ctx, "user1", "deactivated", 100, model.TransferNote{}), model.ErrRecipientInactive)
+	})
+
+	t.Run("Amount above limit", func(t *testing.T) {
+		assert.ErrorIs(t, transferSvc.SendCoins(ctx, "user1", "user2", 501, model.TransferNote{}), model.ErrTransferLimit)
+	})
+
+	t.Run("Non-positive amount", func(t *testing.T) {
+		assert.ErrorIs(t, transferSvc.SendCoins(ctx, "user1", "user2", 0, model.TransferNote{}), model.ErrNegAmount)
+	})
+
+	t.Run("Unknown recipient", func(t *testing.T) {
+		assert.ErrorIs(t, transferSvc.SendCoins(ctx, "user1", "ghost", 100, model.TransferNote{}), model.ErrUserNotFound)
+	})
+
+	t.Run("No policies", func(t *testing.T) {
+		unlimited := NewTransferService(userRepo, txRepo)
+		txRepo.On("TransferCoins", ctx, "user1", "user2", 5000, model.TransferNote{}).Return(model.ErrInsufficientFunds).Once()
+
+		assert.ErrorIs(t, unlimited.SendCoins(ctx, "user1", "user2", 5000, model.TransferNote{}), model.ErrInsufficientFunds)
+	})
+
+	t.Run("Message sanitized", func(t *testing.T) {
+		note := model.TransferNote{Message: "  thanks\n\tfor\u202e help\x00 ", Reason: model.ReasonThanks}
+		txRepo.On("TransferCoins", ctx, "user1", "user2", 10,
+			model.TransferNote{Message: "thanks for help", Reason: model.ReasonThanks}).Return(nil).Once()
+
+		assert.NoError(t, transferSvc.SendCoins(ctx, "user1", "user2", 10, note))
+	})
+
+	t.Run("Message too long", func(t *testing.T) {
+		note := model.TransferNote{Message: strings.Repeat("я", model.MaxTransferMessageLength+1)}
+
+		assert.ErrorIs(t, transferSvc.SendCoins(ctx, "user1", "user2", 10, note), model.ErrMessageTooLong)
+	})
+
+	t.Run("Invalid reason", func(t *testing.T) {
+		note := model.TransferNote{Reason: "bribe"}
+
+		assert.ErrorIs(t, transferSvc.SendCoins(ctx, "user1", "user2", 10, note), model.ErrInvalidReason)
+	})
+
+	txRepo.AssertNumberOfCalls(t, "TransferCoins", 3)
+}
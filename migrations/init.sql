@@ -2,7 +2,11 @@ CREATE TABLE users (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     username VARCHAR(255) UNIQUE NOT NULL,
     password_hash VARCHAR(255) NOT NULL,
-    coins INT NOT NULL DEFAULT 1000
+    coins INT NOT NULL DEFAULT 0 CONSTRAINT users_coins_non_negative CHECK (coins >= 0),
+    role VARCHAR(32) NOT NULL DEFAULT 'employee'
+        CHECK (role IN ('employee', 'merch-manager', 'finance', 'admin')),
+    status VARCHAR(32) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'frozen', 'deactivated'))
 );
 
 CREATE TABLE transactions (
@@ -10,12 +14,194 @@ CREATE TABLE transactions (
     from_user_id UUID REFERENCES users(id),
     to_user_id UUID REFERENCES users(id),
     amount INT NOT NULL,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    message VARCHAR(200) NOT NULL DEFAULT '',
+    reason VARCHAR(32) NOT NULL DEFAULT ''
+        CHECK (reason IN ('', 'thanks', 'gift', 'bet', 'reimbursement')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
+-- History is read page by page from newest transfers of each participant, leading columns also serve
+-- lookups of all transfers of user for counterparty summary
+CREATE INDEX transactions_from_user_idx ON transactions (from_user_id, created_at, id);
+CREATE INDEX transactions_to_user_idx ON transactions (to_user_id, created_at, id);
+
 CREATE TABLE inventory (
     user_id UUID REFERENCES users(id),
     item_name VARCHAR(255) NOT NULL,
     quantity INT NOT NULL DEFAULT 0,
     PRIMARY KEY (user_id, item_name)
-);
\ No newline at end of file
+);
+
+CREATE TABLE items (
+    name VARCHAR(255) PRIMARY KEY,
+    price INT NOT NULL CHECK (price > 0),
+    description TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO items (name, price) VALUES
+    ('t-shirt', 80),
+    ('cup', 20),
+    ('book', 50),
+    ('pen', 10),
+    ('powerbank', 200),
+    ('hoody', 300),
+    ('umbrella', 200),
+    ('socks', 10),
+    ('wallet', 50),
+    ('pink-hoody', 500);
+
+CREATE TABLE refresh_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    family_id UUID NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX refresh_tokens_family_idx ON refresh_tokens (family_id);
+
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE user_token_revocations (
+    user_id UUID PRIMARY KEY REFERENCES users(id),
+    revoked_before TIMESTAMP NOT NULL
+);
+
+CREATE TABLE invites (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code_hash VARCHAR(64) UNIQUE NOT NULL,
+    created_by UUID NOT NULL REFERENCES users(id),
+    expires_at TIMESTAMP NOT NULL,
+    used_by UUID REFERENCES users(id),
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE login_attempts (
+    key VARCHAR(320) PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP
+);
+
+CREATE TABLE password_resets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    created_by UUID NOT NULL REFERENCES users(id),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Every movement of coins is a posting of ledger entries that sum up to zero, users.coins is a cache
+-- of user balance maintained by trigger and is never updated directly
+CREATE TABLE ledger_postings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(32) NOT NULL
+        CHECK (kind IN ('grant', 'transfer', 'purchase', 'refund', 'adjustment', 'correction')),
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    posting_id UUID NOT NULL REFERENCES ledger_postings(id),
+    user_id UUID REFERENCES users(id),
+    system_account VARCHAR(32) CHECK (system_account IN ('issuance', 'shop', 'adjustments')),
+    amount INT NOT NULL CHECK (amount <> 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((user_id IS NULL) <> (system_account IS NULL))
+);
+
+CREATE INDEX ledger_entries_posting_idx ON ledger_entries (posting_id);
+CREATE INDEX ledger_entries_user_idx ON ledger_entries (user_id, created_at);
+-- Activity feed walks entries of user in order of id to compute running balance
+CREATE INDEX ledger_entries_user_id_idx ON ledger_entries (user_id, id) INCLUDE (amount);
+
+CREATE FUNCTION ledger_check_posting() RETURNS trigger AS $$
+BEGIN
+    IF (SELECT SUM(amount) FROM ledger_entries WHERE posting_id = NEW.posting_id) <> 0 THEN
+        RAISE EXCEPTION 'ledger posting % does not sum up to zero', NEW.posting_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_entries_balanced
+    AFTER INSERT ON ledger_entries
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_check_posting();
+
+CREATE FUNCTION ledger_apply_entry() RETURNS trigger AS $$
+BEGIN
+    IF NEW.user_id IS NOT NULL THEN
+        UPDATE users SET coins = coins + NEW.amount WHERE id = NEW.user_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_entries_apply
+    AFTER INSERT ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_apply_entry();
+
+CREATE FUNCTION ledger_reject_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_entries_append_only
+    BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_reject_change();
+
+CREATE TRIGGER ledger_postings_append_only
+    BEFORE UPDATE OR DELETE ON ledger_postings
+    FOR EACH ROW EXECUTE FUNCTION ledger_reject_change();
+
+CREATE TABLE orders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    item_name VARCHAR(255) NOT NULL REFERENCES items(name),
+    unit_price INT NOT NULL CHECK (unit_price > 0),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    status VARCHAR(32) NOT NULL DEFAULT 'completed' CHECK (status IN ('completed', 'refunded')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX orders_user_idx ON orders (user_id, created_at DESC, id DESC);
+
+CREATE TABLE cart_items (
+    user_id UUID NOT NULL REFERENCES users(id),
+    item_name VARCHAR(255) NOT NULL REFERENCES items(name),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    unit_price INT NOT NULL CHECK (unit_price > 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, item_name)
+);
+
+-- Responses of requests made with Idempotency-Key header, status_code is NULL while request is in progress
+CREATE TABLE idempotency_keys (
+    user_id UUID NOT NULL REFERENCES users(id),
+    key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code INT,
+    content_type VARCHAR(255),
+    response BYTEA,
+    token UUID NOT NULL DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
@@ -3,9 +3,12 @@ package integration
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"sync"
 	"testing"
+	"time"
 )
 
 const baseURL = "http://localhost:8080"
@@ -53,9 +56,6 @@ func TestAuth(t *testing.T) {
 		if token1 == "" || token2 == "" {
 			t.Error("Token not received")
 		}
-		if token1 != token2 {
-			t.Error("Tokens don't match")
-		}
 	})
 }
 
@@ -199,3 +199,128 @@ func TestGetUserInfo(t *testing.T) {
 		}
 	})
 }
+
+func TestConcurrentPurchases(t *testing.T) {
+	username := fmt.Sprintf("racer%d", time.Now().UnixNano())
+	token := getAuthToken(username, "racerpass")
+	if token == "" {
+		t.Fatal("Token not received")
+	}
+
+	const buyers = 20
+	var wg sync.WaitGroup
+	statuses := make(chan int, buyers)
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest("GET", baseURL+"/api/buy/hoody", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+
+	bought := 0
+	for status := range statuses {
+		if status == http.StatusOK {
+			bought++
+		} else if status != http.StatusBadRequest {
+			t.Errorf("Expected status 200 or 400, got %d", status)
+		}
+	}
+	if bought != 3 {
+		t.Errorf("Expected 3 purchases out of 1000 coins, got %d", bought)
+	}
+
+	req, _ := http.NewRequest("GET", baseURL+"/api/info", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var info struct {
+		Coins int `json:"coins"`
+	}
+	json.NewDecoder(resp.Body).Decode(&info)
+	if info.Coins != 100 {
+		t.Errorf("Expected 100 coins left, got %d", info.Coins)
+	}
+}
+
+func TestHistoryPagination(t *testing.T) {
+	suffix := time.Now().UnixNano()
+	sender := fmt.Sprintf("historian%d", suffix)
+	recipient := fmt.Sprintf("archivist%d", suffix)
+	token := getAuthToken(sender, "historypass")
+	if token == "" || getAuthToken(recipient, "historypass") == "" {
+		t.Fatal("Token not received")
+	}
+
+	const transfers = 5
+	for i := 0; i < transfers; i++ {
+		payload := map[string]interface{}{"toUser": recipient, "amount": 1, "reason": "thanks"}
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest("POST", baseURL+"/api/sendCoin", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+	}
+
+	seen := make(map[string]bool)
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > transfers {
+			t.Fatal("Pagination does not stop")
+		}
+
+		req, _ := http.NewRequest("GET", baseURL+"/api/history?direction=sent&limit=2&cursor="+cursor, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var page struct {
+			Entries []struct {
+				ID           string `json:"id"`
+				Counterparty string `json:"counterparty"`
+			} `json:"entries"`
+			NextCursor string `json:"nextCursor"`
+		}
+		json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+
+		for _, e := range page.Entries {
+			if seen[e.ID] {
+				t.Errorf("Transfer %s returned twice", e.ID)
+			}
+			if e.Counterparty != recipient {
+				t.Errorf("Expected counterparty %s, got %s", recipient, e.Counterparty)
+			}
+			seen[e.ID] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	if len(seen) != transfers {
+		t.Errorf("Expected %d transfers in history, got %d", transfers, len(seen))
+	}
+}
@@ -93,6 +93,11 @@ func (m *DBMock) Query(ctx context.Context, sql string, args ...any) (pgx.Rows,
 	return mockArgs.Get(0).(pgx.Rows), mockArgs.Error(1)
 }
 
+func (m *DBMock) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	mockArgs := m.Called(ctx, sql, args)
+	return mockArgs.Get(0).(pgconn.CommandTag), mockArgs.Error(1)
+}
+
 type PgxRowsMock struct {
 	mock.Mock
 }
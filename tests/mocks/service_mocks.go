@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/mock"
@@ -18,6 +19,11 @@ func (m *UserRepositoryMock) CreateUser(ctx context.Context, user *model.User) e
 	return args.Error(0)
 }
 
+func (m *UserRepositoryMock) CreateUserTx(ctx context.Context, tx pgx.Tx, user *model.User) error {
+	args := m.Called(ctx, tx, user)
+	return args.Error(0)
+}
+
 func (m *UserRepositoryMock) GetUserByID(ctx context.Context, userID string) (*model.User, error) {
 	args := m.Called(ctx, userID)
 
@@ -34,8 +40,13 @@ func (m *UserRepositoryMock) GetUserByUsername(ctx context.Context, username str
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
-func (m *UserRepositoryMock) UpdateUserCoinsTx(ctx context.Context, tx pgx.Tx, userID string, delta int) error {
-	args := m.Called(ctx, tx, userID, delta)
+func (m *UserRepositoryMock) UpdatePassword(ctx context.Context, userID, passwordHash string) error {
+	args := m.Called(ctx, userID, passwordHash)
+	return args.Error(0)
+}
+
+func (m *UserRepositoryMock) UpdatePasswordTx(ctx context.Context, tx pgx.Tx, userID, passwordHash string) error {
+	args := m.Called(ctx, tx, userID, passwordHash)
 	return args.Error(0)
 }
 
@@ -44,18 +55,55 @@ func (m *UserRepositoryMock) BeginTx(ctx context.Context) (pgx.Tx, error) {
 	return args.Get(0).(pgx.Tx), args.Error(1)
 }
 
+func (m *UserRepositoryMock) GetCoinsForUpdateTx(ctx context.Context, tx pgx.Tx, userID string) (int, error) {
+	args := m.Called(ctx, tx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *UserRepositoryMock) SetStatus(ctx context.Context, userID, status string) error {
+	args := m.Called(ctx, userID, status)
+	return args.Error(0)
+}
+
+func (m *UserRepositoryMock) SetRole(ctx context.Context, userID, role string) error {
+	args := m.Called(ctx, userID, role)
+	return args.Error(0)
+}
+
 type TransactionRepositoryMock struct {
 	mock.Mock
 }
 
-func (m *TransactionRepositoryMock) TransferCoins(ctx context.Context, fromUserID, toUserID string, amount int) error {
-	args := m.Called(ctx, fromUserID, toUserID, amount)
+func (m *TransactionRepositoryMock) TransferCoins(
+	ctx context.Context,
+	fromUserID, toUserID string,
+	amount int,
+	note model.TransferNote,
+	check func(tx pgx.Tx) error,
+) error {
+	// Like repository, check runs before transfer is made
+	if check != nil {
+		if err := check(nil); err != nil {
+			return err
+		}
+	}
+	args := m.Called(ctx, fromUserID, toUserID, amount, note)
 	return args.Error(0)
 }
 
-func (m *TransactionRepositoryMock) GetTransactionHistory(ctx context.Context, userID string) (*model.TransactionHistory, error) {
-	args := m.Called(ctx, userID)
-	return args.Get(0).(*model.TransactionHistory), args.Error(1)
+func (m *TransactionRepositoryMock) GetCounterpartySummary(ctx context.Context, userID, reason string) ([]model.CounterpartySummary, error) {
+	args := m.Called(ctx, userID, reason)
+	return args.Get(0).([]model.CounterpartySummary), args.Error(1)
+}
+
+func (m *TransactionRepositoryMock) GetHistory(ctx context.Context, userID string, filter model.HistoryFilter) (*model.HistoryPage, error) {
+	args := m.Called(ctx, userID, filter)
+	return args.Get(0).(*model.HistoryPage), args.Error(1)
+}
+
+func (m *TransactionRepositoryMock) GetSentSinceTx(ctx context.Context, tx pgx.Tx, userID string, since time.Time) (int, int, error) {
+	args := m.Called(ctx, tx, userID, since)
+	return args.Int(0), args.Int(1), args.Error(2)
 }
 
 type InventoryRepositoryMock struct {
@@ -71,3 +119,300 @@ func (m *InventoryRepositoryMock) AddToInventoryTx(ctx context.Context, tx pgx.T
 	args := m.Called(ctx, tx, userID, item, quantity)
 	return args.Error(0)
 }
+
+type OrderRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *OrderRepositoryMock) CreateOrderTx(ctx context.Context, tx pgx.Tx, order *model.Order) error {
+	args := m.Called(ctx, tx, order)
+	return args.Error(0)
+}
+
+func (m *OrderRepositoryMock) GetUserOrders(ctx context.Context, userID string, cursor *model.HistoryCursor, limit int) (*model.OrderPage, error) {
+	args := m.Called(ctx, userID, cursor, limit)
+	return args.Get(0).(*model.OrderPage), args.Error(1)
+}
+
+type CartRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *CartRepositoryMock) SetItem(ctx context.Context, userID, item string, quantity, unitPrice int) error {
+	args := m.Called(ctx, userID, item, quantity, unitPrice)
+	return args.Error(0)
+}
+
+func (m *CartRepositoryMock) RemoveItem(ctx context.Context, userID, item string) (bool, error) {
+	args := m.Called(ctx, userID, item)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *CartRepositoryMock) GetCart(ctx context.Context, userID string) ([]model.CartItem, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]model.CartItem), args.Error(1)
+}
+
+func (m *CartRepositoryMock) GetCartForUpdateTx(ctx context.Context, tx pgx.Tx, userID string) ([]model.CartItem, error) {
+	args := m.Called(ctx, tx, userID)
+	return args.Get(0).([]model.CartItem), args.Error(1)
+}
+
+func (m *CartRepositoryMock) RemoveItemsTx(ctx context.Context, tx pgx.Tx, userID string, items []string) error {
+	args := m.Called(ctx, tx, userID, items)
+	return args.Error(0)
+}
+
+type IdempotencyRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *IdempotencyRepositoryMock) Reserve(
+	ctx context.Context,
+	userID, key, requestHash string,
+	ttl, lease time.Duration,
+) (string, *model.IdempotencyRecord, error) {
+	args := m.Called(ctx, userID, key, requestHash, ttl, lease)
+
+	var record *model.IdempotencyRecord
+	if args.Get(1) != nil {
+		record = args.Get(1).(*model.IdempotencyRecord)
+	}
+	return args.String(0), record, args.Error(2)
+}
+
+func (m *IdempotencyRepositoryMock) Complete(
+	ctx context.Context,
+	userID, key, token string,
+	status int,
+	contentType string,
+	body []byte,
+) error {
+	args := m.Called(ctx, userID, key, token, status, contentType, body)
+	return args.Error(0)
+}
+
+func (m *IdempotencyRepositoryMock) Release(ctx context.Context, userID, key, token string) error {
+	args := m.Called(ctx, userID, key, token)
+	return args.Error(0)
+}
+
+type CatalogRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *CatalogRepositoryMock) GetItemByName(ctx context.Context, name string) (*model.Item, error) {
+	args := m.Called(ctx, name)
+
+	var item *model.Item
+	if args.Get(0) != nil {
+		item = args.Get(0).(*model.Item)
+	}
+
+	return item, args.Error(1)
+}
+
+func (m *CatalogRepositoryMock) GetItemForShareTx(ctx context.Context, tx pgx.Tx, name string) (*model.Item, error) {
+	args := m.Called(ctx, tx, name)
+
+	var item *model.Item
+	if args.Get(0) != nil {
+		item = args.Get(0).(*model.Item)
+	}
+
+	return item, args.Error(1)
+}
+
+func (m *CatalogRepositoryMock) ListItems(ctx context.Context, sortBy string, maxPrice int) ([]model.Item, error) {
+	args := m.Called(ctx, sortBy, maxPrice)
+	return args.Get(0).([]model.Item), args.Error(1)
+}
+
+func (m *CatalogRepositoryMock) CreateItem(ctx context.Context, item *model.Item) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
+func (m *CatalogRepositoryMock) UpdateItemPrice(ctx context.Context, name string, price int) (*model.Item, error) {
+	args := m.Called(ctx, name, price)
+
+	var item *model.Item
+	if args.Get(0) != nil {
+		item = args.Get(0).(*model.Item)
+	}
+
+	return item, args.Error(1)
+}
+
+func (m *CatalogRepositoryMock) DeactivateItem(ctx context.Context, name string) (*model.Item, error) {
+	args := m.Called(ctx, name)
+
+	var item *model.Item
+	if args.Get(0) != nil {
+		item = args.Get(0).(*model.Item)
+	}
+
+	return item, args.Error(1)
+}
+
+type RefreshTokenRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *RefreshTokenRepositoryMock) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *RefreshTokenRepositoryMock) ConsumeRefreshToken(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	args := m.Called(ctx, tokenHash)
+
+	var token *model.RefreshToken
+	if args.Get(0) != nil {
+		token = args.Get(0).(*model.RefreshToken)
+	}
+
+	return token, args.Error(1)
+}
+
+func (m *RefreshTokenRepositoryMock) GetRefreshToken(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	args := m.Called(ctx, tokenHash)
+
+	var token *model.RefreshToken
+	if args.Get(0) != nil {
+		token = args.Get(0).(*model.RefreshToken)
+	}
+
+	return token, args.Error(1)
+}
+
+func (m *RefreshTokenRepositoryMock) RevokeFamily(ctx context.Context, familyID string) error {
+	args := m.Called(ctx, familyID)
+	return args.Error(0)
+}
+
+func (m *RefreshTokenRepositoryMock) RevokeUserTokens(ctx context.Context, userID string, before time.Time) error {
+	args := m.Called(ctx, userID, before)
+	return args.Error(0)
+}
+
+type RevocationRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *RevocationRepositoryMock) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	args := m.Called(ctx, jti, expiresAt)
+	return args.Error(0)
+}
+
+func (m *RevocationRepositoryMock) RevokeUserTokens(ctx context.Context, userID string, before time.Time) error {
+	args := m.Called(ctx, userID, before)
+	return args.Error(0)
+}
+
+func (m *RevocationRepositoryMock) GetRevokedTokens(ctx context.Context) (map[string]time.Time, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[string]time.Time), args.Error(1)
+}
+
+func (m *RevocationRepositoryMock) GetUserRevocations(ctx context.Context) (map[string]time.Time, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[string]time.Time), args.Error(1)
+}
+
+type InviteRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *InviteRepositoryMock) CreateInvite(ctx context.Context, invite *model.Invite) error {
+	args := m.Called(ctx, invite)
+	return args.Error(0)
+}
+
+func (m *InviteRepositoryMock) UseInviteTx(ctx context.Context, tx pgx.Tx, codeHash, userID string) (bool, error) {
+	args := m.Called(ctx, tx, codeHash, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+type LoginAttemptRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *LoginAttemptRepositoryMock) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	args := m.Called(ctx, key, window)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *LoginAttemptRepositoryMock) Lock(ctx context.Context, key string, d time.Duration) error {
+	args := m.Called(ctx, key, d)
+	return args.Error(0)
+}
+
+func (m *LoginAttemptRepositoryMock) GetLockRemaining(ctx context.Context, keys []string) (time.Duration, error) {
+	args := m.Called(ctx, keys)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+func (m *LoginAttemptRepositoryMock) Reset(ctx context.Context, keys []string) error {
+	args := m.Called(ctx, keys)
+	return args.Error(0)
+}
+
+type PasswordResetRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *PasswordResetRepositoryMock) CreateReset(ctx context.Context, reset *model.PasswordReset) error {
+	args := m.Called(ctx, reset)
+	return args.Error(0)
+}
+
+func (m *PasswordResetRepositoryMock) ConsumeResetTx(ctx context.Context, tx pgx.Tx, tokenHash string) (*model.PasswordReset, error) {
+	args := m.Called(ctx, tx, tokenHash)
+
+	var reset *model.PasswordReset
+	if args.Get(0) != nil {
+		reset = args.Get(0).(*model.PasswordReset)
+	}
+
+	return reset, args.Error(1)
+}
+
+type LedgerRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *LedgerRepositoryMock) PostTx(ctx context.Context, tx pgx.Tx, posting *model.Posting) error {
+	args := m.Called(ctx, tx, posting)
+	return args.Error(0)
+}
+
+func (m *LedgerRepositoryMock) AdjustBalance(ctx context.Context, userID string, amount int, reason string) (int, error) {
+	args := m.Called(ctx, userID, amount, reason)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *LedgerRepositoryMock) GetBalanceReports(ctx context.Context) ([]model.BalanceReport, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.BalanceReport), args.Error(1)
+}
+
+func (m *LedgerRepositoryMock) ReconcileBalance(ctx context.Context, userID, reason string) (int, error) {
+	args := m.Called(ctx, userID, reason)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *LedgerRepositoryMock) StreamStatement(
+	ctx context.Context,
+	userID string,
+	from, to *time.Time,
+	w model.StatementWriter,
+) error {
+	args := m.Called(ctx, userID, from, to, w)
+	return args.Error(0)
+}
+
+func (m *LedgerRepositoryMock) GetActivity(ctx context.Context, userID string, filter model.ActivityFilter) (*model.ActivityPage, error) {
+	args := m.Called(ctx, userID, filter)
+	return args.Get(0).(*model.ActivityPage), args.Error(1)
+}
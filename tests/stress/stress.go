@@ -1,10 +1,12 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"time"
 
 	vegeta "github.com/tsenart/vegeta/v12/lib"
@@ -15,9 +17,21 @@ type AuthRequest struct {
 	Password string `json:"password"`
 }
 
+type AuthResponse struct {
+	Token string `json:"token"`
+}
+
+type SendCoinRequest struct {
+	ToUser string `json:"toUser"`
+	Amount int    `json:"amount"`
+}
+
+const baseURL = "http://localhost:8080/api"
+
 func main() {
 	rpsFlag := flag.Int("rps", 100, "Requests per second")
 	durationFlag := flag.Duration("duration", 1*time.Second, "Test duration (e.g. 5s, 1m)")
+	scenarioFlag := flag.String("scenario", "auth", "Load scenario: auth or transfers")
 	flag.Parse()
 
 	if *rpsFlag <= 0 {
@@ -27,38 +41,101 @@ func main() {
 		log.Fatal("duration must be a positive value")
 	}
 
-	targetURL := "http://localhost:8080/api/auth"
-	authPayload := AuthRequest{
-		Username: "test_user",
-		Password: "test_password",
+	var targeter vegeta.Targeter
+	switch *scenarioFlag {
+	case "auth":
+		targeter = authTargeter()
+	case "transfers":
+		targeter = transfersTargeter()
+	default:
+		log.Fatalf("unknown scenario %q", *scenarioFlag)
+	}
+
+	rate := vegeta.Rate{Freq: *rpsFlag, Per: time.Second}
+	duration := *durationFlag
+
+	attacker := vegeta.NewAttacker()
+	var metrics vegeta.Metrics
+
+	for res := range attacker.Attack(targeter, rate, duration, "Load Test: "+*scenarioFlag) {
+		metrics.Add(res)
 	}
+	metrics.Close()
 
-	payloadBytes, err := json.Marshal(authPayload)
+	extractMetrics(&metrics)
+}
+
+// Function that creates targeter hitting /api/auth with the same user
+func authTargeter() vegeta.Targeter {
+	payloadBytes, err := json.Marshal(AuthRequest{
+		Username: "test_user",
+		Password: "test_password",
+	})
 	if err != nil {
 		log.Fatalf("Error creating JSON body: %v", err)
 	}
 
-	targeter := vegeta.NewStaticTargeter(vegeta.Target{
+	return vegeta.NewStaticTargeter(vegeta.Target{
 		Method: "POST",
-		URL:    targetURL,
+		URL:    baseURL + "/auth",
 		Body:   payloadBytes,
 		Header: map[string][]string{
 			"Content-Type": {"application/json"},
 		},
 	})
+}
 
-	rate := vegeta.Rate{Freq: *rpsFlag, Per: time.Second}
-	duration := *durationFlag
+// Function that creates targeter sending one coin back and forth between two users,
+// so that every pair of concurrent requests locks the same rows in opposite directions
+func transfersTargeter() vegeta.Targeter {
+	users := []string{"stress_sender_a", "stress_sender_b"}
+	tokens := make([]string, len(users))
+	for i, username := range users {
+		tokens[i] = authenticate(username, "test_password")
+	}
 
-	attacker := vegeta.NewAttacker()
-	var metrics vegeta.Metrics
+	targets := make([]vegeta.Target, len(users))
+	for i := range users {
+		payloadBytes, err := json.Marshal(SendCoinRequest{ToUser: users[1-i], Amount: 1})
+		if err != nil {
+			log.Fatalf("Error creating JSON body: %v", err)
+		}
+		targets[i] = vegeta.Target{
+			Method: "POST",
+			URL:    baseURL + "/sendCoin",
+			Body:   payloadBytes,
+			Header: map[string][]string{
+				"Content-Type":  {"application/json"},
+				"Authorization": {"Bearer " + tokens[i]},
+			},
+		}
+	}
 
-	for res := range attacker.Attack(targeter, rate, duration, "Auth Load Test") {
-		metrics.Add(res)
+	return vegeta.NewStaticTargeter(targets...)
+}
+
+// Function that logs user in and returns its token
+func authenticate(username, password string) string {
+	payloadBytes, err := json.Marshal(AuthRequest{Username: username, Password: password})
+	if err != nil {
+		log.Fatalf("Error creating JSON body: %v", err)
 	}
-	metrics.Close()
 
-	extractMetrics(&metrics)
+	resp, err := http.Post(baseURL+"/auth", "application/json", bytes.NewReader(payloadBytes))
+	if err != nil {
+		log.Fatalf("Error authenticating %s: %v", username, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("Error authenticating %s: status %d", username, resp.StatusCode)
+	}
+
+	var auth AuthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		log.Fatalf("Error decoding auth response: %v", err)
+	}
+	return auth.Token
 }
 
 func extractMetrics(metrics *vegeta.Metrics) {